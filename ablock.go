@@ -0,0 +1,149 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// admin block entry types that change the authority set
+var authorityChanges = map[int]string{
+	3:  "AddReplaceMatryoshkaHash",
+	4:  "IncreaseServerCount",
+	5:  "AddFederatedServer",
+	6:  "AddAuditServer",
+	7:  "RemoveFederatedServer",
+	8:  "AddFederatedServerSigningKey",
+	9:  "AddFederatedServerBitcoinAnchorKey",
+	11: "AddAuthorityFactoidAddress",
+	12: "AddAuthorityEfficiency",
+}
+
+// AdminBlockResponse is a struct formed after the response from the factomd "ablock-by-height" API.
+// The entries are kept raw, their fields depend on the type of entry.
+type AdminBlockResponse struct {
+	ABlock struct {
+		Header struct {
+			PrevBackRefHash string `json:"prevbackrefhash"`
+			DBHeight        int64  `json:"dbheight"`
+			MessageCount    int64  `json:"messagecount"`
+			BodySize        int64  `json:"bodysize"`
+		} `json:"header"`
+		ABEntries         []json.RawMessage `json:"abentries"`
+		BackReferenceHash string            `json:"backreferencehash"`
+		LookupHash        string            `json:"lookuphash"`
+	} `json:"ablock"`
+	RawData string `json:"rawdata"`
+}
+
+// AuthorityEvent is sent to authority listeners for every admin block entry that changes
+// the authority set, such as adding or removing servers or changing their keys.
+type AuthorityEvent struct {
+	DBHeight int64 `json:"dbheight"`
+	// The name of the admin entry type, ie "AddFederatedServer"
+	Type string `json:"type"`
+	// The identity of the affected server, if any
+	IdentityChainID string `json:"identitychainid,omitempty"`
+	// The full admin block entry
+	Entry json.RawMessage `json:"entry"`
+}
+
+// AdminBlockRequest sends an "ablock-by-height" API request to the configured node.
+func (m *Monitor) AdminBlockRequest(dbheight int64) (*AdminBlockResponse, error) {
+	res := new(AdminBlockResponse)
+	if err := m.call("ablock-by-height", map[string]int64{"height": dbheight}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// WatchAuthorities starts fetching the admin block of every new dbheight.
+// Authority listeners receive an event for every change to the authority set.
+// Calling it more than once has no effect.
+func (m *Monitor) WatchAuthorities() {
+	m.watchMtx.Lock()
+	defer m.watchMtx.Unlock()
+	if !m.ablocks {
+		m.ablocks = true
+		m.watchAdminBlocks()
+	}
+}
+
+// watchAdminBlocks starts fetching admin blocks for authority and coinbase events.
+// Must be called with watchMtx held.
+func (m *Monitor) watchAdminBlocks() {
+	if !m.ablockWatcher {
+		m.ablockWatcher = true
+		m.everyDBHeight(m.fetchAdminBlock)
+	}
+}
+
+// NewAuthorityListener spawns a new listener that receives an event for every change to
+// the authority set.
+// Each reader must have its own listener.
+func (m *Monitor) NewAuthorityListener() <-chan AuthorityEvent {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan AuthorityEvent, 25)
+	m.authorityListeners = append(m.authorityListeners, l)
+	m.register("authority", l)
+	return l
+}
+
+func (m *Monitor) fetchAdminBlock(dbheight int64) {
+	res, err := m.AdminBlockRequest(dbheight)
+	if err != nil {
+		m.notifyError(err)
+		return
+	}
+
+	m.watchMtx.Lock()
+	authorities, coinbase := m.ablocks, m.coinbase
+	m.watchMtx.Unlock()
+
+	var events []AuthorityEvent
+	var coinbaseEvents []CoinbaseEvent
+	for _, raw := range res.ABlock.ABEntries {
+		var entry struct {
+			AdminIDType     int    `json:"adminidtype"`
+			IdentityChainID string `json:"identitychainid"`
+		}
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			m.notifyError(fmt.Errorf("admin block %d: %v", dbheight, err))
+			continue
+		}
+		if coinbase && (entry.AdminIDType == coinbaseDescriptor || entry.AdminIDType == coinbaseDescriptorCancel) {
+			e, err := parseCoinbaseEntry(res.ABlock.Header.DBHeight, entry.AdminIDType, raw)
+			if err != nil {
+				m.notifyError(fmt.Errorf("admin block %d: %v", dbheight, err))
+				continue
+			}
+			coinbaseEvents = append(coinbaseEvents, e)
+			continue
+		}
+		name, ok := authorityChanges[entry.AdminIDType]
+		if !ok || !authorities {
+			continue
+		}
+		events = append(events, AuthorityEvent{
+			DBHeight:        res.ABlock.Header.DBHeight,
+			Type:            name,
+			IdentityChainID: entry.IdentityChainID,
+			Entry:           raw,
+		})
+	}
+
+	m.notifyCoinbase(coinbaseEvents)
+
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	for _, e := range events {
+		for _, l := range m.authorityListeners {
+			select {
+			case l <- e:
+				m.delivered(l)
+			default:
+				m.dropped(l)
+			}
+		}
+	}
+}
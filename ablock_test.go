@@ -0,0 +1,46 @@
+package monitor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMonitor_WatchAuthorities(t *testing.T) {
+	s := newTestServer("localhost:9868", 10, 0, time.Second*10, t)
+	defer s.stop()
+	s.handle("ablock-by-height", func(params json.RawMessage) interface{} {
+		return json.RawMessage(`{"ablock":{"header":{"dbheight":10},"abentries":[
+			{"adminidtype":1,"identityadminchainid":"` + hash("1") + `"},
+			{"adminidtype":5,"identitychainid":"` + hash("2") + `","dbheight":10},
+			{"adminidtype":7,"identitychainid":"` + hash("3") + `","dbheight":10}
+		]}}`)
+	})
+
+	m, err := NewMonitorWithConfig("http://localhost:9868/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	m.WatchAuthorities()
+	listener := m.NewAuthorityListener()
+	s.tick() // dbheight 10
+
+	for _, want := range []AuthorityEvent{{Type: "AddFederatedServer", IdentityChainID: hash("2")}, {Type: "RemoveFederatedServer", IdentityChainID: hash("3")}} {
+		select {
+		case e := <-listener:
+			if e.DBHeight != 10 || e.Type != want.Type || e.IdentityChainID != want.IdentityChainID || len(e.Entry) == 0 {
+				t.Errorf("unexpected event. got = %+v, want %s", e, want.Type)
+			}
+		case <-time.After(time.Second * 2):
+			t.Fatalf("%s not received", want.Type)
+		}
+	}
+
+	select {
+	case e := <-listener:
+		t.Errorf("unexpected event %+v", e)
+	case <-time.After(time.Millisecond * 100):
+	}
+}
@@ -0,0 +1,316 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// AckInterval is the time between two acknowledgment requests of a tracked entry or
+// transaction
+var AckInterval = time.Second * 5
+
+// RevealLatencyWindow is the duration of the windows of commit to reveal latencies,
+// see RevealLatency
+var RevealLatencyWindow = time.Hour
+
+// AckTimeout is the time a tracked transaction has to reach DBlockConfirmed before
+// tracking gives up with ErrAckTimeout
+var AckTimeout = time.Hour
+
+var (
+	// ErrAckTimeout is the error of a tracked transaction that was not confirmed within AckTimeout
+	ErrAckTimeout = errors.New("transaction not confirmed in time")
+	// ErrTransactionDropped is the error of a tracked transaction that the node no longer
+	// knows after acknowledging it, ie because it was invalid or the node restarted
+	ErrTransactionDropped = errors.New("transaction dropped")
+)
+
+// AckStatus is the status of an entry or transaction reported by the factomd "ack" APIs
+type AckStatus string
+
+// The statuses of acknowledgments, in the order they are reached
+const (
+	// The node has not seen the entry or transaction
+	AckUnknown AckStatus = "Unknown"
+	// The node has seen it, but it is not part of the process list yet
+	AckNotConfirmed AckStatus = "NotConfirmed"
+	// The leader acknowledged it and it is part of the current block
+	AckTransactionACK AckStatus = "TransactionACK"
+	// It is part of a saved directory block
+	AckDBlockConfirmed AckStatus = "DBlockConfirmed"
+)
+
+// AckData is the status part of an "ack" response
+type AckData struct {
+	// Milliseconds since the epoch, zero if unknown
+	TransactionDate int64     `json:"transactiondate"`
+	BlockDate       int64     `json:"blockdate"`
+	Status          AckStatus `json:"status"`
+}
+
+// EntryAckResponse is a struct formed after the response from the factomd "ack" API for entries.
+type EntryAckResponse struct {
+	CommitTxID string  `json:"committxid"`
+	EntryHash  string  `json:"entryhash"`
+	CommitData AckData `json:"commitdata"`
+	EntryData  AckData `json:"entrydata"`
+}
+
+// ReceiptResponse is a struct formed after the response from the factomd "receipt" API.
+type ReceiptResponse struct {
+	Receipt struct {
+		EntryBlockKeyMR     string `json:"entryblockkeymr"`
+		DirectoryBlockKeyMR string `json:"directoryblockkeymr"`
+	} `json:"receipt"`
+}
+
+// EntryAck is sent by TrackEntry every time the status of the entry changes
+type EntryAck struct {
+	EntryHash string    `json:"entryhash"`
+	ChainID   string    `json:"chainid"`
+	Status    AckStatus `json:"status"`
+	// The status of the entry's commit
+	CommitStatus AckStatus `json:"commitstatus"`
+	CommitTxID   string    `json:"committxid,omitempty"`
+	// The height of the directory block containing the entry once it is DBlockConfirmed
+	DBHeight int64 `json:"dbheight,omitempty"`
+	// The time between the acknowledgments of the commit and the reveal, set on the first
+	// event with an acknowledged reveal if it is known
+	RevealLatency time.Duration `json:"reveallatency,omitempty"`
+	// The time the change was observed
+	Time time.Time `json:"time"`
+}
+
+// TransactionAckResponse is a struct formed after the response from the factomd "factoid-ack" API.
+type TransactionAckResponse struct {
+	TxID string `json:"txid"`
+	// Milliseconds since the epoch, zero if unknown
+	TransactionDate int64     `json:"transactiondate"`
+	BlockDate       int64     `json:"blockdate"`
+	Status          AckStatus `json:"status"`
+}
+
+// TransactionAck is sent by TrackTransaction every time the status of the transaction
+// changes, and when tracking fails
+type TransactionAck struct {
+	TxID   string    `json:"txid"`
+	Status AckStatus `json:"status"`
+	// The time of the block containing the transaction once it is DBlockConfirmed
+	BlockDate time.Time `json:"blockdate,omitempty"`
+	// Set on the last event if the transaction will not be confirmed: ErrAckTimeout or
+	// ErrTransactionDropped
+	Err error `json:"-"`
+	// The time the change was observed
+	Time time.Time `json:"time"`
+}
+
+// EntryAckRequest sends an "ack" API request for the entry to the configured node.
+func (m *Monitor) EntryAckRequest(entryHash, chainID string) (*EntryAckResponse, error) {
+	res := new(EntryAckResponse)
+	if err := m.call("ack", map[string]string{"hash": entryHash, "chainid": chainID}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ReceiptRequest sends a "receipt" API request for the entry to the configured node.
+func (m *Monitor) ReceiptRequest(entryHash string) (*ReceiptResponse, error) {
+	res := new(ReceiptResponse)
+	if err := m.call("receipt", map[string]string{"hash": entryHash}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// TrackEntry checks the acknowledgment of an entry every AckInterval and sends an event
+// every time its status changes, usually Unknown, TransactionACK, and DBlockConfirmed with
+// the height of the block containing the entry. The channel is closed after the entry is
+// DBlockConfirmed or when ctx is done. Tracking waits for events to be read, a
+// reader that stops reading should cancel ctx.
+// Failed requests are sent to error listeners and retried with the next check.
+// Tracking doesn't depend on the monitor running.
+func (m *Monitor) TrackEntry(ctx context.Context, entryHash, chainID string) (<-chan EntryAck, error) {
+	if err := checkHash("entry hash", entryHash); err != nil {
+		return nil, err
+	}
+	if err := checkHash("chain id", chainID); err != nil {
+		return nil, err
+	}
+
+	l := make(chan EntryAck, 4) // room for every status
+	var last EntryAck
+	var committed time.Time
+	var commitDate int64
+	go m.track(ctx, func() bool {
+		res, err := m.EntryAckRequest(entryHash, chainID)
+		if err != nil {
+			m.notifyError(err)
+			return false
+		}
+		now := m.clock().Now()
+		if committed.IsZero() && acked(res.CommitData.Status) {
+			committed, commitDate = now, res.CommitData.TransactionDate
+		}
+		a := EntryAck{EntryHash: entryHash, ChainID: chainID, Status: res.EntryData.Status, CommitStatus: res.CommitData.Status, CommitTxID: res.CommitTxID}
+		if a.Status == "" {
+			a.Status = AckUnknown
+		}
+		if a.Status == last.Status && a.CommitStatus == last.CommitStatus {
+			return false
+		}
+		if a.Status == AckDBlockConfirmed {
+			if a.DBHeight, err = m.entryHeight(entryHash); err != nil {
+				m.notifyError(err)
+				return false
+			}
+		}
+		if acked(a.Status) && !acked(last.Status) && !committed.IsZero() {
+			// the node's acknowledgment times are more precise than the polls
+			if commitDate > 0 && res.EntryData.TransactionDate > 0 {
+				a.RevealLatency = time.Duration(res.EntryData.TransactionDate-commitDate) * time.Millisecond
+			} else {
+				a.RevealLatency = now.Sub(committed)
+			}
+			if a.RevealLatency > 0 {
+				m.reveals.add(now, a.RevealLatency)
+			}
+		}
+		a.Time = now
+		last = a
+		select {
+		case l <- a:
+		case <-ctx.Done():
+			return true
+		}
+		return a.Status == AckDBlockConfirmed
+	}, func() { close(l) })
+	return l, nil
+}
+
+// TransactionAckRequest sends a "factoid-ack" API request for the transaction to the configured node.
+func (m *Monitor) TransactionAckRequest(txID string) (*TransactionAckResponse, error) {
+	res := new(TransactionAckResponse)
+	if err := m.call("factoid-ack", map[string]string{"txid": txID}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// TrackTransaction checks the acknowledgment of a factoid transaction every AckInterval
+// and sends an event every time its status changes, usually Unknown, TransactionACK,
+// and DBlockConfirmed. If the node stops knowing the transaction after acknowledging it,
+// or the transaction is not confirmed within AckTimeout, a last event with the status
+// so far and ErrTransactionDropped or ErrAckTimeout is sent.
+// The channel is closed after the last event or when ctx is done. Tracking waits for
+// events to be read, a reader that stops reading should cancel ctx.
+// Failed requests are sent to error listeners and retried with the next check.
+// Tracking doesn't depend on the monitor running.
+func (m *Monitor) TrackTransaction(ctx context.Context, txID string) (<-chan TransactionAck, error) {
+	if err := checkHash("transaction id", txID); err != nil {
+		return nil, err
+	}
+
+	l := make(chan TransactionAck, 5) // room for every status and the error
+	timeout := m.conf.AckTimeout
+	if timeout <= 0 {
+		timeout = AckTimeout
+	}
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	var last TransactionAck
+	var final bool
+	go m.track(tctx, func() bool {
+		res, err := m.TransactionAckRequest(txID)
+		if err != nil {
+			m.notifyError(err)
+			return false
+		}
+		a := TransactionAck{TxID: txID, Status: res.Status, Time: m.clock().Now()}
+		if a.Status == "" {
+			a.Status = AckUnknown
+		}
+		if a.Status == last.Status {
+			return false
+		}
+		if a.Status == AckUnknown && last.Status != "" {
+			a.Err = ErrTransactionDropped
+		}
+		if a.Status == AckDBlockConfirmed && res.BlockDate > 0 {
+			a.BlockDate = time.Unix(0, res.BlockDate*int64(time.Millisecond))
+		}
+		last = a
+		select {
+		case l <- a:
+		case <-tctx.Done():
+			return true
+		}
+		final = a.Status == AckDBlockConfirmed || a.Err != nil
+		return final
+	}, func() {
+		if !final && ctx.Err() == nil && tctx.Err() == context.DeadlineExceeded {
+			if last.Status == "" {
+				last.TxID, last.Status = txID, AckUnknown
+			}
+			last.Err = ErrAckTimeout
+			last.Time = m.clock().Now()
+			select {
+			case l <- last:
+			case <-ctx.Done():
+			}
+		}
+		cancel()
+		close(l)
+	})
+	return l, nil
+}
+
+// RevealLatency returns the percentiles of the times between the acknowledgments of the
+// commits and reveals of entries tracked with TrackEntry in the last one to two
+// RevealLatencyWindows. The latencies are based on the acknowledgment times reported by
+// the node, or on the times the monitor observed the acknowledgments if there are none.
+func (m *Monitor) RevealLatency() LatencyHistogram {
+	return m.reveals.snapshot(m.clock().Now()).summary()
+}
+
+// acked returns true if the leader acknowledged the entry or transaction
+func acked(s AckStatus) bool {
+	return s == AckTransactionACK || s == AckDBlockConfirmed
+}
+
+// entryHeight looks up the dbheight of a confirmed entry via its receipt
+func (m *Monitor) entryHeight(entryHash string) (int64, error) {
+	receipt, err := m.ReceiptRequest(entryHash)
+	if err != nil {
+		return 0, err
+	}
+	eblock, err := m.EntryBlockRequest(receipt.Receipt.EntryBlockKeyMR)
+	if err != nil {
+		return 0, err
+	}
+	return eblock.Header.DBHeight, nil
+}
+
+// track calls check right away and then every AckInterval until it returns true or ctx
+// is done, then calls done
+func (m *Monitor) track(ctx context.Context, check func() bool, done func()) {
+	defer done()
+	if check() {
+		return
+	}
+	interval := m.conf.AckInterval
+	if interval <= 0 {
+		interval = AckInterval
+	}
+	ticker := m.clock().NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			if check() {
+				return
+			}
+		}
+	}
+}
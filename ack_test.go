@@ -0,0 +1,202 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMonitor_TrackEntry(t *testing.T) {
+	s := newTestServer("localhost:9831", 10, 5, time.Second*10, t)
+	defer s.stop()
+
+	status := AckUnknown
+	s.handle("ack", func(params json.RawMessage) interface{} {
+		var p map[string]string
+		json.Unmarshal(params, &p)
+		res := new(EntryAckResponse)
+		res.EntryHash = p["hash"]
+		res.EntryData.Status = status
+		res.CommitData.Status = status
+		if acked(status) {
+			res.CommitData.TransactionDate = 1600000000000
+			res.EntryData.TransactionDate = 1600000003000
+		}
+		return res
+	})
+	s.handle("receipt", func(json.RawMessage) interface{} {
+		res := new(ReceiptResponse)
+		res.Receipt.EntryBlockKeyMR = hash("eblock")
+		return res
+	})
+	s.handle("entry-block", func(json.RawMessage) interface{} {
+		res := new(EntryBlockResponse)
+		res.Header.DBHeight = 10
+		return res
+	})
+
+	m, err := New("http://localhost:9831/v2", Config{AckInterval: time.Millisecond * 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.TrackEntry(context.Background(), "abc", hash("c")); err == nil {
+		t.Error("invalid entry hash accepted")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	l, err := m.TrackEntry(ctx, hash("e"), hash("c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, next := range []AckStatus{AckTransactionACK, AckDBlockConfirmed, ""} {
+		select {
+		case a := <-l:
+			if a.EntryHash != hash("e") || a.Status != status {
+				t.Fatalf("unexpected ack %+v, want status %s", a, status)
+			}
+			if a.Status == AckDBlockConfirmed && a.DBHeight != 10 {
+				t.Errorf("unexpected dbheight %d", a.DBHeight)
+			}
+			if a.Status == AckTransactionACK && a.RevealLatency != time.Second*3 {
+				t.Errorf("unexpected reveal latency %v", a.RevealLatency)
+			}
+		case <-time.After(time.Second * 2):
+			t.Fatalf("status %s not received", status)
+		}
+		s.mtx.Lock()
+		status = next
+		s.mtx.Unlock()
+	}
+
+	select {
+	case a, ok := <-l:
+		if ok {
+			t.Errorf("unexpected ack after confirmation %+v", a)
+		}
+	case <-time.After(time.Second * 2):
+		t.Error("tracker not closed after confirmation")
+	}
+	if rl := m.Stats().RevealLatency; rl.Count != 1 || rl.Max < time.Second*3 {
+		t.Errorf("unexpected reveal latency stats %+v", rl)
+	}
+}
+
+func TestMonitor_TrackTransaction(t *testing.T) {
+	s := newTestServer("localhost:9830", 10, 5, time.Second*10, t)
+	defer s.stop()
+
+	statuses := make(map[string]AckStatus)
+	s.handle("factoid-ack", func(params json.RawMessage) interface{} {
+		var p map[string]string
+		json.Unmarshal(params, &p)
+		res := &TransactionAckResponse{TxID: p["txid"], Status: statuses[p["txid"]]}
+		if res.Status == AckDBlockConfirmed {
+			res.BlockDate = 1600000000000
+		}
+		return res
+	})
+	set := func(txID string, status AckStatus) {
+		s.mtx.Lock()
+		statuses[txID] = status
+		s.mtx.Unlock()
+	}
+	expect := func(l <-chan TransactionAck, status AckStatus, err error) TransactionAck {
+		select {
+		case a := <-l:
+			if a.Status != status || a.Err != err {
+				t.Fatalf("unexpected ack %+v, want status %s and error %v", a, status, err)
+			}
+			return a
+		case <-time.After(time.Second * 2):
+			t.Fatalf("status %s not received", status)
+		}
+		return TransactionAck{}
+	}
+
+	m, err := New("http://localhost:9830/v2", Config{AckInterval: time.Millisecond * 50, AckTimeout: time.Millisecond * 500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	set(hash("c"), AckTransactionACK)
+	confirmed, err := m.TrackTransaction(ctx, hash("c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(confirmed, AckTransactionACK, nil)
+	set(hash("c"), AckDBlockConfirmed)
+	if a := expect(confirmed, AckDBlockConfirmed, nil); a.BlockDate.Unix() != 1600000000 {
+		t.Errorf("unexpected block date %v", a.BlockDate)
+	}
+
+	set(hash("d"), AckTransactionACK)
+	dropped, err := m.TrackTransaction(ctx, hash("d"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(dropped, AckTransactionACK, nil)
+	set(hash("d"), AckUnknown)
+	expect(dropped, AckUnknown, ErrTransactionDropped)
+
+	stuck, err := m.TrackTransaction(ctx, hash("e"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(stuck, AckUnknown, nil)
+	expect(stuck, AckUnknown, ErrAckTimeout)
+
+	for _, l := range []<-chan TransactionAck{confirmed, dropped, stuck} {
+		if _, ok := <-l; ok {
+			t.Error("tracker not closed after the last event")
+		}
+	}
+}
+
+func TestMonitor_TrackEntryUnread(t *testing.T) {
+	s := newTestServer("localhost:9818", 10, 5, time.Second*10, t)
+	defer s.stop()
+
+	// the status keeps flipping, so there are more changes than the channel buffers
+	var calls int
+	s.handle("ack", func(json.RawMessage) interface{} {
+		calls++
+		res := new(EntryAckResponse)
+		res.EntryData.Status = AckUnknown
+		if calls%2 == 0 {
+			res.EntryData.Status = AckNotConfirmed
+		}
+		return res
+	})
+
+	m, err := New("http://localhost:9818/v2", Config{AckInterval: time.Millisecond * 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l, err := m.TrackEntry(ctx, hash("e"), hash("c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(time.Millisecond * 200)
+	cancel()
+	time.Sleep(time.Millisecond * 50)
+
+	// the buffered events are followed by the close, without reads unblocking the tracker
+	for i, n := 0, len(l); i < n; i++ {
+		<-l
+	}
+	select {
+	case _, ok := <-l:
+		if ok {
+			t.Error("unexpected event after ctx was cancelled")
+		}
+	default:
+		t.Error("channel not closed after ctx was cancelled")
+	}
+}
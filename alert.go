@@ -0,0 +1,451 @@
+package monitor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AlertInterval is the time between two evaluations of the alert rules
+var AlertInterval time.Duration = time.Second * 10
+
+// maxPollAge is how long poll outcomes are kept for error rates
+const maxPollAge = time.Hour
+
+// Severity is the importance of an alert
+type Severity int
+
+// The severities of alerts, from least to most important
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityCritical:
+		return "critical"
+	}
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+// MarshalText encodes the severity as its name
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// AlertStatus is the stage in an alert's lifecycle
+type AlertStatus string
+
+// An alert is firing while its rule is violated and resolved once the rule holds again
+const (
+	AlertFiring   AlertStatus = "firing"
+	AlertResolved AlertStatus = "resolved"
+)
+
+// Alert is sent to alert listeners when a rule starts firing and when it resolves.
+// Every firing alert is followed by exactly one resolved alert, unless the rule is
+// removed first.
+type Alert struct {
+	Rule     string      `json:"rule"`
+	Severity Severity    `json:"severity"`
+	Status   AlertStatus `json:"status"`
+	// The description of the violation returned by the rule. Resolved alerts repeat the
+	// message of the firing alert.
+	Message string `json:"message"`
+	// The time the alert started firing
+	Since time.Time `json:"since"`
+	// The time of this event
+	Time time.Time `json:"time"`
+
+	// The monitor's state at the time of the event
+	Height   int64  `json:"height"`
+	DBHeight int64  `json:"dbheight"`
+	Minute   int64  `json:"minute"`
+	Network  string `json:"network,omitempty"`
+}
+
+// Duration returns how long the alert has been firing, or how long it fired for if it was resolved
+func (a Alert) Duration() time.Duration {
+	return a.Time.Sub(a.Since)
+}
+
+// Rule is a condition that is checked every AlertInterval
+type Rule struct {
+	// The unique name of the rule, ie "no-new-block"
+	Name     string
+	Severity Severity
+	// Violated returns true and a description of the problem while the rule is violated
+	Violated func(s RuleState) (bool, string)
+	// How long the rule must be violated before the alert fires
+	For time.Duration
+	// The minimum time between an alert resolving and the rule firing again, so a
+	// flapping condition doesn't produce a stream of alerts
+	Cooldown time.Duration
+}
+
+// RuleState is the information rules are evaluated against
+type RuleState struct {
+	State
+	// The time of the evaluation
+	Now time.Time
+	// The time the monitor last observed a new height
+	LastBlock time.Time
+
+	polls    []pollOutcome
+	balances map[string]int64 // known balances of watched factoid and entry credit addresses
+}
+
+// Balance returns the known balance of a watched factoid or entry credit address.
+// Factoid balances are in factoshis. Returns false if the address isn't watched.
+func (s RuleState) Balance(addr string) (int64, bool) {
+	b, ok := s.balances[addr]
+	return b, ok
+}
+
+// ErrorRate returns the ratio of failed polls within the window before Now, and the
+// number of polls in the window. Only the polls of the last hour are kept, so longer
+// windows count the polls of the last hour.
+func (s RuleState) ErrorRate(window time.Duration) (float64, int) {
+	var n, failed int
+	for _, p := range s.polls {
+		if s.Now.Sub(p.time) > window {
+			continue
+		}
+		n++
+		if p.failed {
+			failed++
+		}
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	return float64(failed) / float64(n), n
+}
+
+// NoNewBlock fires when the monitor hasn't seen a new height for the duration
+func NoNewBlock(d time.Duration) Rule {
+	return Rule{
+		Name:     "no-new-block",
+		Severity: SeverityCritical,
+		Violated: func(s RuleState) (bool, string) {
+			if s.LastBlock.IsZero() {
+				return false, ""
+			}
+			since := s.Now.Sub(s.LastBlock)
+			return since >= d, fmt.Sprintf("no new block for %s since height %d", since.Round(time.Second), s.Height)
+		},
+	}
+}
+
+// DBHeightLag fires when the node's dbheight is n or more blocks behind the leader height
+func DBHeightLag(n int64) Rule {
+	return Rule{
+		Name:     "dbheight-lag",
+		Severity: SeverityWarning,
+		Violated: func(s RuleState) (bool, string) {
+			lag := s.Height - s.DBHeight
+			return lag >= n, fmt.Sprintf("dbheight %d lags leader height %d by %d blocks", s.DBHeight, s.Height, lag)
+		},
+	}
+}
+
+// ErrorRate fires when more than the ratio (0-1) of polls failed within the window.
+// At least two polls are needed in the window. The window is capped at an hour, the
+// time the monitor keeps the outcomes of its polls.
+func ErrorRate(ratio float64, window time.Duration) Rule {
+	return Rule{
+		Name:     "error-rate",
+		Severity: SeverityCritical,
+		Violated: func(s RuleState) (bool, string) {
+			rate, n := s.ErrorRate(window)
+			return n >= 2 && rate > ratio, fmt.Sprintf("%.0f%% of %d polls failed in the last %s", rate*100, n, window)
+		},
+	}
+}
+
+// BalanceBelow fires when the balance of the watched factoid or entry credit address drops
+// below the threshold, ie BalanceBelow("EC...", 5000) for an address that pays for entries.
+// Factoid thresholds are in factoshis. The address has to be watched via WatchFactoidAddress
+// or WatchECAddress, the rule holds for addresses that are not.
+func BalanceBelow(addr string, threshold int64) Rule {
+	return Rule{
+		Name:     "balance-below-" + addr,
+		Severity: SeverityWarning,
+		Violated: func(s RuleState) (bool, string) {
+			b, ok := s.Balance(addr)
+			return ok && b < threshold, fmt.Sprintf("balance of %s is %s, below %s", addr, formatBalance(addr, b), formatBalance(addr, threshold))
+		},
+	}
+}
+
+// BalanceAbove fires when the balance of the watched factoid or entry credit address rises
+// above the threshold, ie for a hot wallet that should be emptied regularly.
+// Factoid thresholds are in factoshis. The address has to be watched via WatchFactoidAddress
+// or WatchECAddress, the rule holds for addresses that are not.
+func BalanceAbove(addr string, threshold int64) Rule {
+	return Rule{
+		Name:     "balance-above-" + addr,
+		Severity: SeverityInfo,
+		Violated: func(s RuleState) (bool, string) {
+			b, ok := s.Balance(addr)
+			return ok && b > threshold, fmt.Sprintf("balance of %s is %s, above %s", addr, formatBalance(addr, b), formatBalance(addr, threshold))
+		},
+	}
+}
+
+// formatBalance formats factoid balances in FCT and entry credit balances in EC
+func formatBalance(addr string, b int64) string {
+	if strings.HasPrefix(addr, "EC") {
+		return fmt.Sprintf("%d EC", b)
+	}
+	sign := ""
+	if b < 0 {
+		sign, b = "-", -b
+	}
+	return fmt.Sprintf("%s%d.%08d FCT", sign, b/1e8, b%1e8)
+}
+
+// ruleState tracks the lifecycle of a single rule
+type ruleState struct {
+	rule Rule
+	// the time the rule started being violated, zero if it holds
+	violated time.Time
+	// the firing alert, nil if the rule isn't firing
+	alert *Alert
+	// the time the last alert resolved
+	resolved time.Time
+}
+
+// AddRule starts evaluating the rule every AlertInterval.
+// Returns an error if the rule has no name or condition, or if a rule with the same name exists.
+func (m *Monitor) AddRule(r Rule) error {
+	if r.Name == "" || r.Violated == nil {
+		return fmt.Errorf("rule needs a name and a condition")
+	}
+
+	m.watchMtx.Lock()
+	defer m.watchMtx.Unlock()
+	for _, rs := range m.rules {
+		if rs.rule.Name == r.Name {
+			return fmt.Errorf("rule %q already exists", r.Name)
+		}
+	}
+	if m.rules == nil {
+		m.every(AlertInterval, func() { m.checkRules(m.clock().Now()) })
+	}
+	m.rules = append(m.rules, &ruleState{rule: r})
+	return nil
+}
+
+// RemoveRule stops evaluating the rule. A firing alert of the rule is not resolved.
+func (m *Monitor) RemoveRule(name string) {
+	m.watchMtx.Lock()
+	defer m.watchMtx.Unlock()
+	for i, rs := range m.rules {
+		if rs.rule.Name == name {
+			m.rules = append(m.rules[:i], m.rules[i+1:]...)
+			return
+		}
+	}
+}
+
+// Rules returns the names of all rules
+func (m *Monitor) Rules() []string {
+	m.watchMtx.Lock()
+	defer m.watchMtx.Unlock()
+	names := make([]string, 0, len(m.rules))
+	for _, rs := range m.rules {
+		names = append(names, rs.rule.Name)
+	}
+	return names
+}
+
+// Alerts returns the alerts that are currently firing, sorted by rule name
+func (m *Monitor) Alerts() []Alert {
+	m.watchMtx.Lock()
+	defer m.watchMtx.Unlock()
+	var alerts []Alert
+	for _, rs := range m.rules {
+		if rs.alert != nil {
+			alerts = append(alerts, *rs.alert)
+		}
+	}
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].Rule < alerts[j].Rule })
+	return alerts
+}
+
+// NewAlertListener spawns a new listener that receives alerts when rules fire and resolve.
+// Each reader must have its own listener.
+func (m *Monitor) NewAlertListener() <-chan Alert {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan Alert, 25)
+	m.alertListeners = append(m.alertListeners, l)
+	m.register("alert", l)
+	return l
+}
+
+// ruleState returns the state rules are evaluated against
+func (m *Monitor) ruleState(now time.Time) RuleState {
+	s := RuleState{State: m.State(), Now: now}
+	m.heightMtx.Lock()
+	s.LastBlock = m.lastBlock
+	m.heightMtx.Unlock()
+	s.polls = m.polls.since(now.Add(-maxPollAge))
+	m.watchMtx.Lock()
+	if n := len(m.fctBalances) + len(m.ecBalances); n > 0 {
+		s.balances = make(map[string]int64, n)
+		for addr, b := range m.fctBalances {
+			s.balances[addr] = b
+		}
+		for addr, b := range m.ecBalances {
+			s.balances[addr] = b
+		}
+	}
+	m.watchMtx.Unlock()
+	return s
+}
+
+// checkRules evaluates every rule and notifies alert listeners of changes.
+// Rules are evaluated without holding watchMtx, so a rule's condition may call any
+// method of the monitor.
+func (m *Monitor) checkRules(now time.Time) {
+	s := m.ruleState(now)
+	var network string
+	if id, ok := m.Network(); ok {
+		network = id.String()
+	}
+
+	m.watchMtx.Lock()
+	rules := make([]*ruleState, len(m.rules))
+	copy(rules, m.rules)
+	m.watchMtx.Unlock()
+
+	type result struct {
+		violated bool
+		msg      string
+	}
+	results := make([]result, len(rules))
+	for i, rs := range rules {
+		results[i].violated, results[i].msg = rs.rule.Violated(s)
+	}
+
+	var alerts []Alert
+	m.watchMtx.Lock()
+	for i, rs := range rules {
+		if !m.hasRule(rs) { // removed during the evaluation
+			continue
+		}
+		if !results[i].violated {
+			rs.violated = time.Time{}
+			if rs.alert != nil {
+				a := *rs.alert
+				a.Status = AlertResolved
+				a.Time = now
+				a.Height, a.DBHeight, a.Minute = s.Height, s.DBHeight, s.Minute
+				alerts = append(alerts, a)
+				rs.alert = nil
+				rs.resolved = now
+			}
+			continue
+		}
+
+		if rs.violated.IsZero() {
+			rs.violated = now
+		}
+		if rs.alert != nil || now.Sub(rs.violated) < rs.rule.For {
+			continue
+		}
+		if !rs.resolved.IsZero() && now.Sub(rs.resolved) < rs.rule.Cooldown {
+			continue
+		}
+
+		rs.alert = &Alert{
+			Rule:     rs.rule.Name,
+			Severity: rs.rule.Severity,
+			Status:   AlertFiring,
+			Message:  results[i].msg,
+			Since:    now,
+			Time:     now,
+			Height:   s.Height,
+			DBHeight: s.DBHeight,
+			Minute:   s.Minute,
+			Network:  network,
+		}
+		alerts = append(alerts, *rs.alert)
+	}
+	m.watchMtx.Unlock()
+
+	m.notifyAlerts(alerts)
+}
+
+// hasRule returns true if the rule is still evaluated. Must be called with watchMtx held.
+func (m *Monitor) hasRule(rs *ruleState) bool {
+	for _, r := range m.rules {
+		if r == rs {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Monitor) notifyAlerts(alerts []Alert) {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	for _, a := range alerts {
+		for _, l := range m.alertListeners {
+			select {
+			case l <- a:
+				m.delivered(l)
+			default:
+				m.dropped(l)
+			}
+		}
+	}
+}
+
+// pollOutcome is the result of a single poll
+type pollOutcome struct {
+	time   time.Time
+	failed bool
+}
+
+// pollLog keeps the outcomes of the polls of the last maxPollAge
+type pollLog struct {
+	mtx   sync.Mutex
+	polls []pollOutcome
+}
+
+func (pl *pollLog) add(t time.Time, failed bool) {
+	pl.mtx.Lock()
+	defer pl.mtx.Unlock()
+	pl.polls = append(pl.polls, pollOutcome{time: t, failed: failed})
+	i := 0
+	for i < len(pl.polls) && t.Sub(pl.polls[i].time) > maxPollAge {
+		i++
+	}
+	if i > 0 {
+		pl.polls = append(pl.polls[:0], pl.polls[i:]...)
+	}
+}
+
+// since returns a copy of the outcomes after t
+func (pl *pollLog) since(t time.Time) []pollOutcome {
+	pl.mtx.Lock()
+	defer pl.mtx.Unlock()
+	var polls []pollOutcome
+	for _, p := range pl.polls {
+		if p.time.After(t) {
+			polls = append(polls, p)
+		}
+	}
+	return polls
+}
@@ -0,0 +1,207 @@
+package monitor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMonitor_Rules(t *testing.T) {
+	m := new(Monitor)
+	alerts := m.NewAlertListener()
+	t0 := time.Unix(1600000000, 0)
+
+	stall := NoNewBlock(time.Minute * 12)
+	stall.Cooldown = time.Minute * 5
+	if err := m.AddRule(stall); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AddRule(stall); err == nil {
+		t.Error("no error for duplicate rule")
+	}
+	if err := m.AddRule(Rule{Name: "empty"}); err == nil {
+		t.Error("no error for rule without condition")
+	}
+
+	expect := func(status AlertStatus, since time.Time) {
+		t.Helper()
+		select {
+		case a := <-alerts:
+			if a.Rule != "no-new-block" || a.Status != status || !a.Since.Equal(since) || a.Severity != SeverityCritical {
+				t.Errorf("unexpected alert %+v, want %s since %s", a, status, since)
+			}
+		default:
+			t.Errorf("no %s alert", status)
+		}
+	}
+	expectNone := func() {
+		t.Helper()
+		select {
+		case a := <-alerts:
+			t.Errorf("unexpected alert %+v", a)
+		default:
+		}
+	}
+
+	m.height, m.dbheight, m.lastBlock = 10, 10, t0
+	m.checkRules(t0.Add(time.Minute))
+	expectNone()
+
+	m.checkRules(t0.Add(time.Minute * 12))
+	expect(AlertFiring, t0.Add(time.Minute*12))
+	if a := m.Alerts(); len(a) != 1 || a[0].Message != "no new block for 12m0s since height 10" {
+		t.Errorf("unexpected firing alerts %+v", a)
+	}
+	m.checkRules(t0.Add(time.Minute * 13))
+	expectNone() // deduplicated
+
+	m.height, m.lastBlock = 11, t0.Add(time.Minute*14)
+	m.checkRules(t0.Add(time.Minute * 14))
+	expect(AlertResolved, t0.Add(time.Minute*12))
+	if a := m.Alerts(); len(a) != 0 {
+		t.Errorf("resolved alert still firing %+v", a)
+	}
+
+	// violated again within the cooldown
+	m.lastBlock = t0
+	m.checkRules(t0.Add(time.Minute * 16))
+	expectNone()
+	m.checkRules(t0.Add(time.Minute * 19))
+	expect(AlertFiring, t0.Add(time.Minute*19))
+
+	m.RemoveRule("no-new-block")
+	if r := m.Rules(); len(r) != 0 {
+		t.Errorf("rule not removed: %v", r)
+	}
+}
+
+func TestMonitor_Rules_For(t *testing.T) {
+	m := new(Monitor)
+	alerts := m.NewAlertListener()
+	t0 := time.Unix(1600000000, 0)
+
+	lag := DBHeightLag(2)
+	lag.For = time.Minute
+	if err := m.AddRule(lag); err != nil {
+		t.Fatal(err)
+	}
+
+	m.height, m.dbheight = 10, 8
+	m.checkRules(t0)
+	m.checkRules(t0.Add(time.Second * 30))
+	if len(alerts) != 0 {
+		t.Fatalf("alert fired before the rule was violated long enough")
+	}
+	m.checkRules(t0.Add(time.Minute))
+	if a := <-alerts; a.Status != AlertFiring || a.Message != "dbheight 8 lags leader height 10 by 2 blocks" {
+		t.Errorf("unexpected alert %+v", a)
+	}
+}
+
+func TestMonitor_Rules_Reentrant(t *testing.T) {
+	m := new(Monitor)
+	alerts := m.NewAlertListener()
+
+	// a rule that calls back into the monitor must not deadlock
+	err := m.AddRule(Rule{Name: "self", Violated: func(s RuleState) (bool, string) {
+		rules := m.Rules()
+		m.RemoveRule("self")
+		return true, fmt.Sprintf("%d rules", len(rules))
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AddRule(Rule{Name: "other", Violated: func(s RuleState) (bool, string) { return true, "other" }}); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.checkRules(time.Unix(1600000000, 0))
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("rule evaluation deadlocked")
+	}
+
+	// the removed rule doesn't fire
+	if a := <-alerts; a.Rule != "other" || len(alerts) != 0 {
+		t.Errorf("unexpected alert %+v", a)
+	}
+}
+
+func TestRuleState_ErrorRate(t *testing.T) {
+	m := new(Monitor)
+	t0 := time.Unix(1600000000, 0)
+	for i := 0; i < 10; i++ {
+		m.polls.add(t0.Add(time.Duration(i)*time.Minute), i >= 4)
+	}
+
+	s := m.ruleState(t0.Add(time.Minute * 9))
+	if rate, n := s.ErrorRate(time.Minute * 4); rate != 1 || n != 5 {
+		t.Errorf("unexpected rate. got = (%v, %d), want = (1, 5)", rate, n)
+	}
+	if rate, n := s.ErrorRate(time.Hour); rate != 0.6 || n != 10 {
+		t.Errorf("unexpected rate. got = (%v, %d), want = (0.6, 10)", rate, n)
+	}
+	if violated, msg := ErrorRate(0.5, time.Minute*5).Violated(s); !violated || msg != "100% of 6 polls failed in the last 5m0s" {
+		t.Errorf("unexpected result. got = (%v, %s)", violated, msg)
+	}
+
+	m.polls.add(t0.Add(time.Hour*2), false)
+	if polls := m.polls.since(time.Time{}); len(polls) != 1 {
+		t.Errorf("old polls not discarded: %d", len(polls))
+	}
+}
+
+func TestBalanceRules(t *testing.T) {
+	m := new(Monitor)
+	alerts := m.NewAlertListener()
+	t0 := time.Unix(1600000000, 0)
+
+	m.ecBalances = map[string]int64{"EC1": 6000}
+	m.fctBalances = map[string]int64{"FA1": 250000000}
+	for _, r := range []Rule{BalanceBelow("EC1", 5000), BalanceAbove("FA1", 300000000), BalanceBelow("EC2", 5000)} {
+		if err := m.AddRule(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m.checkRules(t0)
+	select {
+	case a := <-alerts:
+		t.Fatalf("unexpected alert %+v", a)
+	default:
+	}
+
+	m.ecBalances["EC1"] = 4000
+	m.fctBalances["FA1"] = 350000000
+	m.checkRules(t0.Add(time.Minute))
+	want := map[string]string{
+		"balance-below-EC1": "balance of EC1 is 4000 EC, below 5000 EC",
+		"balance-above-FA1": "balance of FA1 is 3.50000000 FCT, above 3.00000000 FCT",
+	}
+	for range want {
+		select {
+		case a := <-alerts:
+			if a.Status != AlertFiring || a.Message != want[a.Rule] {
+				t.Errorf("unexpected alert %+v", a)
+			}
+		default:
+			t.Fatal("balance alert not fired")
+		}
+	}
+
+	m.ecBalances["EC1"] = 10000
+	m.checkRules(t0.Add(time.Minute * 2))
+	select {
+	case a := <-alerts:
+		if a.Rule != "balance-below-EC1" || a.Status != AlertResolved {
+			t.Errorf("unexpected alert %+v", a)
+		}
+	default:
+		t.Fatal("balance alert not resolved")
+	}
+}
@@ -0,0 +1,204 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// AnchorWindow is the number of dbheights an unanchored directory block is checked for
+// before the anchor watcher gives up on it
+var AnchorWindow int64 = 100
+
+// Anchor networks
+const (
+	Bitcoin  = "bitcoin"
+	Ethereum = "ethereum"
+)
+
+// AnchorsResponse is a struct formed after the response from the factomd "anchors" API.
+// Bitcoin and Ethereum are nil if the directory block has not been anchored on that network yet.
+type AnchorsResponse struct {
+	DirectoryBlockHeight int64           `json:"directoryblockheight"`
+	DirectoryBlockKeyMR  string          `json:"directoryblockkeymr"`
+	Bitcoin              *BitcoinAnchor  `json:"bitcoin"`
+	Ethereum             *EthereumAnchor `json:"ethereum"`
+}
+
+// BitcoinAnchor is the bitcoin transaction that anchors a directory block
+type BitcoinAnchor struct {
+	TransactionHash string `json:"transactionhash"`
+	BlockHash       string `json:"blockhash"`
+}
+
+// EthereumAnchor is the ethereum transaction that anchors a window of directory blocks
+type EthereumAnchor struct {
+	RecordHeight    int64  `json:"recordheight"`
+	DBHeightMax     int64  `json:"dbheightmax"`
+	DBHeightMin     int64  `json:"dbheightmin"`
+	WindowMR        string `json:"windowmr"`
+	ContractAddress string `json:"contractaddress"`
+	TxID            string `json:"txid"`
+	BlockHash       string `json:"blockhash"`
+	TxIndex         int64  `json:"txindex"`
+}
+
+// UnmarshalJSON handles factomd returning false for networks without an anchor
+func (a *AnchorsResponse) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		DirectoryBlockHeight int64           `json:"directoryblockheight"`
+		DirectoryBlockKeyMR  string          `json:"directoryblockkeymr"`
+		Bitcoin              json.RawMessage `json:"bitcoin"`
+		Ethereum             json.RawMessage `json:"ethereum"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	a.DirectoryBlockHeight = raw.DirectoryBlockHeight
+	a.DirectoryBlockKeyMR = raw.DirectoryBlockKeyMR
+	a.Bitcoin = nil
+	a.Ethereum = nil
+	if anchored(raw.Bitcoin) {
+		a.Bitcoin = new(BitcoinAnchor)
+		if err := json.Unmarshal(raw.Bitcoin, a.Bitcoin); err != nil {
+			return err
+		}
+	}
+	if anchored(raw.Ethereum) {
+		a.Ethereum = new(EthereumAnchor)
+		if err := json.Unmarshal(raw.Ethereum, a.Ethereum); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func anchored(raw json.RawMessage) bool {
+	raw = bytes.TrimSpace(raw)
+	return len(raw) > 0 && !bytes.Equal(raw, []byte("false")) && !bytes.Equal(raw, []byte("null"))
+}
+
+// AnchorEvent is sent to anchor listeners when a directory block is anchored on a network.
+type AnchorEvent struct {
+	DBHeight int64  `json:"dbheight"`
+	KeyMR    string `json:"keymr"`
+	// Bitcoin or Ethereum
+	Network   string `json:"network"`
+	TxID      string `json:"txid"`
+	BlockHash string `json:"blockhash"`
+}
+
+type anchorStatus struct {
+	bitcoin, ethereum bool
+}
+
+// AnchorsRequest sends an "anchors" API request for the directory block at the height to the configured node.
+func (m *Monitor) AnchorsRequest(dbheight int64) (*AnchorsResponse, error) {
+	res := new(AnchorsResponse)
+	if err := m.call("anchors", map[string]int64{"height": dbheight}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// WatchAnchors starts tracking the anchors of every directory block saved after the call.
+// After every new dbheight, the anchors of unconfirmed directory blocks are checked and
+// anchor listeners receive an event once a block is anchored on bitcoin and on ethereum.
+// Blocks are checked for up to AnchorWindow dbheights.
+// Calling it more than once has no effect.
+func (m *Monitor) WatchAnchors() {
+	m.watchMtx.Lock()
+	defer m.watchMtx.Unlock()
+	if m.anchors == nil {
+		m.anchors = make(map[int64]*anchorStatus)
+		m.everyDBHeight(m.checkAnchors)
+	}
+}
+
+// NewAnchorListener spawns a new listener that receives an event every time a directory
+// block is anchored.
+// Each reader must have its own listener.
+func (m *Monitor) NewAnchorListener() <-chan AnchorEvent {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan AnchorEvent, 25)
+	m.anchorListeners = append(m.anchorListeners, l)
+	m.register("anchor", l)
+	return l
+}
+
+func (m *Monitor) checkAnchors(dbheight int64) {
+	m.watchMtx.Lock()
+	if _, ok := m.anchors[dbheight]; !ok {
+		m.anchors[dbheight] = new(anchorStatus)
+	}
+	// every new directory block is tracked, but only checked when the watcher is due
+	if !m.due(WatcherAnchors) {
+		m.watchMtx.Unlock()
+		return
+	}
+	pending := make(map[int64]anchorStatus, len(m.anchors))
+	for h, s := range m.anchors {
+		if dbheight-h > AnchorWindow {
+			delete(m.anchors, h)
+			continue
+		}
+		pending[h] = *s
+	}
+	m.watchMtx.Unlock()
+
+	var events []AnchorEvent
+	for h, status := range pending {
+		res, err := m.AnchorsRequest(h)
+		if err != nil {
+			m.notifyError(err)
+			continue
+		}
+
+		if res.Bitcoin != nil && !status.bitcoin {
+			status.bitcoin = true
+			events = append(events, AnchorEvent{
+				DBHeight:  h,
+				KeyMR:     res.DirectoryBlockKeyMR,
+				Network:   Bitcoin,
+				TxID:      res.Bitcoin.TransactionHash,
+				BlockHash: res.Bitcoin.BlockHash,
+			})
+		}
+		if res.Ethereum != nil && !status.ethereum {
+			status.ethereum = true
+			events = append(events, AnchorEvent{
+				DBHeight:  h,
+				KeyMR:     res.DirectoryBlockKeyMR,
+				Network:   Ethereum,
+				TxID:      res.Ethereum.TxID,
+				BlockHash: res.Ethereum.BlockHash,
+			})
+		}
+
+		m.watchMtx.Lock()
+		if status.bitcoin && status.ethereum {
+			delete(m.anchors, h)
+		} else if s, ok := m.anchors[h]; ok {
+			*s = status
+		}
+		m.watchMtx.Unlock()
+	}
+
+	m.notifyAnchors(events)
+}
+
+func (m *Monitor) notifyAnchors(events []AnchorEvent) {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	for _, e := range events {
+		for _, l := range m.anchorListeners {
+			select {
+			case l <- e:
+				m.delivered(l)
+			default:
+				m.dropped(l)
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestAnchorsResponse_UnmarshalJSON(t *testing.T) {
+	var a AnchorsResponse
+	data := `{"directoryblockheight":5,"directoryblockkeymr":"abc","bitcoin":{"transactionhash":"tx","blockhash":"bh"},"ethereum":false}`
+	if err := json.Unmarshal([]byte(data), &a); err != nil {
+		t.Fatal(err)
+	}
+	if a.DirectoryBlockHeight != 5 || a.Bitcoin == nil || a.Bitcoin.TransactionHash != "tx" || a.Ethereum != nil {
+		t.Errorf("unexpected anchors %+v", a)
+	}
+}
+
+func TestMonitor_WatchAnchors(t *testing.T) {
+	s := newTestServer("localhost:9870", 10, 0, time.Second*10, t)
+	defer s.stop()
+	ethereum := false
+	s.handle("anchors", func(params json.RawMessage) interface{} {
+		var p struct {
+			Height int64 `json:"height"`
+		}
+		json.Unmarshal(params, &p)
+		eth := "false"
+		if ethereum {
+			eth = `{"txid":"eth","blockhash":"ethblock"}`
+		}
+		return json.RawMessage(fmt.Sprintf(`{"directoryblockheight":%d,"bitcoin":{"transactionhash":"btc","blockhash":"btcblock"},"ethereum":%s}`, p.Height, eth))
+	})
+
+	m, err := NewMonitorWithConfig("http://localhost:9870/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	m.WatchAnchors()
+	listener := m.NewAnchorListener()
+
+	next := func() AnchorEvent {
+		select {
+		case e := <-listener:
+			return e
+		case <-time.After(time.Second * 2):
+			t.Fatal("anchor event not received")
+		}
+		return AnchorEvent{}
+	}
+
+	s.tick() // dbheight 10
+	if e := next(); e.Network != Bitcoin || e.TxID != "btc" || e.DBHeight != 10 {
+		t.Errorf("unexpected event %+v", e)
+	}
+
+	s.mtx.Lock()
+	ethereum = true
+	s.height = 11
+	s.minute = 0
+	s.mtx.Unlock()
+	s.tick() // dbheight 11
+
+	// ethereum for 10, bitcoin and ethereum for 11
+	got := make(map[AnchorEvent]bool)
+	for i := 0; i < 3; i++ {
+		e := next()
+		got[AnchorEvent{DBHeight: e.DBHeight, Network: e.Network}] = true
+	}
+	for _, want := range []AnchorEvent{{DBHeight: 10, Network: Ethereum}, {DBHeight: 11, Network: Bitcoin}, {DBHeight: 11, Network: Ethereum}} {
+		if !got[want] {
+			t.Errorf("missing %s anchor for %d", want.Network, want.DBHeight)
+		}
+	}
+}
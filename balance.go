@@ -0,0 +1,244 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// base58 alphabet used by human readable factom addresses
+const base58 = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// BalanceResponse is a struct formed after the response from the factomd "factoid-balance"
+// and "entry-credit-balance" APIs.
+type BalanceResponse struct {
+	Balance int64 `json:"balance"`
+}
+
+// BalanceEvent is sent to balance listeners when the balance of a watched address changes.
+type BalanceEvent struct {
+	Address string `json:"address"`
+	// The balance before the change. Factoid balances are in factoshis.
+	Old int64 `json:"old"`
+	// The balance after the change
+	New int64 `json:"new"`
+	// The dbheight at which the change was detected
+	DBHeight int64 `json:"dbheight"`
+	// Low is set for entry credit addresses if the new balance is below Config.ECThreshold
+	Low bool `json:"low,omitempty"`
+}
+
+// FactoidBalanceRequest sends a "factoid-balance" API request for the address to the configured node.
+func (m *Monitor) FactoidBalanceRequest(addr string) (int64, error) {
+	res := new(BalanceResponse)
+	if err := m.call("factoid-balance", map[string]string{"address": addr}, res); err != nil {
+		return 0, err
+	}
+	return res.Balance, nil
+}
+
+// WatchFactoidAddress adds the public factoid address (FA...) to the set of watched addresses.
+// The balance is checked after every new dbheight and factoid balance listeners receive
+// an event whenever it changes.
+// The current balance is fetched immediately, returning an error if the request fails.
+func (m *Monitor) WatchFactoidAddress(addr string) error {
+	if err := checkAddress("FA", addr); err != nil {
+		return err
+	}
+
+	balance, err := m.FactoidBalanceRequest(addr)
+	if err != nil {
+		return err
+	}
+
+	m.watchMtx.Lock()
+	m.watchFactoidBalance(addr, balance)
+	m.watchMtx.Unlock()
+	m.saveWatchlist()
+	return nil
+}
+
+// watchFactoidBalance adds the address with a known balance if it isn't watched yet,
+// must be called with watchMtx held
+func (m *Monitor) watchFactoidBalance(addr string, balance int64) {
+	if m.fctBalances == nil {
+		m.fctBalances = make(map[string]int64)
+		m.everyDBHeight(m.cadenced(WatcherFactoidBalances, func(dbheight int64) {
+			m.checkBalances(dbheight, m.fctBalances, m.FactoidBalanceRequest, m.notifyFactoidBalance)
+		}))
+	}
+	if _, ok := m.fctBalances[addr]; !ok {
+		m.fctBalances[addr] = balance
+	}
+}
+
+// UnwatchFactoidAddress removes the address from the set of watched factoid addresses
+func (m *Monitor) UnwatchFactoidAddress(addr string) {
+	m.watchMtx.Lock()
+	delete(m.fctBalances, addr)
+	m.savedWatchlist.FactoidAddresses = remove(m.savedWatchlist.FactoidAddresses, addr)
+	m.watchMtx.Unlock()
+	m.saveWatchlist()
+}
+
+// NewFactoidBalanceListener spawns a new listener that receives an event every time the
+// balance of a watched factoid address changes.
+// Each reader must have its own listener.
+func (m *Monitor) NewFactoidBalanceListener() <-chan BalanceEvent {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan BalanceEvent, 25)
+	m.fctBalanceListeners = append(m.fctBalanceListeners, l)
+	m.register("factoid balance", l)
+	return l
+}
+
+// ECBalanceRequest sends an "entry-credit-balance" API request for the address to the configured node.
+func (m *Monitor) ECBalanceRequest(addr string) (int64, error) {
+	res := new(BalanceResponse)
+	if err := m.call("entry-credit-balance", map[string]string{"address": addr}, res); err != nil {
+		return 0, err
+	}
+	return res.Balance, nil
+}
+
+// WatchECAddress adds the public entry credit address (EC...) to the set of watched addresses.
+// The balance is checked after every new dbheight and entry credit balance listeners receive
+// an event whenever it changes. Events where the balance fell below Config.ECThreshold
+// are marked as low.
+// The current balance is fetched immediately, returning an error if the request fails.
+func (m *Monitor) WatchECAddress(addr string) error {
+	if err := checkAddress("EC", addr); err != nil {
+		return err
+	}
+
+	balance, err := m.ECBalanceRequest(addr)
+	if err != nil {
+		return err
+	}
+
+	m.watchMtx.Lock()
+	m.watchECBalance(addr, balance)
+	m.watchMtx.Unlock()
+	m.saveWatchlist()
+	return nil
+}
+
+// watchECBalance adds the address with a known balance if it isn't watched yet,
+// must be called with watchMtx held
+func (m *Monitor) watchECBalance(addr string, balance int64) {
+	if m.ecBalances == nil {
+		m.ecBalances = make(map[string]int64)
+		m.everyDBHeight(m.cadenced(WatcherECBalances, func(dbheight int64) {
+			m.checkBalances(dbheight, m.ecBalances, m.ECBalanceRequest, m.notifyECBalance)
+		}))
+	}
+	if _, ok := m.ecBalances[addr]; !ok {
+		m.ecBalances[addr] = balance
+	}
+}
+
+// UnwatchECAddress removes the address from the set of watched entry credit addresses
+func (m *Monitor) UnwatchECAddress(addr string) {
+	m.watchMtx.Lock()
+	delete(m.ecBalances, addr)
+	m.savedWatchlist.ECAddresses = remove(m.savedWatchlist.ECAddresses, addr)
+	m.watchMtx.Unlock()
+	m.saveWatchlist()
+}
+
+// NewECBalanceListener spawns a new listener that receives an event every time the
+// balance of a watched entry credit address changes.
+// Each reader must have its own listener.
+func (m *Monitor) NewECBalanceListener() <-chan BalanceEvent {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan BalanceEvent, 25)
+	m.ecBalanceListeners = append(m.ecBalanceListeners, l)
+	m.register("ec balance", l)
+	return l
+}
+
+// checkBalances requests the balance of every address in balances and calls notify for
+// every address that changed. balances is guarded by watchMtx.
+func (m *Monitor) checkBalances(dbheight int64, balances map[string]int64, request func(string) (int64, error), notify func(BalanceEvent)) {
+	m.watchMtx.Lock()
+	known := make(map[string]int64, len(balances))
+	for addr, b := range balances {
+		known[addr] = b
+	}
+	m.watchMtx.Unlock()
+
+	addrs := make([]string, 0, len(known))
+	for addr := range known {
+		addrs = append(addrs, addr)
+	}
+	results := make([]int64, len(addrs))
+	errs := make([]error, len(addrs))
+	m.parallel(len(addrs), func(i int) {
+		results[i], errs[i] = request(addrs[i])
+	})
+
+	for i, addr := range addrs {
+		old, balance := known[addr], results[i]
+		if errs[i] != nil {
+			m.notifyError(errs[i])
+			continue
+		}
+		if balance == old {
+			continue
+		}
+
+		m.watchMtx.Lock()
+		_, ok := balances[addr]
+		if ok { // address may have been unwatched in the meantime
+			balances[addr] = balance
+		}
+		m.watchMtx.Unlock()
+
+		if ok {
+			notify(BalanceEvent{Address: addr, Old: old, New: balance, DBHeight: dbheight})
+		}
+	}
+}
+
+func (m *Monitor) notifyFactoidBalance(e BalanceEvent) {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	for _, l := range m.fctBalanceListeners {
+		select {
+		case l <- e:
+			m.delivered(l)
+		default:
+			m.dropped(l)
+		}
+	}
+}
+
+func (m *Monitor) notifyECBalance(e BalanceEvent) {
+	e.Low = e.New < m.conf.ECThreshold
+
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	for _, l := range m.ecBalanceListeners {
+		select {
+		case l <- e:
+			m.delivered(l)
+		default:
+			m.dropped(l)
+		}
+	}
+}
+
+// checkAddress verifies that the string looks like a human readable public address
+// with the given prefix
+func checkAddress(prefix, addr string) error {
+	if len(addr) != 52 || !strings.HasPrefix(addr, prefix) {
+		return fmt.Errorf("invalid address %q: must be 52 characters starting with %s", addr, prefix)
+	}
+	for _, c := range addr {
+		if !strings.ContainsRune(base58, c) {
+			return fmt.Errorf("invalid address %q: invalid character %q", addr, c)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package monitor
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckAddress(t *testing.T) {
+	fa := "FA2jK2HcLnRdS94dEcU27rF3meoJfpUcZPSinpb7AwQvPRY6RL1Q"
+	if err := checkAddress("FA", fa); err != nil {
+		t.Errorf("valid address rejected: %v", err)
+	}
+	for _, bad := range []string{"", fa[:51], "EC" + fa[2:], strings.Replace(fa, "2", "0", 1)} {
+		if err := checkAddress("FA", bad); err == nil {
+			t.Errorf("invalid address %q accepted", bad)
+		}
+	}
+}
+
+func TestMonitor_WatchFactoidAddress(t *testing.T) {
+	s := newTestServer("localhost:9873", 10, 0, time.Second*10, t)
+	defer s.stop()
+	balances := map[string]int64{}
+	s.handle("factoid-balance", func(params json.RawMessage) interface{} {
+		var p struct {
+			Address string `json:"address"`
+		}
+		json.Unmarshal(params, &p)
+		return BalanceResponse{Balance: balances[p.Address]}
+	})
+
+	m, err := NewMonitorWithConfig("http://localhost:9873/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	fa := "FA2jK2HcLnRdS94dEcU27rF3meoJfpUcZPSinpb7AwQvPRY6RL1Q"
+	s.mtx.Lock()
+	balances[fa] = 100
+	s.mtx.Unlock()
+
+	if err := m.WatchFactoidAddress("FA123"); err == nil {
+		t.Errorf("no error for invalid address")
+	}
+	if err := m.WatchFactoidAddress(fa); err != nil {
+		t.Fatal(err)
+	}
+	listener := m.NewFactoidBalanceListener()
+
+	s.mtx.Lock()
+	balances[fa] = 250
+	s.mtx.Unlock()
+	s.tick() // new dbheight
+
+	select {
+	case e := <-listener:
+		if e.Address != fa || e.Old != 100 || e.New != 250 {
+			t.Errorf("unexpected event. got = %+v", e)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("balance change not received")
+	}
+}
+
+func TestMonitor_WatchECAddress(t *testing.T) {
+	s := newTestServer("localhost:9872", 10, 0, time.Second*10, t)
+	defer s.stop()
+	balances := map[string]int64{}
+	s.handle("entry-credit-balance", func(params json.RawMessage) interface{} {
+		var p struct {
+			Address string `json:"address"`
+		}
+		json.Unmarshal(params, &p)
+		return BalanceResponse{Balance: balances[p.Address]}
+	})
+
+	m, err := NewMonitorWithConfig("http://localhost:9872/v2", Config{Interval: time.Millisecond * 100, ECThreshold: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	ec := "EC2BURNFCT2PEGNETooo1oooo1oooo1oooo1oooo1oooo19wthin"
+	s.mtx.Lock()
+	balances[ec] = 100
+	s.mtx.Unlock()
+
+	if err := m.WatchECAddress("FA2jK2HcLnRdS94dEcU27rF3meoJfpUcZPSinpb7AwQvPRY6RL1Q"); err == nil {
+		t.Errorf("no error for factoid address")
+	}
+	if err := m.WatchECAddress(ec); err != nil {
+		t.Fatal(err)
+	}
+	listener := m.NewECBalanceListener()
+
+	s.mtx.Lock()
+	balances[ec] = 40
+	s.mtx.Unlock()
+	s.tick() // new dbheight
+
+	select {
+	case e := <-listener:
+		if e.Address != ec || e.Old != 100 || e.New != 40 || !e.Low {
+			t.Errorf("unexpected event. got = %+v", e)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("balance change not received")
+	}
+}
@@ -0,0 +1,61 @@
+package monitor
+
+import "time"
+
+// NewBatchListener spawns a new listener that receives minute events in batches.
+// A batch is sent once it holds size events or once delay has passed since the first
+// event of the batch arrived, whichever happens first. A delay of zero or less only
+// sends full batches.
+// When the monitor is drained with StopAndDrain, the incomplete batch is sent and the
+// listener is closed.
+// Each reader must have its own listener.
+func (m *Monitor) NewBatchListener(size int, delay time.Duration) <-chan []Event {
+	if size < 1 {
+		size = 1
+	}
+	in := m.NewMinuteListener()
+	out := make(chan []Event, 25)
+
+	go func() {
+		var batch []Event
+		var flush <-chan time.Time
+
+		timer := m.clock().NewTimer(delay)
+		timer.Stop()
+		defer timer.Stop()
+
+		send := func() {
+			select {
+			case out <- batch:
+			default:
+			}
+			batch = nil
+			flush = nil
+			timer.Stop()
+		}
+
+		for {
+			select {
+			case e, ok := <-in:
+				if !ok {
+					if len(batch) > 0 {
+						send()
+					}
+					close(out)
+					return
+				}
+				batch = append(batch, e)
+				if len(batch) >= size {
+					send()
+				} else if len(batch) == 1 && delay > 0 {
+					timer.Reset(delay)
+					flush = timer.C()
+				}
+			case <-flush:
+				send()
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,49 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonitor_NewBatchListener(t *testing.T) {
+	s := newTestServer("localhost:9857", 10, 0, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitorWithConfig("http://localhost:9857/v2", Config{Interval: time.Millisecond * 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	batches := m.NewBatchListener(2, time.Millisecond*500)
+	all := m.NewMinuteListener()
+
+	for i := 0; i < 3; i++ {
+		s.tick()
+		<-all
+	}
+
+	// full batch
+	select {
+	case b := <-batches:
+		if len(b) != 2 || b[0].Minute != 1 || b[1].Minute != 2 {
+			t.Errorf("unexpected batch %+v", b)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("full batch not received")
+	}
+
+	// flushed after the delay
+	start := time.Now()
+	select {
+	case b := <-batches:
+		if len(b) != 1 || b[0].Minute != 3 {
+			t.Errorf("unexpected batch %+v", b)
+		}
+		if time.Since(start) < time.Millisecond*200 {
+			t.Errorf("partial batch flushed too early")
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("partial batch not received")
+	}
+}
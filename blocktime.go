@@ -0,0 +1,132 @@
+package monitor
+
+import (
+	"math"
+	"time"
+)
+
+// defaultBlockTimeWindow is the number of blocks used for block time statistics
+// if Config.BlockTimeWindow is not set, one day of 10 minute blocks
+const defaultBlockTimeWindow = 144
+
+// defaultBlockTimeTolerance is the deviation of a block time that is reported as an
+// anomaly if Config.BlockTimeTolerance is not set
+const defaultBlockTimeTolerance = 0.25
+
+// BlockTimeAnomaly is sent to anomaly listeners when a block took significantly longer
+// or shorter than the node's configured block time. A series of long blocks is a sign of
+// a slow leader well before the network stalls completely.
+type BlockTimeAnomaly struct {
+	Height int64 `json:"height"`
+	// The duration of the block, based on the node's block start times if available,
+	// or on the times the monitor observed the blocks
+	Duration time.Duration `json:"duration"`
+	// The node's DBlockSeconds
+	Expected time.Duration `json:"expected"`
+	// The time the anomaly was detected
+	Time time.Time `json:"time"`
+}
+
+// Deviation returns the difference between the duration and the expected duration as a
+// fraction of the expected duration, ie 0.5 for a block that took 50% longer than expected
+// and -0.5 for one that took half as long
+func (a BlockTimeAnomaly) Deviation() float64 {
+	if a.Expected <= 0 {
+		return 0
+	}
+	return float64(a.Duration-a.Expected) / float64(a.Expected)
+}
+
+// NewBlockTimeAnomalyListener spawns a new listener that receives an event for every block
+// whose duration deviates from the node's DBlockSeconds by more than Config.BlockTimeTolerance.
+// Blocks are only timed if the monitor observed both the start of the block and the start
+// of the next one.
+// Each reader must have its own listener.
+func (m *Monitor) NewBlockTimeAnomalyListener() <-chan BlockTimeAnomaly {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan BlockTimeAnomaly, 6)
+	m.anomalyListeners = append(m.anomalyListeners, l)
+	m.register("anomaly", l)
+	return l
+}
+
+// BlockTimeStats contains statistics about the durations of recent blocks
+type BlockTimeStats struct {
+	// The number of blocks the statistics are based on
+	Count int           `json:"count"`
+	Mean  time.Duration `json:"mean"`
+	Min   time.Duration `json:"min"`
+	Max   time.Duration `json:"max"`
+	P50   time.Duration `json:"p50"`
+	P90   time.Duration `json:"p90"`
+	P99   time.Duration `json:"p99"`
+}
+
+// BlockTimeStats returns statistics about the durations of the last Config.BlockTimeWindow
+// blocks. Blocks are only counted if the monitor observed both the start of the block
+// and the start of the next one. All values are zero if no block was observed in full.
+func (m *Monitor) BlockTimeStats() BlockTimeStats {
+	var s BlockTimeStats
+	s.Count = m.blockTimes.count()
+	if s.Count == 0 {
+		return s
+	}
+	s.Mean = m.blockTimes.mean()
+	s.Min, _ = m.blockTimes.percentile(0)
+	s.Max, _ = m.blockTimes.percentile(100)
+	s.P50, _ = m.blockTimes.percentile(50)
+	s.P90, _ = m.blockTimes.percentile(90)
+	s.P99, _ = m.blockTimes.percentile(99)
+	return s
+}
+
+// timeBlock records the duration of the previous block if the event is the start of the block after it
+func (m *Monitor) timeBlock(e Event, observed time.Time) {
+	m.heightMtx.Lock()
+	prev, prevObserved := m.prevBlock, m.prevBlockObserved
+	m.prevBlock, m.prevBlockObserved = e, observed
+	m.heightMtx.Unlock()
+
+	if prevObserved.IsZero() || prev.Minute != 0 || e.Minute != 0 || e.Height != prev.Height+1 {
+		return
+	}
+
+	var d time.Duration
+	if !prev.BlockStart.IsZero() && !e.BlockStart.IsZero() {
+		d = e.BlockStart.Sub(prev.BlockStart)
+	} else {
+		d = observed.Sub(prevObserved)
+	}
+	m.blockTimes.add(d)
+	m.checkBlockTime(prev.Height, d)
+}
+
+// checkBlockTime notifies anomaly listeners if the duration of the block at height is
+// outside the tolerance
+func (m *Monitor) checkBlockTime(height int64, d time.Duration) {
+	m.heightMtx.Lock()
+	expected := time.Duration(m.blockSeconds) * time.Second
+	m.heightMtx.Unlock()
+
+	a := BlockTimeAnomaly{Height: height, Duration: d, Expected: expected}
+	tolerance := m.conf.BlockTimeTolerance
+	if tolerance <= 0 {
+		tolerance = defaultBlockTimeTolerance
+	}
+	if expected <= 0 || math.Abs(a.Deviation()) <= tolerance {
+		return
+	}
+	a.Time = m.clock().Now()
+
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	for _, l := range m.anomalyListeners {
+		select {
+		case l <- a:
+			m.delivered(l)
+		default:
+			m.dropped(l)
+		}
+	}
+}
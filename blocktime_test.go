@@ -0,0 +1,82 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonitor_BlockTimeStats(t *testing.T) {
+	m := new(Monitor)
+	m.blockTimes = newLatencies(3)
+
+	if s := m.BlockTimeStats(); s.Count != 0 || s.Mean != 0 {
+		t.Errorf("unexpected stats without blocks %+v", s)
+	}
+
+	start := time.Now()
+	observed := start
+	block := func(height, minute int64, dur time.Duration) {
+		start = start.Add(dur)
+		observed = observed.Add(time.Second)
+		m.timeBlock(Event{Height: height, Minute: minute, BlockStart: start}, observed)
+	}
+
+	block(10, 0, 0)
+	block(11, 0, time.Minute*8)
+	block(12, 0, time.Minute*10)
+	block(14, 0, time.Minute*20) // skipped a height
+	block(15, 3, time.Minute*10) // not the start of the block
+	block(16, 0, time.Minute*12) // start of 15 not observed
+	block(17, 0, time.Minute*11)
+	block(18, 0, time.Minute*9)
+
+	s := m.BlockTimeStats()
+	if s.Count != 3 {
+		t.Fatalf("unexpected count. got = %d, want = 3", s.Count)
+	}
+	// 10, 11, 9
+	if s.Min != time.Minute*9 || s.Max != time.Minute*11 || s.Mean != time.Minute*10 || s.P50 != time.Minute*10 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+func TestMonitor_BlockTimeAnomaly(t *testing.T) {
+	m := new(Monitor)
+	m.blockTimes = newLatencies(3)
+	m.blockSeconds = 600
+	m.conf.BlockTimeTolerance = 0.2
+	listener := m.NewBlockTimeAnomalyListener()
+
+	start := time.Now()
+	for i, dur := range []time.Duration{0, time.Minute * 11, time.Minute * 13, time.Minute * 7, time.Minute * 9} {
+		start = start.Add(dur)
+		m.timeBlock(Event{Height: int64(10 + i), BlockStart: start}, start)
+	}
+
+	for _, want := range []int64{11, 12} {
+		select {
+		case a := <-listener:
+			if a.Height != want || a.Expected != time.Minute*10 {
+				t.Errorf("unexpected anomaly %+v, want height %d", a, want)
+			}
+		default:
+			t.Fatalf("no anomaly for height %d", want)
+		}
+	}
+	select {
+	case a := <-listener:
+		t.Errorf("unexpected anomaly %+v", a)
+	default:
+	}
+}
+
+func TestBlockTimeAnomaly_Deviation(t *testing.T) {
+	a := BlockTimeAnomaly{Duration: time.Minute * 15, Expected: time.Minute * 10}
+	if d := a.Deviation(); d != 0.5 {
+		t.Errorf("got = %v, want = 0.5", d)
+	}
+	a.Duration = time.Minute * 5
+	if d := a.Deviation(); d != -0.5 {
+		t.Errorf("got = %v, want = -0.5", d)
+	}
+}
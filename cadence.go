@@ -0,0 +1,44 @@
+package monitor
+
+import "time"
+
+// The names of the watchers whose cadence can be set via Config.Cadences
+const (
+	WatcherChains              = "chains"
+	WatcherFactoidBalances     = "factoid-balances"
+	WatcherECBalances          = "ec-balances"
+	WatcherECRate              = "ec-rate"
+	WatcherAnchors             = "anchors"
+	WatcherPendingEntries      = "pending-entries"
+	WatcherPendingTransactions = "pending-transactions"
+	WatcherVersion             = "version"
+)
+
+// due returns true if the watcher's cadence has passed since it last ran, and records
+// the run. Watchers without a cadence are always due.
+func (m *Monitor) due(watcher string) bool {
+	cadence := m.conf.Cadences[watcher]
+	if cadence <= 0 {
+		return true
+	}
+	now := m.clock().Now()
+	m.cadenceMtx.Lock()
+	defer m.cadenceMtx.Unlock()
+	if last, ok := m.lastRuns[watcher]; ok && now.Sub(last) < cadence {
+		return false
+	}
+	if m.lastRuns == nil {
+		m.lastRuns = make(map[string]time.Time)
+	}
+	m.lastRuns[watcher] = now
+	return true
+}
+
+// cadenced wraps a dbheight watcher so it only runs when it is due
+func (m *Monitor) cadenced(watcher string, f func(dbheight int64)) func(dbheight int64) {
+	return func(dbheight int64) {
+		if m.due(watcher) {
+			f(dbheight)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonitor_Cadences(t *testing.T) {
+	m := new(Monitor)
+	m.conf.Cadences = map[string]time.Duration{WatcherAnchors: time.Millisecond * 100}
+
+	var runs []int64
+	check := m.cadenced(WatcherAnchors, func(dbheight int64) { runs = append(runs, dbheight) })
+	check(10)
+	check(11)
+	time.Sleep(time.Millisecond * 120)
+	check(12)
+	if len(runs) != 2 || runs[0] != 10 || runs[1] != 12 {
+		t.Errorf("unexpected runs %v", runs)
+	}
+
+	for i := 0; i < 3; i++ {
+		if !m.due(WatcherChains) {
+			t.Fatal("watcher without cadence not due")
+		}
+	}
+}
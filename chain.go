@@ -0,0 +1,259 @@
+package monitor
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxEntryBlocks limits how far back the chain watcher follows a chain after a
+// single new dbheight
+var maxEntryBlocks = 1000
+
+// ChainHeadResponse is a struct formed after the response from the factomd "chain-head" API.
+type ChainHeadResponse struct {
+	ChainHead          string `json:"chainhead"`
+	ChainInProcessList bool   `json:"chaininprocesslist"`
+}
+
+// EntryBlockResponse is a struct formed after the response from the factomd "entry-block" API.
+type EntryBlockResponse struct {
+	Header    EntryBlockHeader `json:"header"`
+	EntryList []EntryBlockItem `json:"entrylist"`
+}
+
+// EntryBlockHeader is the header of an entry block
+type EntryBlockHeader struct {
+	BlockSequenceNumber int64  `json:"blocksequencenumber"`
+	ChainID             string `json:"chainid"`
+	PrevKeyMR           string `json:"prevkeymr"`
+	Timestamp           int64  `json:"timestamp"`
+	DBHeight            int64  `json:"dbheight"`
+}
+
+// EntryBlockItem is a single entry in an entry block
+type EntryBlockItem struct {
+	EntryHash string `json:"entryhash"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// EntryEvent is sent to entry listeners for every new entry on a watched chain.
+type EntryEvent struct {
+	ChainID   string `json:"chainid"`
+	EntryHash string `json:"entryhash"`
+	// The KeyMR of the entry block containing the entry
+	EntryBlock string `json:"entryblock"`
+	// The height of the directory block containing the entry block
+	DBHeight int64 `json:"dbheight"`
+	// The time the entry was included in the block
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ChainHeadRequest sends a "chain-head" API request for the chain to the configured node.
+func (m *Monitor) ChainHeadRequest(chainID string) (*ChainHeadResponse, error) {
+	res := new(ChainHeadResponse)
+	if err := m.call("chain-head", map[string]string{"chainid": chainID}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// EntryBlockRequest sends an "entry-block" API request for the keymr to the configured node.
+func (m *Monitor) EntryBlockRequest(keymr string) (*EntryBlockResponse, error) {
+	res := new(EntryBlockResponse)
+	if err := m.call("entry-block", map[string]string{"keymr": keymr}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// EntryGapError is sent to error listeners when more than maxEntryBlocks entry blocks
+// were added to a watched chain since it was last checked. Entry listeners receive the
+// entries of the newest blocks and the chain watcher continues from the chain head, the
+// entries of the blocks between Known and Oldest are skipped.
+type EntryGapError struct {
+	ChainID string
+	// The last chain head the monitor knew
+	Known string
+	// The KeyMR of the oldest entry block whose entries were delivered
+	Oldest string
+}
+
+func (e *EntryGapError) Error() string {
+	return fmt.Sprintf("chain %s: more than %d new entry blocks, skipped entry blocks from %s to %s", e.ChainID, maxEntryBlocks, e.Known, e.Oldest)
+}
+
+// WatchChain adds the chain to the set of watched chains. After every new dbheight,
+// the heads of watched chains are checked and entry listeners receive an event for
+// every entry that was added since.
+// The current chain head is fetched immediately, returning an error if the request fails.
+// Watching a chain that is already watched has no effect.
+func (m *Monitor) WatchChain(chainID string) error {
+	if err := checkHash("chain id", chainID); err != nil {
+		return err
+	}
+
+	m.watchMtx.Lock()
+	_, ok := m.chains[chainID]
+	m.watchMtx.Unlock()
+	if ok {
+		return nil
+	}
+
+	head, err := m.ChainHeadRequest(chainID)
+	if err != nil {
+		return err
+	}
+
+	m.watchMtx.Lock()
+	m.watchChainHead(chainID, head.ChainHead)
+	m.watchMtx.Unlock()
+	m.saveWatchlist()
+	return nil
+}
+
+// watchChainHead adds the chain with a known head if it isn't watched yet, must be
+// called with watchMtx held
+func (m *Monitor) watchChainHead(chainID, head string) {
+	if m.chains == nil {
+		m.chains = make(map[string]string)
+		m.everyDBHeight(m.cadenced(WatcherChains, m.checkChains))
+	}
+	if _, ok := m.chains[chainID]; !ok {
+		m.chains[chainID] = head
+	}
+}
+
+// UnwatchChain removes the chain from the set of watched chains
+func (m *Monitor) UnwatchChain(chainID string) {
+	m.watchMtx.Lock()
+	delete(m.chains, chainID)
+	m.savedWatchlist.Chains = remove(m.savedWatchlist.Chains, chainID)
+	m.watchMtx.Unlock()
+	m.saveWatchlist()
+}
+
+// WatchedChains returns the ids of all watched chains
+func (m *Monitor) WatchedChains() []string {
+	m.watchMtx.Lock()
+	defer m.watchMtx.Unlock()
+	chains := make([]string, 0, len(m.chains))
+	for id := range m.chains {
+		chains = append(chains, id)
+	}
+	return chains
+}
+
+// NewEntryListener spawns a new listener that receives an event for every new entry on a watched chain.
+// Each reader must have its own listener.
+func (m *Monitor) NewEntryListener() <-chan EntryEvent {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan EntryEvent, 100)
+	m.entryListeners = append(m.entryListeners, l)
+	m.register("entry", l)
+	return l
+}
+
+func (m *Monitor) checkChains(dbheight int64) {
+	m.watchMtx.Lock()
+	heads := make(map[string]string, len(m.chains))
+	for id, head := range m.chains {
+		heads[id] = head
+	}
+	m.watchMtx.Unlock()
+
+	ids := make([]string, 0, len(heads))
+	for id := range heads {
+		ids = append(ids, id)
+	}
+	results := make([]*ChainHeadResponse, len(ids))
+	errs := make([]error, len(ids))
+	m.parallel(len(ids), func(i int) {
+		results[i], errs[i] = m.ChainHeadRequest(ids[i])
+	})
+
+	for i, id := range ids {
+		known, head := heads[id], results[i]
+		if errs[i] != nil {
+			m.notifyError(errs[i])
+			continue
+		}
+		if head.ChainHead == known {
+			continue
+		}
+
+		events, err := m.newEntries(id, head.ChainHead, known)
+		if err != nil {
+			m.notifyError(err)
+			if _, gap := err.(*EntryGapError); !gap {
+				continue
+			}
+		}
+
+		m.watchMtx.Lock()
+		if _, ok := m.chains[id]; ok { // chain may have been unwatched in the meantime
+			m.chains[id] = head.ChainHead
+		}
+		m.watchMtx.Unlock()
+
+		m.notifyEntries(events)
+	}
+}
+
+// newEntries follows the chain back from head until it reaches known and returns
+// the entries in between, oldest first. If there are more than maxEntryBlocks blocks
+// in between, it returns the entries of the newest ones along with an *EntryGapError.
+func (m *Monitor) newEntries(chainID, head, known string) ([]EntryEvent, error) {
+	var blocks [][]EntryEvent
+	var gap error
+	keymr, oldest := head, head
+	for i := 0; keymr != known; i++ {
+		if i == maxEntryBlocks {
+			gap = &EntryGapError{ChainID: chainID, Known: known, Oldest: oldest}
+			break
+		}
+
+		eblock, err := m.EntryBlockRequest(keymr)
+		if err != nil {
+			return nil, err
+		}
+
+		var entries []EntryEvent
+		for _, e := range eblock.EntryList {
+			entries = append(entries, EntryEvent{
+				ChainID:    chainID,
+				EntryHash:  e.EntryHash,
+				EntryBlock: keymr,
+				DBHeight:   eblock.Header.DBHeight,
+				Timestamp:  time.Unix(e.Timestamp, 0),
+			})
+		}
+		blocks = append(blocks, entries)
+
+		oldest, keymr = keymr, eblock.Header.PrevKeyMR
+		if keymr == zeroHash { // reached the first block of the chain
+			break
+		}
+	}
+
+	var events []EntryEvent
+	for i := len(blocks) - 1; i >= 0; i-- {
+		events = append(events, blocks[i]...)
+	}
+	return events, gap
+}
+
+func (m *Monitor) notifyEntries(events []EntryEvent) {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	for _, e := range events {
+		for _, l := range m.entryListeners {
+			select {
+			case l <- e:
+				m.delivered(l)
+			default:
+				m.dropped(l)
+			}
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package monitor
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeChain serves chain-head and entry-block requests for a single chain
+type fakeChain struct {
+	id      string
+	head    string
+	eblocks map[string]*EntryBlockResponse
+}
+
+func newFakeChain(ts *testServer, id, head string) *fakeChain {
+	fc := &fakeChain{id: id, head: head, eblocks: make(map[string]*EntryBlockResponse)}
+	ts.handle("chain-head", func(params json.RawMessage) interface{} {
+		return ChainHeadResponse{ChainHead: fc.head}
+	})
+	ts.handle("entry-block", func(params json.RawMessage) interface{} {
+		var p struct {
+			KeyMR string `json:"keymr"`
+		}
+		json.Unmarshal(params, &p)
+		return fc.eblocks[p.KeyMR]
+	})
+	return fc
+}
+
+// add appends an entry block with the given entries, must be called with the server's mtx held
+func (fc *fakeChain) add(keymr string, dbheight int64, entries ...string) {
+	eb := new(EntryBlockResponse)
+	eb.Header.ChainID = fc.id
+	eb.Header.PrevKeyMR = fc.head
+	eb.Header.DBHeight = dbheight
+	for _, e := range entries {
+		eb.EntryList = append(eb.EntryList, EntryBlockItem{EntryHash: e, Timestamp: time.Now().Unix()})
+	}
+	fc.eblocks[keymr] = eb
+	fc.head = keymr
+}
+
+func hash(c string) string {
+	return strings.Repeat(c, 64)
+}
+
+func TestMonitor_WatchChain(t *testing.T) {
+	s := newTestServer("localhost:9875", 10, 0, time.Second*10, t)
+	defer s.stop()
+	fc := newFakeChain(s, hash("c"), hash("a"))
+
+	m, err := NewMonitorWithConfig("http://localhost:9875/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	if err := m.WatchChain("foo"); err == nil {
+		t.Errorf("no error for invalid chain id")
+	}
+	if err := m.WatchChain(hash("c")); err != nil {
+		t.Fatal(err)
+	}
+	if w := m.WatchedChains(); len(w) != 1 || w[0] != hash("c") {
+		t.Errorf("unexpected watched chains: %v", w)
+	}
+
+	listener := m.NewEntryListener()
+
+	s.mtx.Lock()
+	fc.add(hash("b"), 10, hash("1"), hash("2"))
+	fc.add(hash("d"), 10, hash("3"))
+	s.mtx.Unlock()
+	s.tick() // new dbheight
+
+	for _, want := range []string{hash("1"), hash("2"), hash("3")} {
+		select {
+		case e := <-listener:
+			if e.EntryHash != want || e.ChainID != hash("c") || e.DBHeight != 10 {
+				t.Errorf("unexpected entry event. got = %+v, want entry %s", e, want)
+			}
+		case <-time.After(time.Second * 2):
+			t.Fatalf("entry %s not received", want)
+		}
+	}
+
+	m.UnwatchChain(hash("c"))
+	if w := m.WatchedChains(); len(w) != 0 {
+		t.Errorf("chain still watched: %v", w)
+	}
+}
+
+func TestMonitor_WatchChainGap(t *testing.T) {
+	ogm := maxEntryBlocks
+	maxEntryBlocks = 3
+	defer func() { maxEntryBlocks = ogm }()
+
+	s := newTestServer("localhost:9819", 10, 0, time.Second*10, t)
+	defer s.stop()
+	fc := newFakeChain(s, hash("c"), hash("a"))
+
+	m, err := NewMonitorWithConfig("http://localhost:9819/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	if err := m.WatchChain(hash("c")); err != nil {
+		t.Fatal(err)
+	}
+	listener := m.NewEntryListener()
+	errs := m.NewErrorListener()
+
+	s.mtx.Lock()
+	for _, c := range []string{"1", "2", "3", "4", "5"} {
+		fc.add(hash("b" + c)[:64], 10, hash(c))
+	}
+	s.mtx.Unlock()
+	s.tick() // new dbheight
+
+	select {
+	case err := <-errs:
+		gap, ok := err.(*EntryGapError)
+		if !ok {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if gap.ChainID != hash("c") || gap.Known != hash("a") || gap.Oldest != hash("b3")[:64] {
+			t.Errorf("unexpected gap: %+v", gap)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("no gap error")
+	}
+
+	for _, want := range []string{hash("3"), hash("4"), hash("5")} {
+		select {
+		case e := <-listener:
+			if e.EntryHash != want {
+				t.Errorf("unexpected entry event. got = %s, want = %s", e.EntryHash, want)
+			}
+		case <-time.After(time.Second * 2):
+			t.Fatalf("entry %s not received", want)
+		}
+	}
+
+	s.mtx.Lock()
+	fc.add(hash("d"), 11, hash("6"))
+	s.mtx.Unlock()
+	for i := 0; i < 10; i++ { // next dbheight
+		s.tick()
+	}
+
+	select {
+	case e := <-listener:
+		if e.EntryHash != hash("6") {
+			t.Errorf("unexpected entry event after gap. got = %s, want = %s", e.EntryHash, hash("6"))
+		}
+	case err := <-errs:
+		t.Fatalf("unexpected error after gap: %v", err)
+	case <-time.After(time.Second * 2):
+		t.Fatal("chain watcher did not resume after gap")
+	}
+}
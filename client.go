@@ -0,0 +1,143 @@
+package monitor
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+
+	"github.com/AdamSLevy/jsonrpc2/v14"
+)
+
+// OpenNode is the url of the public Open Node run by the Factom Protocol, which is
+// served by several backends behind a load balancer
+const OpenNode = "https://api.factomd.net/v2"
+
+// newClient creates the JSON-RPC client used for all API requests
+func newClient(endpoint string, conf Config) (*jsonrpc2.Client, error) {
+	if conf.Client != nil {
+		return conf.Client, nil
+	}
+
+	client := new(jsonrpc2.Client)
+	if conf.HTTPClient != nil {
+		client.Client = *conf.HTTPClient
+	}
+
+	if (conf.StickySessions || isOpenNode(endpoint)) && client.Jar == nil {
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			return nil, err
+		}
+		client.Jar = jar
+	}
+
+	if conf.RPCUser != "" || conf.RPCPassword != "" {
+		client.BasicAuth = true
+		client.User = conf.RPCUser
+		client.Password = conf.RPCPassword
+	}
+
+	if len(conf.Headers) > 0 || conf.UserAgent != "" {
+		client.Header = conf.Headers.Clone()
+		if client.Header == nil {
+			client.Header = make(http.Header)
+		}
+		if conf.UserAgent != "" {
+			client.Header.Set("User-Agent", conf.UserAgent)
+		}
+	}
+
+	tlsConf, err := conf.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var proxy *url.URL
+	if conf.Proxy != "" {
+		if proxy, err = url.Parse(conf.Proxy); err != nil {
+			return nil, err
+		}
+		switch proxy.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("unsupported proxy scheme %q", proxy.Scheme)
+		}
+	}
+
+	if tlsConf != nil || proxy != nil {
+		transport, err := cloneTransport(client.Transport)
+		if err != nil {
+			return nil, err
+		}
+		if tlsConf != nil {
+			transport.TLSClientConfig = tlsConf
+		}
+		if proxy != nil {
+			transport.Proxy = http.ProxyURL(proxy)
+		}
+		client.Transport = transport
+	}
+
+	return client, nil
+}
+
+// isOpenNode returns true if the url points to the Open Node
+func isOpenNode(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return false
+	}
+	open, _ := url.Parse(OpenNode)
+	return u.Hostname() == open.Hostname()
+}
+
+// cloneTransport returns a copy of the round tripper that can be modified
+func cloneTransport(rt http.RoundTripper) (*http.Transport, error) {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	transport, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, errors.New("the http client's transport must be an *http.Transport to apply TLS or proxy settings")
+	}
+	return transport.Clone(), nil
+}
+
+// tlsConfig combines the TLS settings. Returns nil if there are none.
+func (conf Config) tlsConfig() (*tls.Config, error) {
+	if conf.TLSConfig == nil && conf.CAFile == "" && conf.CertFile == "" && conf.KeyFile == "" {
+		return nil, nil
+	}
+
+	tlsConf := new(tls.Config)
+	if conf.TLSConfig != nil {
+		tlsConf = conf.TLSConfig.Clone()
+	}
+
+	if conf.CAFile != "" {
+		pem, err := ioutil.ReadFile(conf.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", conf.CAFile)
+		}
+		tlsConf.RootCAs = pool
+	}
+
+	if conf.CertFile != "" || conf.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(conf.CertFile, conf.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConf.Certificates = append(tlsConf.Certificates, cert)
+	}
+
+	return tlsConf, nil
+}
@@ -0,0 +1,230 @@
+package monitor
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/AdamSLevy/jsonrpc2/v14"
+)
+
+type countingTransport struct {
+	requests int64
+}
+
+func (ct *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	atomic.AddInt64(&ct.requests, 1)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestMonitor_HTTPClient(t *testing.T) {
+	s := newTestServer("localhost:9880", 10, 5, time.Second*10, t)
+	defer s.stop()
+
+	ct := new(countingTransport)
+	m, err := NewMonitorWithConfig("http://localhost:9880/v2", Config{
+		HTTPClient: &http.Client{Transport: ct},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Stop()
+
+	// current-minute, dblock-by-height, and properties
+	if n := atomic.LoadInt64(&ct.requests); n != 3 {
+		t.Errorf("custom http client was not used. got = %d requests, want = 3", n)
+	}
+
+	client := new(jsonrpc2.Client)
+	client.Transport = ct
+	m, err = NewMonitorWithConfig("http://localhost:9880/v2", Config{
+		Client:     client,
+		HTTPClient: http.DefaultClient,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Stop()
+
+	if m.client != client {
+		t.Errorf("custom jsonrpc2 client was not used")
+	}
+	if n := atomic.LoadInt64(&ct.requests); n != 6 {
+		t.Errorf("custom jsonrpc2 client was not used. got = %d requests, want = 6", n)
+	}
+}
+
+func TestMonitor_TLS(t *testing.T) {
+	s := newTestServer("localhost:9879", 10, 5, time.Second*10, t)
+	defer s.stop()
+
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(s.api))
+	defer tlsServer.Close()
+
+	if _, err := NewMonitor(tlsServer.URL); err == nil {
+		t.Fatalf("monitor accepted unknown certificate authority")
+	}
+
+	dir, err := ioutil.TempDir("", "factom-monitor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := filepath.Join(dir, "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw})
+	if err := ioutil.WriteFile(ca, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewMonitorWithConfig(tlsServer.URL, Config{CAFile: ca})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Stop()
+
+	if _, err := NewMonitorWithConfig(tlsServer.URL, Config{CertFile: filepath.Join(dir, "missing.pem")}); err == nil {
+		t.Errorf("no error for missing client certificate")
+	}
+
+	if _, err := NewMonitorWithConfig(tlsServer.URL, Config{
+		CAFile:     ca,
+		HTTPClient: &http.Client{Transport: new(countingTransport)},
+	}); err == nil {
+		t.Errorf("no error applying TLS settings to a custom transport")
+	}
+}
+
+func TestMonitor_RPCAuth(t *testing.T) {
+	s := newTestServer("localhost:9878", 10, 5, time.Second*10, t)
+	defer s.stop()
+
+	auth := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "user" || pass != "pass" {
+			http.Error(rw, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		s.api(rw, r)
+	}))
+	defer auth.Close()
+
+	if _, err := NewMonitor(auth.URL); err == nil {
+		t.Fatalf("monitor connected without credentials")
+	}
+
+	m, err := NewMonitorWithConfig(auth.URL, Config{RPCUser: "user", RPCPassword: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Stop()
+}
+
+func TestMonitor_Headers(t *testing.T) {
+	s := newTestServer("localhost:9824", 10, 5, time.Second*10, t)
+	defer s.stop()
+
+	hosted := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "secret" || r.UserAgent() != "factom-monitor-test" {
+			http.Error(rw, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		s.api(rw, r)
+	}))
+	defer hosted.Close()
+
+	if _, err := NewMonitor(hosted.URL); err == nil {
+		t.Fatalf("monitor connected without headers")
+	}
+
+	headers := http.Header{}
+	headers.Set("X-Api-Key", "secret")
+	m, err := NewMonitorWithConfig(hosted.URL, Config{Headers: headers, UserAgent: "factom-monitor-test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Stop()
+}
+
+func TestMonitor_Proxy(t *testing.T) {
+	s := newTestServer("localhost:9877", 10, 5, time.Second*10, t)
+	defer s.stop()
+
+	var proxied int64
+	proxy := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		// proxied requests contain the absolute url of the destination
+		if r.URL.Host == "factomd.invalid" {
+			atomic.AddInt64(&proxied, 1)
+		}
+		s.api(rw, r)
+	}))
+	defer proxy.Close()
+
+	m, err := NewMonitorWithConfig("http://factomd.invalid/v2", Config{Proxy: proxy.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Stop()
+
+	// current-minute, dblock-by-height, and properties
+	if n := atomic.LoadInt64(&proxied); n != 3 {
+		t.Errorf("requests did not go through proxy. got = %d, want = 3", n)
+	}
+
+	if _, err := NewMonitorWithConfig("http://factomd.invalid/v2", Config{Proxy: "ftp://localhost"}); err == nil {
+		t.Errorf("no error for unsupported proxy scheme")
+	}
+}
+
+func TestMonitor_StickySessions(t *testing.T) {
+	s := newTestServer("localhost:9846", 10, 5, time.Second*10, t)
+	defer s.stop()
+
+	var sticky int64
+	lb := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("backend"); err == nil && c.Value == "2" {
+			atomic.AddInt64(&sticky, 1)
+		}
+		http.SetCookie(rw, &http.Cookie{Name: "backend", Value: "2"})
+		s.api(rw, r)
+	}))
+	defer lb.Close()
+
+	m, err := NewMonitor(lb.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Stop()
+	if n := atomic.LoadInt64(&sticky); n != 0 {
+		t.Errorf("cookies kept without sticky sessions: %d", n)
+	}
+
+	m, err = NewMonitorWithConfig(lb.URL, Config{StickySessions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Stop()
+	// every request after the first one
+	if n := atomic.LoadInt64(&sticky); n != 2 {
+		t.Errorf("got %d requests with the session cookie, want 2", n)
+	}
+}
+
+func Test_isOpenNode(t *testing.T) {
+	for url, want := range map[string]bool{
+		OpenNode:                         true,
+		"http://api.factomd.net:8088/v2": true,
+		"http://localhost:8088/v2":       false,
+		"https://factomd.net.example/v2": false,
+	} {
+		if got := isOpenNode(url); got != want {
+			t.Errorf("isOpenNode(%s) = %v, want %v", url, got, want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package monitor
+
+import "time"
+
+// Clock provides the current time and timers to the monitor.
+// Config.Clock replaces the system clock, ie to test polling and estimates
+// deterministically. Request timeouts and latencies always use the system clock.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a single event timer, see time.Timer
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker delivers ticks at intervals, see time.Ticker
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// systemClock is the Clock of the time package
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                   { return time.Now() }
+func (systemClock) NewTimer(d time.Duration) Timer   { return systemTimer{time.NewTimer(d)} }
+func (systemClock) NewTicker(d time.Duration) Ticker { return systemTicker{time.NewTicker(d)} }
+
+type systemTimer struct{ *time.Timer }
+
+func (t systemTimer) C() <-chan time.Time { return t.Timer.C }
+
+type systemTicker struct{ *time.Ticker }
+
+func (t systemTicker) C() <-chan time.Time { return t.Ticker.C }
+
+// clock returns the configured clock or the system clock
+func (m *Monitor) clock() Clock {
+	if m.conf.Clock != nil {
+		return m.conf.Clock
+	}
+	return systemClock{}
+}
@@ -0,0 +1,172 @@
+// Command factom-monitor exposes the monitor library on the command line.
+//
+// Usage:
+//
+//	factom-monitor tail [--url URL] [--format text|json]
+//	factom-monitor wait --height N [--url URL] [--format text|json] [--timeout DURATION]
+//
+// tail prints every minute event as it happens. wait blocks until the network
+// has completed the given height, prints the event that reached it, and exits.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+const defaultURL = "http://localhost:8088/v2"
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage:")
+	fmt.Fprintln(os.Stderr, "  factom-monitor tail [--url URL] [--format text|json]")
+	fmt.Fprintln(os.Stderr, "  factom-monitor wait --height N [--url URL] [--format text|json] [--timeout DURATION]")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "tail":
+		err = tail(os.Args[2:])
+	case "wait":
+		err = wait(os.Args[2:])
+	case "-h", "--help", "help":
+		usage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func tail(args []string) error {
+	fs := flag.NewFlagSet("tail", flag.ExitOnError)
+	url := fs.String("url", defaultURL, "url of the factomd api endpoint")
+	format := fs.String("format", "text", "output format: text or json")
+	fs.Parse(args)
+
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	mon, err := monitor.NewMonitor(*url)
+	if err != nil {
+		return err
+	}
+	defer mon.Stop()
+
+	go logErrors(mon.NewErrorListener())
+
+	for e := range mon.NewMinuteListener() {
+		if err := printEvent(os.Stdout, *format, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func wait(args []string) error {
+	fs := flag.NewFlagSet("wait", flag.ExitOnError)
+	url := fs.String("url", defaultURL, "url of the factomd api endpoint")
+	format := fs.String("format", "text", "output format: text or json")
+	height := fs.Int64("height", -1, "the height to wait for")
+	timeout := fs.Duration("timeout", 0, "give up after this long (0 waits forever)")
+	fs.Parse(args)
+
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	if *height < 0 {
+		return fmt.Errorf("wait requires a --height")
+	}
+
+	mon, err := monitor.NewMonitor(*url)
+	if err != nil {
+		return err
+	}
+	defer mon.Stop()
+
+	go logErrors(mon.NewErrorListener())
+
+	// subscribe before checking the current state so no event is missed
+	listener := mon.NewMinuteListener()
+
+	state := mon.State()
+	if state.Height >= *height {
+		return printEvent(os.Stdout, *format, monitor.Event{
+			Height:      state.Height,
+			DBHeight:    state.DBHeight,
+			Minute:      state.Minute,
+			BlockStart:  state.BlockStart,
+			MinuteStart: state.MinuteStart,
+			NodeTime:    state.NodeTime,
+			Network:     state.Network,
+		})
+	}
+
+	var expire <-chan time.Time
+	if *timeout > 0 {
+		expire = time.After(*timeout)
+	}
+
+	for {
+		select {
+		case e := <-listener:
+			if e.Height >= *height {
+				return printEvent(os.Stdout, *format, e)
+			}
+		case <-expire:
+			return fmt.Errorf("timed out waiting for height %d", *height)
+		case <-mon.Done():
+			if err := mon.Err(); err != nil {
+				return err
+			}
+			return fmt.Errorf("monitor stopped before height %d", *height)
+		}
+	}
+}
+
+func logErrors(l <-chan error) {
+	for err := range l {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	}
+}
+
+func checkFormat(format string) error {
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown format %q", format)
+	}
+	return nil
+}
+
+// printEvent writes the event with the node's time, or the start of the minute if the
+// node doesn't report its time
+func printEvent(w io.Writer, format string, e monitor.Event) error {
+	var err error
+	if format == "json" {
+		err = json.NewEncoder(w).Encode(e)
+	} else {
+		t := e.NodeTime
+		if t.IsZero() {
+			t = e.MinuteStart
+		}
+		_, err = fmt.Fprintf(w, "%s height=%d dbheight=%d minute=%d\n", t.Format(time.RFC3339), e.Height, e.DBHeight, e.Minute)
+	}
+	return err
+}
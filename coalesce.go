@@ -0,0 +1,75 @@
+package monitor
+
+import "time"
+
+// CoalescedEvent is sent to coalescing listeners and covers one or more minute events
+type CoalescedEvent struct {
+	// The oldest event covered
+	First Event `json:"first"`
+	// The most recent event
+	Latest Event `json:"latest"`
+	// The number of minute events covered
+	Count int `json:"count"`
+}
+
+// NewCoalescedListener spawns a new listener that merges minute events the reader was
+// too slow to receive into a single event covering all of them, so a reader that only
+// cares about the latest state never processes a backlog of stale events.
+// If window is greater than zero, events are also held back until no new event arrived
+// for the duration of the window, which merges bursts such as catching up after an outage.
+// When the monitor is drained with StopAndDrain, the pending event is delivered and the
+// listener is closed.
+// Each reader must have its own listener.
+func (m *Monitor) NewCoalescedListener(window time.Duration) <-chan CoalescedEvent {
+	in := m.NewMinuteListener()
+	out := make(chan CoalescedEvent)
+
+	go func() {
+		var pending CoalescedEvent
+		var quiet <-chan time.Time
+		ready := false
+
+		timer := m.clock().NewTimer(window)
+		timer.Stop()
+		defer timer.Stop()
+
+		for {
+			var send chan<- CoalescedEvent
+			if ready {
+				send = out
+			}
+
+			select {
+			case e, ok := <-in:
+				if !ok {
+					if pending.Count > 0 {
+						out <- pending
+					}
+					close(out)
+					return
+				}
+				if pending.Count == 0 {
+					pending.First = e
+				}
+				pending.Latest = e
+				pending.Count++
+				if window > 0 {
+					timer.Stop()
+					timer.Reset(window)
+					quiet = timer.C()
+					ready = false
+				} else {
+					ready = true
+				}
+			case <-quiet:
+				quiet = nil
+				ready = true
+			case send <- pending:
+				pending = CoalescedEvent{}
+				ready = false
+			}
+		}
+	}()
+
+	return out
+}
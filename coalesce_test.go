@@ -0,0 +1,51 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonitor_NewCoalescedListener(t *testing.T) {
+	s := newTestServer("localhost:9858", 10, 0, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitorWithConfig("http://localhost:9858/v2", Config{Interval: time.Millisecond * 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	coalesced := m.NewCoalescedListener(0)
+	debounced := m.NewCoalescedListener(time.Millisecond * 300)
+	all := m.NewMinuteListener()
+
+	// nobody reads the coalesced listener while these arrive
+	for i := 0; i < 3; i++ {
+		s.tick()
+		<-all
+	}
+
+	select {
+	case e := <-coalesced:
+		if e.Count != 3 || e.First.Minute != 1 || e.Latest.Minute != 3 {
+			t.Errorf("unexpected coalesced event %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("coalesced event not received")
+	}
+
+	select {
+	case e := <-debounced:
+		if e.Count != 3 || e.First.Minute != 1 || e.Latest.Minute != 3 {
+			t.Errorf("unexpected debounced event %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("debounced event not received")
+	}
+
+	select {
+	case e := <-coalesced:
+		t.Errorf("unexpected event %+v", e)
+	default:
+	}
+}
@@ -0,0 +1,155 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	// CoinbaseFrequency is the number of blocks between two coinbase transactions
+	CoinbaseFrequency = 25
+	// CoinbaseDeclaration is the number of blocks between the admin block that declares
+	// a coinbase descriptor and the coinbase transaction that pays it out
+	CoinbaseDeclaration = 1000
+)
+
+// admin block entry types of coinbase descriptors
+const (
+	coinbaseDescriptor       = 13
+	coinbaseDescriptorCancel = 14
+)
+
+// CoinbaseEvent types
+const (
+	// An admin block declared the outputs of a future coinbase transaction, the rewards
+	// of the authority servers and any grants
+	CoinbaseDescribed = "descriptor"
+	// An admin block cancelled an output of an earlier descriptor before it was paid out
+	CoinbaseCancelled = "cancel"
+	// A coinbase transaction paid out
+	CoinbasePaid = "payout"
+)
+
+// CoinbaseEvent is sent to coinbase listeners when payouts are declared, cancelled,
+// and paid out. Grant recipients and the operators of authority servers can follow
+// their payouts from the declaration to the payment.
+type CoinbaseEvent struct {
+	Type string `json:"type"`
+	// The height of the admin block or the factoid block containing the coinbase transaction
+	DBHeight int64 `json:"dbheight"`
+	// The outputs of descriptors and payouts
+	Outputs []TransactionAddress `json:"outputs,omitempty"`
+	// The height the outputs of a descriptor are paid out at
+	PayoutHeight int64 `json:"payoutheight,omitempty"`
+	// The descriptor and the index of the output a cancellation refers to
+	DescriptorHeight int64 `json:"descriptorheight,omitempty"`
+	DescriptorIndex  int64 `json:"descriptorindex,omitempty"`
+	// The id of the coinbase transaction of payouts
+	TxID      string    `json:"txid,omitempty"`
+	Timestamp time.Time `json:"timestamp,omitempty"`
+}
+
+// Total returns the sum of the outputs in factoshis
+func (e CoinbaseEvent) Total() int64 {
+	var total int64
+	for _, o := range e.Outputs {
+		total += o.Amount
+	}
+	return total
+}
+
+// WatchCoinbase starts fetching the admin block of every new dbheight and the factoid
+// block of every CoinbaseFrequency-th dbheight. Coinbase listeners receive an event for
+// every coinbase descriptor, cancellation, and coinbase transaction.
+// Calling it more than once has no effect.
+func (m *Monitor) WatchCoinbase() {
+	m.watchMtx.Lock()
+	defer m.watchMtx.Unlock()
+	if !m.coinbase {
+		m.coinbase = true
+		m.watchAdminBlocks()
+		m.everyDBHeight(m.fetchCoinbase)
+	}
+}
+
+// NewCoinbaseListener spawns a new listener that receives an event for every coinbase
+// descriptor, cancellation, and payout.
+// Each reader must have its own listener.
+func (m *Monitor) NewCoinbaseListener() <-chan CoinbaseEvent {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan CoinbaseEvent, 25)
+	m.coinbaseListeners = append(m.coinbaseListeners, l)
+	m.register("coinbase", l)
+	return l
+}
+
+// parseCoinbaseEntry turns a coinbase descriptor or cancellation of an admin block into an event
+func parseCoinbaseEntry(dbheight int64, adminType int, raw json.RawMessage) (CoinbaseEvent, error) {
+	e := CoinbaseEvent{DBHeight: dbheight}
+	if adminType == coinbaseDescriptor {
+		var desc struct {
+			Outputs []TransactionAddress `json:"outputs"`
+		}
+		if err := json.Unmarshal(raw, &desc); err != nil {
+			return e, fmt.Errorf("coinbase descriptor: %v", err)
+		}
+		e.Type = CoinbaseDescribed
+		e.Outputs = desc.Outputs
+		e.PayoutHeight = dbheight + CoinbaseDeclaration
+		return e, nil
+	}
+
+	var cancel struct {
+		DescriptorHeight int64 `json:"descriptor_height"`
+		DescriptorIndex  int64 `json:"descriptor_index"`
+	}
+	if err := json.Unmarshal(raw, &cancel); err != nil {
+		return e, fmt.Errorf("coinbase descriptor cancel: %v", err)
+	}
+	e.Type = CoinbaseCancelled
+	e.DescriptorHeight = cancel.DescriptorHeight
+	e.DescriptorIndex = cancel.DescriptorIndex
+	return e, nil
+}
+
+// fetchCoinbase looks for the coinbase transaction in the factoid block of coinbase heights
+func (m *Monitor) fetchCoinbase(dbheight int64) {
+	if dbheight%CoinbaseFrequency != 0 {
+		return
+	}
+	res, err := m.FactoidBlockRequest(dbheight)
+	if err != nil {
+		m.notifyError(err)
+		return
+	}
+	for _, tx := range res.FBlock.Transactions {
+		if len(tx.Inputs) > 0 || len(tx.Outputs) == 0 {
+			continue
+		}
+		m.notifyCoinbase([]CoinbaseEvent{{
+			Type:      CoinbasePaid,
+			DBHeight:  res.FBlock.DBHeight,
+			Outputs:   tx.Outputs,
+			TxID:      tx.TxID,
+			Timestamp: time.Unix(0, tx.MilliTimestamp*int64(time.Millisecond)),
+		}})
+		return
+	}
+}
+
+func (m *Monitor) notifyCoinbase(events []CoinbaseEvent) {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	for _, e := range events {
+		for _, l := range m.coinbaseListeners {
+			select {
+			case l <- e:
+				m.delivered(l)
+			default:
+				m.dropped(l)
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package monitor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMonitor_WatchCoinbase(t *testing.T) {
+	s := newTestServer("localhost:9829", 25, 0, time.Second*10, t)
+	defer s.stop()
+	s.handle("ablock-by-height", func(params json.RawMessage) interface{} {
+		return json.RawMessage(`{"ablock":{"header":{"dbheight":25},"abentries":[
+			{"adminidtype":13,"outputs":[{"amount":640000000,"address":"` + hash("1") + `","useraddress":"FA1"},{"amount":100000000,"address":"` + hash("2") + `","useraddress":"FA2"}]},
+			{"adminidtype":5,"identitychainid":"` + hash("3") + `","dbheight":25},
+			{"adminidtype":14,"descriptor_height":10,"descriptor_index":1}
+		]}}`)
+	})
+	s.handle("fblock-by-height", func(params json.RawMessage) interface{} {
+		return json.RawMessage(`{"fblock":{"dbheight":25,"transactions":[
+			{"txid":"` + hash("tx") + `","millitimestamp":1600000000000,"inputs":[],"outputs":[{"amount":500,"address":"` + hash("1") + `","useraddress":"FA1"}]},
+			{"txid":"` + hash("other") + `","inputs":[{"amount":10,"address":"` + hash("2") + `"}],"outputs":[]}
+		]}}`)
+	})
+
+	m, err := NewMonitorWithConfig("http://localhost:9829/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	m.WatchCoinbase()
+	m.WatchCoinbase()
+	listener := m.NewCoinbaseListener()
+	authorities := m.NewAuthorityListener()
+	s.tick() // dbheight 25
+
+	got := make(map[string]CoinbaseEvent)
+	for i := 0; i < 3; i++ {
+		select {
+		case e := <-listener:
+			got[e.Type] = e
+		case <-time.After(time.Second * 2):
+			t.Fatalf("only received %d events", i)
+		}
+	}
+	if d := got[CoinbaseDescribed]; d.DBHeight != 25 || d.PayoutHeight != 1025 || len(d.Outputs) != 2 || d.Total() != 740000000 || d.Outputs[0].UserAddress != "FA1" {
+		t.Errorf("unexpected descriptor %+v", d)
+	}
+	if c := got[CoinbaseCancelled]; c.DescriptorHeight != 10 || c.DescriptorIndex != 1 {
+		t.Errorf("unexpected cancellation %+v", c)
+	}
+	if p := got[CoinbasePaid]; p.TxID != hash("tx") || p.Total() != 500 || p.Timestamp.Unix() != 1600000000 {
+		t.Errorf("unexpected payout %+v", p)
+	}
+
+	select {
+	case e := <-authorities:
+		t.Errorf("authority event without WatchAuthorities %+v", e)
+	case <-time.After(time.Millisecond * 100):
+	}
+}
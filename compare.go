@@ -0,0 +1,156 @@
+package monitor
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultMaxLag is the number of minutes two nodes of a Comparison may drift apart
+// before they are considered diverged
+var DefaultMaxLag int64 = 10
+
+// DivergenceEvent is sent to divergence listeners when the compared node falls behind
+// or runs ahead of the reference node by more than the comparison's MaxLag, and again
+// when the two nodes converge.
+type DivergenceEvent struct {
+	// The height and minute of the compared node
+	Height int64 `json:"height"`
+	Minute int64 `json:"minute"`
+	// The height and minute of the reference node
+	ReferenceHeight int64 `json:"referenceheight"`
+	ReferenceMinute int64 `json:"referenceminute"`
+	// The number of minutes the compared node is behind the reference node.
+	// Negative if it is ahead.
+	Lag int64 `json:"lag"`
+	// True if the nodes diverged, false if they converged again
+	Diverged bool `json:"diverged"`
+	// When the divergence or convergence was detected
+	Time time.Time `json:"time"`
+}
+
+// Comparison follows two nodes at the same time, usually an operator's own node and a
+// trusted reference like the Open Node, and reports when their positions in the
+// network drift apart. A node that falls out of sync keeps reporting healthy minutes
+// of its own, so this is the only way to notice it from the outside.
+type Comparison struct {
+	local     Source
+	reference Source
+	maxLag    int64
+	close     chan struct{}
+	once      sync.Once
+
+	mtx       sync.Mutex
+	pos       [2]int64 // height*10+minute of local and reference, 0 if unknown
+	heights   [2]int64
+	minutes   [2]int64
+	diverged  bool
+	listeners []chan DivergenceEvent
+}
+
+// NewComparison compares the position of local against reference and reports a
+// divergence when they are more than maxLag minutes apart. A maxLag of ten is one
+// block. If maxLag is zero or less, DefaultMaxLag is used.
+// The comparison does not take ownership of the monitors, Stop only stops the comparison.
+func NewComparison(local, reference Source, maxLag int64) *Comparison {
+	if maxLag <= 0 {
+		maxLag = DefaultMaxLag
+	}
+	c := new(Comparison)
+	c.local = local
+	c.reference = reference
+	c.maxLag = maxLag
+	c.close = make(chan struct{})
+
+	c.heights[0], _, c.minutes[0] = local.GetCurrentMinute()
+	c.heights[1], _, c.minutes[1] = reference.GetCurrentMinute()
+	c.pos[0] = c.heights[0]*10 + c.minutes[0]
+	c.pos[1] = c.heights[1]*10 + c.minutes[1]
+
+	go c.run(local.NewMinuteListener(), reference.NewMinuteListener())
+	return c
+}
+
+// NewDivergenceListener spawns a new listener that receives an event every time the
+// nodes diverge or converge.
+// Each reader must have its own listener.
+func (c *Comparison) NewDivergenceListener() <-chan DivergenceEvent {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	l := make(chan DivergenceEvent, 6)
+	c.listeners = append(c.listeners, l)
+	return l
+}
+
+// Lag returns the number of minutes the compared node is behind the reference node,
+// negative if it is ahead
+func (c *Comparison) Lag() int64 {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.pos[1] - c.pos[0]
+}
+
+// Diverged returns true if the nodes are currently more than MaxLag minutes apart
+func (c *Comparison) Diverged() bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.diverged
+}
+
+// Stop stops the comparison. The monitors keep running.
+func (c *Comparison) Stop() {
+	c.once.Do(func() { close(c.close) })
+}
+
+func (c *Comparison) run(local, reference <-chan Event) {
+	for local != nil || reference != nil {
+		select {
+		case <-c.close:
+			return
+		case e, ok := <-local:
+			if !ok { // drained
+				local = nil
+				continue
+			}
+			c.update(0, e)
+		case e, ok := <-reference:
+			if !ok {
+				reference = nil
+				continue
+			}
+			c.update(1, e)
+		}
+	}
+}
+
+// update records the new position of one of the nodes and notifies listeners if
+// the nodes diverged or converged
+func (c *Comparison) update(i int, e Event) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.heights[i] = e.Height
+	c.minutes[i] = e.Minute
+	c.pos[i] = e.Height*10 + e.Minute
+
+	lag := c.pos[1] - c.pos[0]
+	diverged := lag > c.maxLag || lag < -c.maxLag
+	if diverged == c.diverged {
+		return
+	}
+	c.diverged = diverged
+
+	d := DivergenceEvent{
+		Height:          c.heights[0],
+		Minute:          c.minutes[0],
+		ReferenceHeight: c.heights[1],
+		ReferenceMinute: c.minutes[1],
+		Lag:             lag,
+		Diverged:        diverged,
+		Time:            time.Now(),
+	}
+	for _, l := range c.listeners {
+		select {
+		case l <- d:
+		default:
+		}
+	}
+}
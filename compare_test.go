@@ -0,0 +1,60 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComparison(t *testing.T) {
+	local := newTestServer("localhost:9839", 10, 0, time.Second*10, t)
+	defer local.stop()
+	reference := newTestServer("localhost:9838", 12, 0, time.Second*10, t)
+	defer reference.stop()
+
+	lm, err := NewMonitorWithConfig("http://localhost:9839/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lm.Stop()
+	rm, err := NewMonitorWithConfig("http://localhost:9838/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rm.Stop()
+
+	c := NewComparison(lm, rm, 5)
+	defer c.Stop()
+	listener := c.NewDivergenceListener()
+
+	if lag := c.Lag(); lag != 20 {
+		t.Errorf("unexpected initial lag. got = %d, want = 20", lag)
+	}
+
+	local.tick()
+	select {
+	case d := <-listener:
+		if !d.Diverged || d.Lag != 19 || d.Height != 10 || d.Minute != 1 || d.ReferenceHeight != 12 {
+			t.Errorf("unexpected divergence %+v", d)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("divergence not received")
+	}
+	if !c.Diverged() {
+		t.Error("comparison not diverged")
+	}
+
+	local.mtx.Lock()
+	local.height = 11
+	local.minute = 7
+	local.mtx.Unlock()
+	local.tick() // 11/8, two minutes behind
+
+	select {
+	case d := <-listener:
+		if d.Diverged || d.Lag != 2 {
+			t.Errorf("unexpected convergence %+v", d)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("convergence not received")
+	}
+}
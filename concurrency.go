@@ -0,0 +1,88 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+)
+
+// ConcurrencyLimiter caps the number of API requests that are in flight at the same
+// time. Requests over the cap wait in a queue and are let through in the order they
+// arrived, so a large watchlist can't crowd out the polling of the node.
+// A single limiter can be shared by multiple monitors to cap their combined requests.
+type ConcurrencyLimiter struct {
+	mtx    sync.Mutex
+	limit  int
+	active int
+	queue  []chan struct{}
+}
+
+// NewConcurrencyLimiter creates a limiter that allows up to limit requests in flight.
+// A limit of zero or less does not limit requests.
+func NewConcurrencyLimiter(limit int) *ConcurrencyLimiter {
+	cl := new(ConcurrencyLimiter)
+	cl.limit = limit
+	return cl
+}
+
+// Acquire blocks until a request is allowed or the context is done.
+// Every successful Acquire has to be followed by a Release.
+func (cl *ConcurrencyLimiter) Acquire(ctx context.Context) error {
+	if cl.limit <= 0 {
+		return nil
+	}
+
+	cl.mtx.Lock()
+	if cl.active < cl.limit && len(cl.queue) == 0 {
+		cl.active++
+		cl.mtx.Unlock()
+		return nil
+	}
+	turn := make(chan struct{})
+	cl.queue = append(cl.queue, turn)
+	cl.mtx.Unlock()
+
+	select {
+	case <-turn:
+		return nil
+	case <-ctx.Done():
+		cl.mtx.Lock()
+		defer cl.mtx.Unlock()
+		for i, w := range cl.queue {
+			if w == turn {
+				cl.queue = append(cl.queue[:i], cl.queue[i+1:]...)
+				return ctx.Err()
+			}
+		}
+		// the slot was handed over at the same time, pass it on
+		cl.release()
+		return ctx.Err()
+	}
+}
+
+// Release frees the slot of a finished request
+func (cl *ConcurrencyLimiter) Release() {
+	if cl.limit <= 0 {
+		return
+	}
+	cl.mtx.Lock()
+	defer cl.mtx.Unlock()
+	cl.release()
+}
+
+// release hands the slot to the next request in the queue.
+// Must be called with mtx held.
+func (cl *ConcurrencyLimiter) release() {
+	if len(cl.queue) > 0 {
+		close(cl.queue[0])
+		cl.queue = cl.queue[1:]
+		return
+	}
+	cl.active--
+}
+
+// InFlight returns the number of requests in flight and the number of requests waiting
+func (cl *ConcurrencyLimiter) InFlight() (active, queued int) {
+	cl.mtx.Lock()
+	defer cl.mtx.Unlock()
+	return cl.active, len(cl.queue)
+}
@@ -0,0 +1,64 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestConcurrencyLimiter(t *testing.T) {
+	cl := NewConcurrencyLimiter(2)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := cl.Acquire(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	order := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		go func(i int) {
+			if err := cl.Acquire(ctx); err != nil {
+				t.Error(err)
+				return
+			}
+			order <- i
+		}(i)
+		// let the goroutine enqueue before the next one
+		for {
+			if _, queued := cl.InFlight(); queued == i+1 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	cctx, cancel := context.WithTimeout(ctx, time.Millisecond*10)
+	defer cancel()
+	if err := cl.Acquire(cctx); err != context.DeadlineExceeded {
+		t.Errorf("unexpected error for cancelled acquire. got = %v, want = %v", err, context.DeadlineExceeded)
+	}
+	if active, queued := cl.InFlight(); active != 2 || queued != 3 {
+		t.Errorf("unexpected in flight. got = %d/%d, want = 2/3", active, queued)
+	}
+
+	for i := 0; i < 3; i++ {
+		cl.Release()
+		select {
+		case got := <-order:
+			if got != i {
+				t.Errorf("requests not let through in order. got = %d, want = %d", got, i)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("queued request not let through")
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		cl.Release()
+	}
+	if active, queued := cl.InFlight(); active != 0 || queued != 0 {
+		t.Errorf("unexpected in flight after release. got = %d/%d, want = 0/0", active, queued)
+	}
+}
@@ -0,0 +1,201 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultCourtesyCooldown is used if Config.CourtesyCooldown is zero
+const defaultCourtesyCooldown = time.Minute * 5
+
+// NodeStats is the state of one node of the pool formed by the monitor's url and
+// Config.CourtesyNodes
+type NodeStats struct {
+	URL string `json:"url"`
+	// True if the monitor currently sends its requests to the node
+	Active bool `json:"active"`
+	// True while the node is blacklisted
+	Blacklisted bool `json:"blacklisted"`
+	// The end of the node's most recent cool-down period
+	Until time.Time `json:"until,omitempty"`
+	// The reason the node was most recently blacklisted
+	Reason string `json:"reason,omitempty"`
+	// The number of times the node was blacklisted
+	Count int `json:"count"`
+}
+
+// NodeBlacklistedError is sent to error listeners when a node of the pool is blacklisted
+// because it failed or reported a stale height. The monitor switches to the next healthy
+// node.
+type NodeBlacklistedError struct {
+	URL    string
+	Reason string
+	Until  time.Time
+}
+
+func (e *NodeBlacklistedError) Error() string {
+	return fmt.Sprintf("node %s blacklisted until %s: %s", e.URL, e.Until.Format(time.RFC3339), e.Reason)
+}
+
+// courtesyNode is one node of the pool
+type courtesyNode struct {
+	url    string
+	until  time.Time
+	reason string
+	count  int
+}
+
+// nodePool rotates between the monitor's url and the courtesy nodes
+type nodePool struct {
+	mtx    sync.Mutex
+	nodes  []*courtesyNode
+	active int
+}
+
+func newNodePool(urls []string) *nodePool {
+	p := new(nodePool)
+	for _, url := range urls {
+		p.nodes = append(p.nodes, &courtesyNode{url: url})
+	}
+	return p
+}
+
+// nodeURL returns the url of the node that requests are sent to
+func (m *Monitor) nodeURL() string {
+	if m.pool == nil {
+		return m.url
+	}
+	m.pool.mtx.Lock()
+	defer m.pool.mtx.Unlock()
+	return m.pool.nodes[m.pool.active].url
+}
+
+// blacklist takes the node out of rotation for Config.CourtesyCooldown. If it is the
+// active node, the monitor switches to the next node that isn't blacklisted, or to the
+// node whose cool-down ends first if all of them are.
+// Has no effect without courtesy nodes.
+func (m *Monitor) blacklist(url, reason string) {
+	if m.pool == nil {
+		return
+	}
+	cooldown := m.conf.CourtesyCooldown
+	if cooldown <= 0 {
+		cooldown = defaultCourtesyCooldown
+	}
+	now := m.clock().Now()
+
+	p := m.pool
+	p.mtx.Lock()
+	var blacklisted *courtesyNode
+	for i, n := range p.nodes {
+		if n.url != url {
+			continue
+		}
+		n.until = now.Add(cooldown)
+		n.reason = reason
+		n.count++
+		blacklisted = n
+		if i == p.active {
+			p.rotate(now)
+		}
+		break
+	}
+	p.mtx.Unlock()
+
+	if blacklisted != nil {
+		m.notifyError(&NodeBlacklistedError{URL: url, Reason: reason, Until: blacklisted.until})
+	}
+}
+
+// rotate makes the next healthy node after the active one the active node.
+// Must be called with mtx held.
+func (p *nodePool) rotate(now time.Time) {
+	next := -1
+	for i := 1; i <= len(p.nodes); i++ {
+		j := (p.active + i) % len(p.nodes)
+		if !now.Before(p.nodes[j].until) {
+			p.active = j
+			return
+		}
+		if next < 0 || p.nodes[j].until.Before(p.nodes[next].until) {
+			next = j
+		}
+	}
+	p.active = next
+}
+
+// checkNode blacklists the active node if the poll failed or the node reported a height
+// below the one the monitor already saw. Returns a non-nil error for a stale response,
+// which the monitor has to ignore. A leader height of 0 is a restarting node rather than
+// a stale one: the node is blacklisted so polling moves on to a healthy node, but the
+// response is left to checkRestart.
+func (m *Monitor) checkNode(url string, resp *MinuteResponse, err error) error {
+	if m.pool == nil {
+		return nil
+	}
+	if err != nil {
+		m.blacklist(url, err.Error())
+		return nil
+	}
+	if resp.LeaderHeight == 0 {
+		m.blacklist(url, "restarting")
+		return nil
+	}
+
+	m.heightMtx.Lock()
+	known := m.height
+	m.heightMtx.Unlock()
+	if resp.LeaderHeight >= known {
+		return nil
+	}
+	reason := fmt.Sprintf("stale height %d, the monitor is at %d", resp.LeaderHeight, known)
+	m.blacklist(url, reason)
+	return &InvalidResponseError{Response: resp, Reason: reason}
+}
+
+// startRequest sends the initial request of Start, trying every node of the pool once
+// until one of them responds
+func (m *Monitor) startRequest() (*MinuteResponse, error) {
+	attempts := 1
+	if m.pool != nil {
+		attempts = len(m.pool.nodes)
+	}
+	var err error
+	for i := 0; i < attempts; i++ {
+		url := m.nodeURL()
+		ctx, cancel := context.WithTimeout(context.Background(), m.maxTimeout())
+		var resp *MinuteResponse
+		resp, err = m.FactomdRequest(ctx)
+		cancel()
+		if err == nil {
+			return resp, nil
+		}
+		m.blacklist(url, err.Error())
+	}
+	return nil, err
+}
+
+// Nodes returns the state of every node of the pool, starting with the monitor's url.
+// Returns nil without Config.CourtesyNodes.
+func (m *Monitor) Nodes() []NodeStats {
+	if m.pool == nil {
+		return nil
+	}
+	now := m.clock().Now()
+	m.pool.mtx.Lock()
+	defer m.pool.mtx.Unlock()
+	nodes := make([]NodeStats, len(m.pool.nodes))
+	for i, n := range m.pool.nodes {
+		nodes[i] = NodeStats{
+			URL:         n.url,
+			Active:      i == m.pool.active,
+			Blacklisted: now.Before(n.until),
+			Until:       n.until,
+			Reason:      n.reason,
+			Count:       n.count,
+		}
+	}
+	return nodes
+}
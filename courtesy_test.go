@@ -0,0 +1,112 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonitor_CourtesyNodes(t *testing.T) {
+	primary := newTestServer("localhost:9823", 10, 5, time.Second*10, t)
+	defer primary.stop()
+	courtesy := newTestServer("localhost:9822", 10, 5, time.Second*10, t)
+	defer courtesy.stop()
+
+	m, err := New("http://localhost:9823/v2", Config{Interval: time.Millisecond * 50, CourtesyNodes: []string{"http://localhost:9822/v2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := m.NewErrorListener()
+	if err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	if nodes := m.Stats().Nodes; len(nodes) != 2 || !nodes[0].Active || nodes[0].Blacklisted || nodes[1].Active {
+		t.Errorf("unexpected nodes %+v", nodes)
+	}
+
+	primary.mtx.Lock()
+	primary.height = 8 // stale
+	primary.mtx.Unlock()
+
+	deadline := time.After(time.Second * 2)
+	for blacklisted := false; !blacklisted; {
+		select {
+		case err := <-errs:
+			if b, ok := err.(*NodeBlacklistedError); ok {
+				if b.URL != "http://localhost:9823/v2" {
+					t.Errorf("wrong node blacklisted: %v", b)
+				}
+				blacklisted = true
+			}
+		case <-deadline:
+			t.Fatal("stale node not blacklisted")
+		}
+	}
+
+	nodes := m.Stats().Nodes
+	if !nodes[0].Blacklisted || nodes[0].Active || nodes[0].Count != 1 || !nodes[1].Active {
+		t.Errorf("unexpected nodes after blacklisting %+v", nodes)
+	}
+	if m.nodeURL() != "http://localhost:9822/v2" {
+		t.Errorf("monitor did not rotate to the courtesy node, using %s", m.nodeURL())
+	}
+
+	// an unreachable url is skipped at start
+	m2, err := NewMonitorWithConfig("http://localhost:9821/v2", Config{Interval: time.Millisecond * 50, CourtesyNodes: []string{"http://localhost:9822/v2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m2.Stop()
+	if nodes := m2.Nodes(); !nodes[0].Blacklisted || !nodes[1].Active {
+		t.Errorf("unexpected nodes after start %+v", nodes)
+	}
+}
+
+func TestMonitor_CourtesyNodesRestart(t *testing.T) {
+	primary := newTestServer("localhost:9816", 10, 5, time.Second*10, t)
+	defer primary.stop()
+	courtesy := newTestServer("localhost:9815", 10, 5, time.Second*10, t)
+	defer courtesy.stop()
+
+	m, err := NewMonitorWithConfig("http://localhost:9816/v2", Config{Interval: time.Millisecond * 50, CourtesyNodes: []string{"http://localhost:9815/v2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+	errs := m.NewErrorListener()
+	restarts := m.NewRestartListener()
+
+	primary.mtx.Lock()
+	primary.height = 0 // booting
+	primary.minute = 0
+	primary.mtx.Unlock()
+
+	deadline := time.After(time.Second * 2)
+	for blacklisted := false; !blacklisted; {
+		select {
+		case err := <-errs:
+			if b, ok := err.(*NodeBlacklistedError); ok {
+				if b.URL != "http://localhost:9816/v2" || b.Reason != "restarting" {
+					t.Errorf("unexpected blacklisting: %v", b)
+				}
+				blacklisted = true
+			}
+		case <-deadline:
+			t.Fatal("restarting node not blacklisted")
+		}
+	}
+
+	// the courtesy node takes over and reports a real height again
+	select {
+	case e := <-restarts:
+		if e.Height != 10 || e.ResumedHeight != 10 {
+			t.Errorf("unexpected restart event %+v", e)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("no restart event")
+	}
+	if nodes := m.Nodes(); !nodes[0].Blacklisted || nodes[0].Active || !nodes[1].Active {
+		t.Errorf("restarting node was not rotated out %+v", nodes)
+	}
+}
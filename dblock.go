@@ -0,0 +1,112 @@
+package monitor
+
+import "time"
+
+// DirectoryBlockResponse is a struct formed after the response from the factomd "dblock-by-height" API.
+type DirectoryBlockResponse struct {
+	DBlock struct {
+		Header struct {
+			Version      int    `json:"version"`
+			NetworkID    int64  `json:"networkid"`
+			BodyMR       string `json:"bodymr"`
+			PrevKeyMR    string `json:"prevkeymr"`
+			PrevFullHash string `json:"prevfullhash"`
+			// Minutes since the unix epoch
+			Timestamp  int64 `json:"timestamp"`
+			DBHeight   int64 `json:"dbheight"`
+			BlockCount int64 `json:"blockcount"`
+		} `json:"header"`
+		DBEntries []DirectoryBlockEntry `json:"dbentries"`
+		DBHash    string                `json:"dbhash"`
+		KeyMR     string                `json:"keymr"`
+	} `json:"dblock"`
+	RawData string `json:"rawdata"`
+}
+
+// DirectoryBlockEntry is a single block referenced by a directory block
+type DirectoryBlockEntry struct {
+	ChainID string `json:"chainid"`
+	KeyMR   string `json:"keymr"`
+}
+
+// DirectoryBlockEvent is sent to directory block listeners for every new directory block.
+type DirectoryBlockEvent struct {
+	DBHeight  int64     `json:"dbheight"`
+	KeyMR     string    `json:"keymr"`
+	Timestamp time.Time `json:"timestamp"`
+	// The ids of all chains with a block in the directory block, including
+	// the admin, entry credit, and factoid chains
+	ChainIDs []string `json:"chainids"`
+	// The number of entries and of chains with new entries in the block,
+	// -1 if the entry blocks could not be retrieved
+	Entries int `json:"entries"`
+	Chains  int `json:"chains"`
+}
+
+// DirectoryBlockRequest sends a "dblock-by-height" API request to the configured node.
+func (m *Monitor) DirectoryBlockRequest(dbheight int64) (*DirectoryBlockResponse, error) {
+	res := new(DirectoryBlockResponse)
+	if err := m.call("dblock-by-height", map[string]int64{"height": dbheight}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// WatchDirectoryBlocks starts fetching the directory block of every new dbheight.
+// Directory block listeners receive an event with the block's contents.
+// The entry blocks in it are fetched to count entries for ThroughputStats.
+// Calling it more than once has no effect.
+func (m *Monitor) WatchDirectoryBlocks() {
+	m.watchMtx.Lock()
+	defer m.watchMtx.Unlock()
+	if !m.dblocks {
+		m.dblocks = true
+		m.everyDBHeight(m.fetchDirectoryBlock)
+	}
+}
+
+// NewDirectoryBlockListener spawns a new listener that receives an event for every new directory block.
+// Each reader must have its own listener.
+func (m *Monitor) NewDirectoryBlockListener() <-chan DirectoryBlockEvent {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan DirectoryBlockEvent, 6)
+	m.dblockListeners = append(m.dblockListeners, l)
+	m.register("directory block", l)
+	return l
+}
+
+func (m *Monitor) fetchDirectoryBlock(dbheight int64) {
+	res, err := m.DirectoryBlockRequest(dbheight)
+	if err != nil {
+		m.notifyError(err)
+		return
+	}
+
+	var e DirectoryBlockEvent
+	e.DBHeight = res.DBlock.Header.DBHeight
+	e.KeyMR = res.DBlock.KeyMR
+	e.Timestamp = time.Unix(res.DBlock.Header.Timestamp*60, 0)
+	for _, entry := range res.DBlock.DBEntries {
+		e.ChainIDs = append(e.ChainIDs, entry.ChainID)
+	}
+
+	if count, err := m.countEntries(res); err != nil {
+		m.notifyError(err)
+		e.Entries, e.Chains = -1, -1
+	} else {
+		m.throughput.add(count)
+		e.Entries, e.Chains = count.entries, count.chains
+	}
+
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	for _, l := range m.dblockListeners {
+		select {
+		case l <- e:
+			m.delivered(l)
+		default:
+			m.dropped(l)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package monitor
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMonitor_WatchDirectoryBlocks(t *testing.T) {
+	s := newTestServer("localhost:9869", 10, 0, time.Second*10, t)
+	defer s.stop()
+	s.handle("dblock-by-height", func(params json.RawMessage) interface{} {
+		var p struct {
+			Height int64 `json:"height"`
+		}
+		json.Unmarshal(params, &p)
+		res := new(DirectoryBlockResponse)
+		res.DBlock.Header.DBHeight = p.Height
+		res.DBlock.Header.Timestamp = 26000000
+		res.DBlock.KeyMR = hash("k")
+		res.DBlock.DBEntries = []DirectoryBlockEntry{
+			{ChainID: strings.Repeat("0", 63) + "a", KeyMR: hash("0")},
+			{ChainID: hash("a"), KeyMR: hash("1")},
+			{ChainID: hash("c"), KeyMR: hash("2")},
+		}
+		return res
+	})
+	s.handle("entry-block", func(params json.RawMessage) interface{} {
+		res := new(EntryBlockResponse)
+		res.EntryList = make([]EntryBlockItem, 2)
+		return res
+	})
+
+	m, err := NewMonitorWithConfig("http://localhost:9869/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	m.WatchDirectoryBlocks()
+	listener := m.NewDirectoryBlockListener()
+	s.tick() // dbheight 10
+
+	select {
+	case e := <-listener:
+		if e.DBHeight != 10 || e.KeyMR != hash("k") || len(e.ChainIDs) != 3 || e.ChainIDs[2] != hash("c") {
+			t.Errorf("unexpected event %+v", e)
+		}
+		if !e.Timestamp.Equal(time.Unix(26000000*60, 0)) {
+			t.Errorf("unexpected timestamp %v", e.Timestamp)
+		}
+		if e.Entries != 4 || e.Chains != 2 {
+			t.Errorf("unexpected counts. got = %d entries, %d chains, want = 4, 2", e.Entries, e.Chains)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("directory block not received")
+	}
+
+	// 4 entries in a 10 second block
+	stats := m.ThroughputStats()
+	if stats.Blocks != 1 || stats.Entries != 4 || stats.ChainsPerBlock != 2 || stats.EntriesPerSecond != 0.4 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if got := m.Stats().Throughput; got != stats {
+		t.Errorf("Stats().Throughput = %+v, want %+v", got, stats)
+	}
+}
@@ -0,0 +1,106 @@
+package monitor
+
+import "time"
+
+// DepthEvent is sent when a block has the requested number of saved blocks on top of it
+type DepthEvent struct {
+	// The confirmed block
+	Height int64 `json:"height"`
+	// The number of saved blocks on top of it
+	Depth int64 `json:"depth"`
+	// The node's DBHeight at the time of the event
+	DBHeight int64     `json:"dbheight"`
+	Time     time.Time `json:"time"`
+}
+
+// depthListener receives blocks that reached a depth
+type depthListener struct {
+	ch     chan DepthEvent
+	once   bool // only notified for height
+	height int64
+	depth  int64
+	last   int64 // the last dbheight the listener was notified of
+}
+
+// NotifyAtDepth returns a channel that receives a single event once the block at height
+// has n saved blocks on top of it, ie the node's DBHeight reached height + n, and is
+// closed afterwards. If the block already has that depth, the event is sent right away.
+// Payment and anchoring applications use this as their finality criterion.
+func (m *Monitor) NotifyAtDepth(height, n int64) <-chan DepthEvent {
+	if n < 0 {
+		n = 0
+	}
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := &depthListener{ch: make(chan DepthEvent, 1), once: true, height: height, depth: n}
+
+	m.heightMtx.Lock()
+	dbheight := m.dbheight
+	m.heightMtx.Unlock()
+	if dbheight > 0 && dbheight >= height+n {
+		l.ch <- DepthEvent{Height: height, Depth: n, DBHeight: dbheight, Time: m.clock().Now()}
+		close(l.ch)
+		return l.ch
+	}
+
+	m.depthListeners = append(m.depthListeners, l)
+	m.register("depth", l.ch)
+	return l.ch
+}
+
+// NewDepthListener spawns a new listener that receives an event for every block that
+// reaches a depth of n saved blocks on top of it, in ascending order. The first block is
+// the one that reaches the depth with the first dbheight after the listener was created.
+// Each reader must have its own listener.
+func (m *Monitor) NewDepthListener(n int64) <-chan DepthEvent {
+	if n < 0 {
+		n = 0
+	}
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := &depthListener{ch: make(chan DepthEvent, 25), depth: n}
+	m.heightMtx.Lock()
+	l.last = m.dbheight
+	m.heightMtx.Unlock()
+	m.depthListeners = append(m.depthListeners, l)
+	m.register("depth", l.ch)
+	return l.ch
+}
+
+// notifyDepth must be called with listenerMtx held
+func (m *Monitor) notifyDepth(dbheight int64) {
+	now := m.clock().Now()
+	keep := m.depthListeners[:0]
+	for _, l := range m.depthListeners {
+		if l.once {
+			// the buffer of one always has room for the only event
+			if dbheight < l.height+l.depth {
+				keep = append(keep, l)
+				continue
+			}
+			l.ch <- DepthEvent{Height: l.height, Depth: l.depth, DBHeight: dbheight, Time: now}
+			m.unregister(l.ch)
+			close(l.ch)
+			continue
+		}
+
+		from := l.last + 1
+		if l.last == 0 {
+			from = dbheight
+		}
+		for d := from; d <= dbheight; d++ {
+			if d-l.depth < 0 {
+				continue
+			}
+			select {
+			case l.ch <- DepthEvent{Height: d - l.depth, Depth: l.depth, DBHeight: dbheight, Time: now}:
+				m.delivered(l.ch)
+			default:
+				m.dropped(l.ch)
+			}
+		}
+		l.last = dbheight
+		keep = append(keep, l)
+	}
+	m.depthListeners = keep
+}
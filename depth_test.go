@@ -0,0 +1,57 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonitor_NotifyAtDepth(t *testing.T) {
+	s := newTestServer("localhost:9832", 10, 8, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitorWithConfig("http://localhost:9832/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	select {
+	case e, ok := <-m.NotifyAtDepth(8, 2):
+		if !ok || e.Height != 8 || e.DBHeight != 10 {
+			t.Errorf("unexpected event for a confirmed block %+v", e)
+		}
+	default:
+		t.Fatal("confirmed block not reported right away")
+	}
+
+	once := m.NotifyAtDepth(10, 2)
+	listener := m.NewDepthListener(1)
+
+	for i := 0; i < 13; i++ {
+		s.tick() // until height 12 minute 1, which saves dbheight 12
+		time.Sleep(Interval * 2)
+	}
+
+	for _, want := range []int64{10, 11} {
+		select {
+		case e := <-listener:
+			if e.Height != want || e.Depth != 1 || e.DBHeight != want+1 {
+				t.Errorf("unexpected depth event %+v, want height %d", e, want)
+			}
+		case <-time.After(time.Second * 2):
+			t.Fatalf("height %d did not reach depth 1", want)
+		}
+	}
+
+	select {
+	case e := <-once:
+		if e.Height != 10 || e.Depth != 2 || e.DBHeight != 12 {
+			t.Errorf("unexpected depth event %+v", e)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("height 10 did not reach depth 2")
+	}
+	if _, ok := <-once; ok {
+		t.Error("one-shot listener not closed")
+	}
+}
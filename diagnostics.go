@@ -0,0 +1,161 @@
+package monitor
+
+import (
+	"sort"
+	"time"
+)
+
+// DiagnosticsInterval specifies the time between "diagnostics" API requests.
+// Unlike other watchers, diagnostics are polled on a timer so that trouble is
+// reported even while the network is stalled.
+var DiagnosticsInterval time.Duration = time.Second * 10
+
+// DiagnosticsResponse is a struct formed after the response from the factomd "diagnostics" API.
+type DiagnosticsResponse struct {
+	Name                   string  `json:"name"`
+	ID                     string  `json:"id"`
+	PublicKey              string  `json:"publickey"`
+	Role                   string  `json:"role"`
+	LeaderHeight           int64   `json:"leaderheight"`
+	CurrentMinute          int64   `json:"currentminute"`
+	CurrentMinuteDuration  float64 `json:"currentminuteduration"`
+	PreviousMinuteDuration float64 `json:"previousminuteduration"`
+	BalanceHash            string  `json:"balancehash"`
+	TempBalanceHash        string  `json:"tempbalancehash"`
+	LastBlockFromDBState   bool    `json:"lastblockfromdbstate"`
+	Syncing                struct {
+		Status   string   `json:"status"`
+		Received int64    `json:"received"`
+		Expected int64    `json:"expected"`
+		Missing  []string `json:"missing"`
+	} `json:"syncing"`
+	AuthSet struct {
+		Leaders []struct {
+			ID         string `json:"id"`
+			VM         int    `json:"vm"`
+			ListHeight int    `json:"listheight"`
+			ListLength int    `json:"listlength"`
+			NextNil    int    `json:"nextnil"`
+		} `json:"leaders"`
+		Audits []struct {
+			ID     string `json:"id"`
+			Online bool   `json:"online"`
+		} `json:"audits"`
+	} `json:"authset"`
+	Elections struct {
+		InProgress bool   `json:"inprogress"`
+		VMIndex    int    `json:"vmindex"`
+		FedIndex   int    `json:"fedindex"`
+		FedID      string `json:"fedid"`
+		Round      int    `json:"round"`
+	} `json:"elections"`
+}
+
+// NetworkEvent is sent to network listeners when an election starts or ends, or when the
+// set of offline audit servers changes.
+type NetworkEvent struct {
+	// Election is true while an election is in progress
+	Election bool `json:"election"`
+	// The federated server being replaced and the election round, if there is an election
+	FedID string `json:"fedid,omitempty"`
+	Round int    `json:"round,omitempty"`
+	// The ids of audit servers that are offline
+	OfflineAudits []string  `json:"offlineaudits"`
+	Time          time.Time `json:"time"`
+}
+
+// Trouble is true if there is an election or an audit server is offline
+func (e NetworkEvent) Trouble() bool {
+	return e.Election || len(e.OfflineAudits) > 0
+}
+
+// DiagnosticsRequest sends a "diagnostics" API request to the configured node.
+func (m *Monitor) DiagnosticsRequest() (*DiagnosticsResponse, error) {
+	res := new(DiagnosticsResponse)
+	if err := m.call("diagnostics", nil, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// WatchDiagnostics starts polling the node's diagnostics every DiagnosticsInterval.
+// Network listeners receive an event when an election starts or ends and when audit
+// servers go offline or come back.
+// Calling it more than once has no effect.
+func (m *Monitor) WatchDiagnostics() {
+	m.watchMtx.Lock()
+	defer m.watchMtx.Unlock()
+	if m.network == nil {
+		m.network = new(NetworkEvent)
+		interval := m.conf.DiagnosticsInterval
+		if interval <= 0 {
+			interval = DiagnosticsInterval
+		}
+		m.every(interval, m.checkDiagnostics)
+	}
+}
+
+// NewNetworkListener spawns a new listener that receives an event every time the
+// election or authority set status changes.
+// Each reader must have its own listener.
+func (m *Monitor) NewNetworkListener() <-chan NetworkEvent {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan NetworkEvent, 6)
+	m.networkListeners = append(m.networkListeners, l)
+	m.register("network", l)
+	return l
+}
+
+func (m *Monitor) checkDiagnostics() {
+	res, err := m.DiagnosticsRequest()
+	if err != nil {
+		m.notifyError(err)
+		return
+	}
+
+	var e NetworkEvent
+	e.Time = m.clock().Now()
+	if res.Elections.InProgress {
+		e.Election = true
+		e.FedID = res.Elections.FedID
+		e.Round = res.Elections.Round
+	}
+	for _, a := range res.AuthSet.Audits {
+		if !a.Online {
+			e.OfflineAudits = append(e.OfflineAudits, a.ID)
+		}
+	}
+	sort.Strings(e.OfflineAudits)
+
+	m.watchMtx.Lock()
+	changed := !sameNetworkStatus(*m.network, e)
+	*m.network = e
+	m.watchMtx.Unlock()
+	if !changed {
+		return
+	}
+
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	for _, l := range m.networkListeners {
+		select {
+		case l <- e:
+			m.delivered(l)
+		default:
+			m.dropped(l)
+		}
+	}
+}
+
+func sameNetworkStatus(a, b NetworkEvent) bool {
+	if a.Election != b.Election || a.FedID != b.FedID || a.Round != b.Round || len(a.OfflineAudits) != len(b.OfflineAudits) {
+		return false
+	}
+	for i := range a.OfflineAudits {
+		if a.OfflineAudits[i] != b.OfflineAudits[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package monitor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMonitor_WatchDiagnostics(t *testing.T) {
+	s := newTestServer("localhost:9863", 10, 0, time.Second*10, t)
+	defer s.stop()
+	status := `{"authset":{"audits":[{"id":"a1","online":true}]},"elections":{"inprogress":false}}`
+	s.handle("diagnostics", func(json.RawMessage) interface{} {
+		return json.RawMessage(status)
+	})
+
+	m, err := NewMonitorWithConfig("http://localhost:9863/v2", Config{DiagnosticsInterval: time.Millisecond * 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	listener := m.NewNetworkListener()
+	m.WatchDiagnostics()
+
+	next := func() NetworkEvent {
+		select {
+		case e := <-listener:
+			return e
+		case <-time.After(time.Second):
+			t.Fatal("network event not received")
+		}
+		return NetworkEvent{}
+	}
+
+	time.Sleep(time.Millisecond * 200)
+	select {
+	case e := <-listener:
+		t.Errorf("unexpected event for healthy network %+v", e)
+	default:
+	}
+
+	s.mtx.Lock()
+	status = `{"authset":{"audits":[{"id":"a1","online":false}]},"elections":{"inprogress":true,"fedid":"f1","round":2}}`
+	s.mtx.Unlock()
+
+	e := next()
+	if !e.Election || e.FedID != "f1" || e.Round != 2 || len(e.OfflineAudits) != 1 || e.OfflineAudits[0] != "a1" {
+		t.Errorf("unexpected event %+v", e)
+	}
+
+	select {
+	case e := <-listener:
+		t.Errorf("unexpected event without change %+v", e)
+	case <-time.After(time.Millisecond * 200):
+	}
+
+	s.mtx.Lock()
+	status = `{"authset":{"audits":[{"id":"a1","online":true}]},"elections":{"inprogress":false}}`
+	s.mtx.Unlock()
+	if e := next(); e.Trouble() {
+		t.Errorf("unexpected trouble after recovery %+v", e)
+	}
+}
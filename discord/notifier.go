@@ -0,0 +1,265 @@
+// Package discord posts monitor alerts and minute timing anomalies to a Discord channel
+// via a webhook, as embeds showing the height and minute.
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+	"github.com/WhoSoup/factom-monitor/notify"
+)
+
+// Embed colors
+const (
+	ColorCritical = 0xE74C3C
+	ColorWarning  = 0xE67E22
+	ColorInfo     = 0x3498DB
+	ColorResolved = 0x2ECC71
+	ColorTiming   = 0xF1C40F
+)
+
+// Source is the subset of a monitor used by the notifier
+type Source interface {
+	NewAlertListener() <-chan monitor.Alert
+	NewMinuteTimingListener() <-chan monitor.MinuteTiming
+}
+
+// Config contains the settings of a notifier
+type Config struct {
+	// The webhook url
+	WebhookURL string
+	// Optional overrides of the webhook's name and avatar
+	Username  string
+	AvatarURL string
+	// A minute is reported as a timing anomaly if it took at least TimingThreshold
+	// times as long as expected, or at most 1/TimingThreshold. Defaults to 1.5.
+	// Negative values disable timing anomalies.
+	TimingThreshold float64
+	// Templates replace the DefaultTemplates of the embed descriptions by kind
+	Templates map[string]string
+	// Leader, if set, limits notifications to the times it is the leader, so only one of
+	// several monitor instances sends them. See monitor.Elector.
+	Leader monitor.Leader
+	// The client used for requests, defaults to http.DefaultClient
+	Client *http.Client
+}
+
+// DefaultTemplates are the descriptions of the embeds of firing and resolved alerts
+// and timing anomalies. See package notify for the available fields and functions.
+var DefaultTemplates = map[string]string{
+	notify.KindFiring:   `{{.Alert.Message}}`,
+	notify.KindResolved: `{{.Alert.Message}}`,
+	notify.KindTiming:   `Minute {{.Timing.Minute}} of height {{.Timing.Height}} took {{duration .Timing.Duration}}, expected {{.Timing.Expected}}`,
+}
+
+// Timeout specifies the maximum time a single webhook request can take
+var Timeout = time.Second * 10
+
+// Embed is a Discord message embed
+type Embed struct {
+	Title       string  `json:"title,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Color       int     `json:"color,omitempty"`
+	Fields      []Field `json:"fields,omitempty"`
+	Timestamp   string  `json:"timestamp,omitempty"`
+}
+
+// Field is a name and value shown in an embed
+type Field struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+// Notifier sends alerts and timing anomalies to Discord
+type Notifier struct {
+	conf      Config
+	templates notify.Templates
+
+	errors chan error
+
+	close  chan interface{}
+	done   chan interface{}
+	closer sync.Once
+}
+
+// NewNotifier creates a new notifier that begins sending notifications from the source immediately.
+// Returns an error if a template can't be parsed.
+// Starts a goroutine that can be stopped via notifier.Stop().
+func NewNotifier(src Source, conf Config) (*Notifier, error) {
+	if conf.TimingThreshold == 0 {
+		conf.TimingThreshold = 1.5
+	}
+	if conf.Client == nil {
+		conf.Client = http.DefaultClient
+	}
+	n := new(Notifier)
+	n.conf = conf
+	var err error
+	if n.templates, err = notify.ParseTemplates(DefaultTemplates, conf.Templates); err != nil {
+		return nil, fmt.Errorf("discord: %v", err)
+	}
+	n.errors = make(chan error, 6)
+	n.close = make(chan interface{})
+	n.done = make(chan interface{})
+	go n.run(src.NewAlertListener(), src.NewMinuteTimingListener())
+	return n, nil
+}
+
+// Errors returns a channel that receives errors from failed messages.
+// Errors are dropped if the channel is not read.
+func (n *Notifier) Errors() <-chan error {
+	return n.errors
+}
+
+func (n *Notifier) run(alerts <-chan monitor.Alert, timings <-chan monitor.MinuteTiming) {
+	defer close(n.done)
+	for {
+		select {
+		case <-n.close:
+			return
+		case a, ok := <-alerts:
+			if !ok { // drained
+				alerts = nil
+				continue
+			}
+			n.send(AlertEmbed(a), notify.AlertData(a))
+		case t, ok := <-timings:
+			if !ok {
+				timings = nil
+				continue
+			}
+			if n.anomalous(t) {
+				n.send(TimingEmbed(t), notify.Data{Kind: notify.KindTiming, Height: t.Height, Minute: t.Minute, Timing: t, Time: time.Now()})
+			}
+		}
+	}
+}
+
+// send posts the embed with the description rendered from the data's template
+func (n *Notifier) send(e Embed, d notify.Data) {
+	if n.conf.Leader != nil && !n.conf.Leader.IsLeader() {
+		return
+	}
+	desc, err := n.templates.Render(d)
+	if err != nil {
+		n.notifyError(err)
+		return
+	}
+	e.Description = desc
+	n.notifyError(n.Post(e))
+}
+
+// anomalous returns true if the minute's duration is outside of the threshold
+func (n *Notifier) anomalous(t monitor.MinuteTiming) bool {
+	r := t.Ratio()
+	if n.conf.TimingThreshold < 0 || r == 0 {
+		return false
+	}
+	return r >= n.conf.TimingThreshold || r <= 1/n.conf.TimingThreshold
+}
+
+// AlertEmbed creates the embed of an alert
+func AlertEmbed(a monitor.Alert) Embed {
+	e := Embed{
+		Title:       fmt.Sprintf("%s: %s", a.Status, a.Rule),
+		Description: a.Message,
+		Timestamp:   a.Time.UTC().Format(time.RFC3339),
+		Fields: []Field{
+			{Name: "Height", Value: strconv.FormatInt(a.Height, 10), Inline: true},
+			{Name: "DBHeight", Value: strconv.FormatInt(a.DBHeight, 10), Inline: true},
+			{Name: "Minute", Value: strconv.FormatInt(a.Minute, 10), Inline: true},
+		},
+	}
+	if a.Network != "" {
+		e.Fields = append(e.Fields, Field{Name: "Network", Value: a.Network, Inline: true})
+	}
+
+	switch {
+	case a.Status == monitor.AlertResolved:
+		e.Color = ColorResolved
+		e.Fields = append(e.Fields, Field{Name: "Duration", Value: a.Duration().Round(time.Second).String(), Inline: true})
+	case a.Severity == monitor.SeverityCritical:
+		e.Color = ColorCritical
+	case a.Severity == monitor.SeverityWarning:
+		e.Color = ColorWarning
+	default:
+		e.Color = ColorInfo
+	}
+	return e
+}
+
+// TimingEmbed creates the embed of a minute timing anomaly
+func TimingEmbed(t monitor.MinuteTiming) Embed {
+	return Embed{
+		Title:       "Minute timing anomaly",
+		Description: fmt.Sprintf("Minute %d of height %d took %s, expected %s", t.Minute, t.Height, t.Duration.Round(time.Second), t.Expected),
+		Color:       ColorTiming,
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		Fields: []Field{
+			{Name: "Height", Value: strconv.FormatInt(t.Height, 10), Inline: true},
+			{Name: "Minute", Value: strconv.FormatInt(t.Minute, 10), Inline: true},
+			{Name: "Ratio", Value: strconv.FormatFloat(t.Ratio(), 'f', 2, 64), Inline: true},
+		},
+	}
+}
+
+// Post sends a message with the embeds
+func (n *Notifier) Post(embeds ...Embed) error {
+	msg := struct {
+		Username  string  `json:"username,omitempty"`
+		AvatarURL string  `json:"avatar_url,omitempty"`
+		Embeds    []Embed `json:"embeds"`
+	}{n.conf.Username, n.conf.AvatarURL, embeds}
+	js, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodPost, n.conf.WebhookURL, bytes.NewReader(js))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.conf.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("discord: %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+func (n *Notifier) notifyError(err error) {
+	if err == nil {
+		return
+	}
+	select {
+	case n.errors <- err:
+	default:
+	}
+}
+
+// Stop halts the notifier
+func (n *Notifier) Stop() {
+	n.closer.Do(func() {
+		close(n.close)
+		<-n.done
+	})
+}
@@ -0,0 +1,83 @@
+package discord
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+	"github.com/WhoSoup/factom-monitor/monitortest"
+)
+
+type message struct {
+	Username string  `json:"username"`
+	Embeds   []Embed `json:"embeds"`
+}
+
+func TestNotifier(t *testing.T) {
+	var mtx sync.Mutex
+	var msgs []message
+	hook := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var msg message
+		json.NewDecoder(r.Body).Decode(&msg)
+		mtx.Lock()
+		msgs = append(msgs, msg)
+		mtx.Unlock()
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer hook.Close()
+
+	// the alerts and timings arrive on separate listeners, so wait for
+	// each message to keep them in order
+	wait := func(n int) {
+		for i := 0; i < 100; i++ {
+			mtx.Lock()
+			l := len(msgs)
+			mtx.Unlock()
+			if l >= n {
+				return
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+		t.Fatalf("timed out waiting for message %d", n)
+	}
+
+	fake := monitortest.NewFakeMonitor(10, 3)
+	n, err := NewNotifier(fake, Config{
+		WebhookURL: hook.URL,
+		Username:   "factom-monitor",
+		Templates:  map[string]string{"resolved": "{{.Alert.Rule}} is fine again"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer n.Stop()
+
+	now := time.Now()
+	fake.SendAlert(monitor.Alert{Rule: "no-new-block", Severity: monitor.SeverityCritical, Status: monitor.AlertFiring, Message: "stalled", Height: 10, Time: now})
+	wait(1)
+	fake.SendMinuteTiming(monitor.MinuteTiming{Height: 10, Minute: 3, Duration: time.Minute, Expected: time.Minute})
+	fake.SendMinuteTiming(monitor.MinuteTiming{Height: 10, Minute: 4, Duration: time.Minute * 2, Expected: time.Minute})
+	wait(2)
+	fake.SendAlert(monitor.Alert{Rule: "no-new-block", Status: monitor.AlertResolved, Since: now, Time: now.Add(time.Minute)})
+	wait(3)
+	n.Stop()
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	if len(msgs) != 3 {
+		t.Fatalf("unexpected messages: %+v", msgs)
+	}
+	if e := msgs[0].Embeds[0]; msgs[0].Username != "factom-monitor" || e.Title != "firing: no-new-block" || e.Color != ColorCritical || e.Fields[0].Value != "10" {
+		t.Errorf("unexpected alert embed %+v", e)
+	}
+	if e := msgs[1].Embeds[0]; e.Description != "Minute 4 of height 10 took 2m0s, expected 1m0s" || e.Fields[2].Value != "2.00" {
+		t.Errorf("unexpected timing embed %+v", e)
+	}
+	if e := msgs[2].Embeds[0]; e.Color != ColorResolved || e.Description != "no-new-block is fine again" || e.Fields[len(e.Fields)-1].Value != "1m0s" {
+		t.Errorf("unexpected resolved embed %+v", e)
+	}
+}
@@ -0,0 +1,168 @@
+package monitor
+
+import (
+	"errors"
+	"math"
+	"path/filepath"
+	"sync"
+)
+
+// ErrNoSubscriptionStore is returned when creating a durable listener on a monitor
+// without Config.Subscriptions
+var ErrNoSubscriptionStore = errors.New("monitor has no subscription store")
+
+// SubscriptionStore persists the acknowledged cursors of durable listeners by name.
+type SubscriptionStore interface {
+	// Load returns the cursor saved under the name. If nothing has been saved yet,
+	// it returns nil and no error.
+	Load(name string) (*Cursor, error)
+	// Save replaces the cursor saved under the name
+	Save(name string, c Cursor) error
+}
+
+// DirStore is a SubscriptionStore that keeps each cursor in a JSON file named after
+// the listener.
+type DirStore struct {
+	dir string
+}
+
+var _ SubscriptionStore = (*DirStore)(nil)
+
+// NewDirStore creates a store that saves cursors to files in the directory.
+// The directory must exist.
+func NewDirStore(dir string) *DirStore {
+	ds := new(DirStore)
+	ds.dir = dir
+	return ds
+}
+
+// Load reads the cursor from the listener's file
+func (ds *DirStore) Load(name string) (*Cursor, error) {
+	return NewFileStore(ds.file(name)).Load()
+}
+
+// Save writes the cursor to the listener's file, see FileStore.Save
+func (ds *DirStore) Save(name string, c Cursor) error {
+	return NewFileStore(ds.file(name)).Save(c)
+}
+
+func (ds *DirStore) file(name string) string {
+	return filepath.Join(ds.dir, filepath.Base(name)+".json")
+}
+
+// DurableListener delivers minute events at least once, even across restarts of the
+// process. Events are acknowledged once they have been processed. A new durable
+// listener with the same name resumes after the last acknowledged event, re-delivering
+// every event since from the journal.
+type DurableListener struct {
+	// C receives the events. Unlike other listeners, events are never dropped: if the
+	// reader falls behind, missed events are read back from the journal.
+	C <-chan Event
+
+	m     *Monitor
+	name  string
+	mtx   sync.Mutex
+	acked *Cursor
+}
+
+// NewDurableListener creates a durable listener registered under the name.
+// Requires Config.Journal and Config.Subscriptions. Only one listener per name should be
+// active at a time.
+func (m *Monitor) NewDurableListener(name string) (*DurableListener, error) {
+	if m.conf.Journal == nil {
+		return nil, ErrNoJournal
+	}
+	if m.conf.Subscriptions == nil {
+		return nil, ErrNoSubscriptionStore
+	}
+
+	acked, err := m.conf.Subscriptions.Load(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// subscribe before reading the journal, so no event falls in between
+	live := m.NewMinuteListenerNamed(name)
+	out := make(chan Event)
+
+	d := &DurableListener{C: out, m: m, name: name, acked: acked}
+	m.watchMtx.Lock()
+	if m.durables == nil {
+		m.durables = make(map[string]*DurableListener)
+	}
+	m.durables[name] = d
+	m.watchMtx.Unlock()
+	go d.run(live, out)
+	return d, nil
+}
+
+// Name returns the name the listener is registered under
+func (d *DurableListener) Name() string {
+	return d.name
+}
+
+// Ack marks the event and all events before it as processed. Acknowledging an event
+// older than the last acknowledged one has no effect.
+func (d *DurableListener) Ack(e Event) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if d.acked != nil && !after(e.Height, e.Minute, d.acked.Height, d.acked.Minute) {
+		return nil
+	}
+	c := Cursor{Height: e.Height, DBHeight: e.DBHeight, Minute: e.Minute, Time: d.m.clock().Now()}
+	if err := d.m.conf.Subscriptions.Save(d.name, c); err != nil {
+		return err
+	}
+	d.acked = &c
+	return nil
+}
+
+// run delivers the journaled events after the acknowledged cursor, followed by live
+// events. Gaps in the live events are filled from the journal.
+func (d *DurableListener) run(live <-chan Event, out chan<- Event) {
+	defer close(out)
+
+	// the most recently delivered minute
+	var height, minute int64 = -1, -1
+	deliver := func(e Event) {
+		out <- e
+		height, minute = e.Height, e.Minute
+	}
+	fill := func(from, to Event) {
+		events, err := d.m.conf.Journal.Read(from.Height, to.Height)
+		if err != nil {
+			d.m.notifyError(err)
+			return
+		}
+		for _, e := range events {
+			if after(e.Height, e.Minute, height, minute) && after(to.Height, to.Minute, e.Height, e.Minute) {
+				deliver(e)
+			}
+		}
+	}
+
+	d.mtx.Lock()
+	acked := d.acked
+	d.mtx.Unlock()
+	if acked != nil {
+		height, minute = acked.Height, acked.Minute
+		fill(Event{Height: acked.Height}, Event{Height: math.MaxInt64})
+	}
+
+	var sequence uint64
+	for e := range live {
+		if e.Partial || !after(e.Height, e.Minute, height, minute) {
+			continue // already delivered from the journal
+		}
+		if sequence > 0 && e.Sequence > sequence+1 {
+			fill(Event{Height: height}, e)
+		}
+		sequence = e.Sequence
+		deliver(e)
+	}
+}
+
+// after returns true if height h1 minute m1 comes after height h2 minute m2
+func after(h1, m1, h2, m2 int64) bool {
+	return h1 > h2 || (h1 == h2 && m1 > m2)
+}
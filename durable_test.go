@@ -0,0 +1,84 @@
+package monitor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMonitor_DurableListener(t *testing.T) {
+	dir, err := ioutil.TempDir("", "factom-monitor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := newTestServer("localhost:9842", 10, 1, time.Second*10, t)
+	defer s.stop()
+
+	conf := Config{
+		Interval:      time.Millisecond * 100,
+		Journal:       NewFileJournal(filepath.Join(dir, "journal.ndjson")),
+		Subscriptions: NewDirStore(dir),
+	}
+
+	if _, err := new(Monitor).NewDurableListener("alerts"); err != ErrNoJournal {
+		t.Errorf("unexpected error without journal. got = %v, want = %v", err, ErrNoJournal)
+	}
+
+	m, err := NewMonitorWithConfig("http://localhost:9842/v2", conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := m.NewDurableListener("alerts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next := func(d *DurableListener, minute int64) Event {
+		select {
+		case e := <-d.C:
+			if e.Height != 10 || e.Minute != minute {
+				t.Errorf("unexpected event. got = %+v, want minute %d", e, minute)
+			}
+			return e
+		case <-time.After(time.Second * 2):
+			t.Fatalf("minute %d not received", minute)
+		}
+		return Event{}
+	}
+
+	for min := int64(2); min <= 4; min++ {
+		s.tick()
+		e := next(d, min)
+		if min < 4 {
+			if err := d.Ack(e); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := d.Ack(Event{Height: 10, Minute: 1}); err != nil {
+		t.Fatal(err)
+	}
+	m.Stop()
+
+	if c, err := conf.Subscriptions.Load("alerts"); err != nil || c.Height != 10 || c.Minute != 3 {
+		t.Fatalf("unexpected saved cursor. got = (%+v, %v)", c, err)
+	}
+
+	// resumes after the last acked event, re-delivering minute 4
+	m, err = NewMonitorWithConfig("http://localhost:9842/v2", conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+	d, err = m.NewDurableListener("alerts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	next(d, 4)
+	s.tick()
+	next(d, 5)
+}
@@ -0,0 +1,165 @@
+package monitor
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ECEvent types
+const (
+	// A new chain was paid for. The chain id is only known as its hash.
+	ECCommitChain = "commitchain"
+	// A new entry was paid for
+	ECCommitEntry = "commitentry"
+	// Entry credits were purchased with factoids
+	ECPurchase = "purchase"
+)
+
+// ECBlockResponse is a struct formed after the response from the factomd "ecblock-by-height" API.
+// The entries are kept raw, their fields depend on the type of entry.
+type ECBlockResponse struct {
+	ECBlock struct {
+		Header struct {
+			BodyHash       string `json:"bodyhash"`
+			PrevHeaderHash string `json:"prevheaderhash"`
+			PrevFullHash   string `json:"prevfullhash"`
+			DBHeight       int64  `json:"dbheight"`
+			ObjectCount    int64  `json:"objectcount"`
+			BodySize       int64  `json:"bodysize"`
+		} `json:"header"`
+		Body struct {
+			Entries []json.RawMessage `json:"entries"`
+		} `json:"body"`
+	} `json:"ecblock"`
+	RawData string `json:"rawdata"`
+}
+
+// ECEvent is sent to entry credit listeners for every commit and purchase in a new
+// entry credit block.
+type ECEvent struct {
+	DBHeight int64  `json:"dbheight"`
+	Type     string `json:"type"`
+	// The public key of the entry credit address that paid for a commit or received a purchase
+	ECPubKey string `json:"ecpubkey"`
+	// Credits spent by a commit or purchased
+	Credits int64 `json:"credits"`
+	// Set for commits
+	EntryHash string    `json:"entryhash,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	// Set for chain commits
+	ChainIDHash string `json:"chainidhash,omitempty"`
+	// Set for purchases, the factoid transaction that bought the credits
+	TxID string `json:"txid,omitempty"`
+}
+
+// ecEntry contains the union of the fields of all entry credit block entries
+type ecEntry struct {
+	MilliTime   string `json:"millitime"`
+	ChainIDHash string `json:"chainidhash"`
+	EntryHash   string `json:"entryhash"`
+	Credits     int64  `json:"credits"`
+	ECPubKey    string `json:"ecpubkey"`
+	TxID        string `json:"txid"`
+	NumEC       *int64 `json:"numec"`
+}
+
+// ECBlockRequest sends an "ecblock-by-height" API request to the configured node.
+func (m *Monitor) ECBlockRequest(dbheight int64) (*ECBlockResponse, error) {
+	res := new(ECBlockResponse)
+	if err := m.call("ecblock-by-height", map[string]int64{"height": dbheight}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// WatchECBlocks starts fetching the entry credit block of every new dbheight.
+// Entry credit listeners receive an event for every commit and purchase in the block.
+// Calling it more than once has no effect.
+func (m *Monitor) WatchECBlocks() {
+	m.watchMtx.Lock()
+	defer m.watchMtx.Unlock()
+	if !m.ecblocks {
+		m.ecblocks = true
+		m.everyDBHeight(m.fetchECBlock)
+	}
+}
+
+// NewECListener spawns a new listener that receives an event for every commit and purchase
+// in a new entry credit block.
+// Each reader must have its own listener.
+func (m *Monitor) NewECListener() <-chan ECEvent {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan ECEvent, 100)
+	m.ecListeners = append(m.ecListeners, l)
+	m.register("ec", l)
+	return l
+}
+
+func (m *Monitor) fetchECBlock(dbheight int64) {
+	res, err := m.ECBlockRequest(dbheight)
+	if err != nil {
+		m.notifyError(err)
+		return
+	}
+
+	var events []ECEvent
+	for _, raw := range res.ECBlock.Body.Entries {
+		var entry ecEntry
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			m.notifyError(fmt.Errorf("entry credit block %d: %v", dbheight, err))
+			continue
+		}
+
+		e := ECEvent{DBHeight: res.ECBlock.Header.DBHeight, ECPubKey: entry.ECPubKey}
+		switch {
+		case entry.NumEC != nil:
+			e.Type = ECPurchase
+			e.Credits = *entry.NumEC
+			e.TxID = entry.TxID
+		case entry.ChainIDHash != "":
+			e.Type = ECCommitChain
+			e.Credits = entry.Credits
+			e.EntryHash = entry.EntryHash
+			e.ChainIDHash = entry.ChainIDHash
+			e.Timestamp = milliTime(entry.MilliTime)
+		case entry.EntryHash != "":
+			e.Type = ECCommitEntry
+			e.Credits = entry.Credits
+			e.EntryHash = entry.EntryHash
+			e.Timestamp = milliTime(entry.MilliTime)
+		default: // server index and minute markers
+			continue
+		}
+		events = append(events, e)
+	}
+
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	for _, e := range events {
+		for _, l := range m.ecListeners {
+			select {
+			case l <- e:
+				m.delivered(l)
+			default:
+				m.dropped(l)
+			}
+		}
+	}
+}
+
+// milliTime decodes the hex encoded 6 byte millisecond timestamp of commits.
+// Returns the zero time if it is malformed.
+func milliTime(h string) time.Time {
+	b, err := hex.DecodeString(h)
+	if err != nil || len(b) != 6 {
+		return time.Time{}
+	}
+	var ms int64
+	for _, c := range b {
+		ms = ms<<8 | int64(c)
+	}
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
@@ -0,0 +1,55 @@
+package monitor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMilliTime(t *testing.T) {
+	if got := milliTime("015cd3e0b2b8"); !got.Equal(time.Unix(0, 0x015cd3e0b2b8*int64(time.Millisecond))) {
+		t.Errorf("unexpected time %v", got)
+	}
+	if got := milliTime("zz"); !got.IsZero() {
+		t.Errorf("malformed time not zero: %v", got)
+	}
+}
+
+func TestMonitor_WatchECBlocks(t *testing.T) {
+	s := newTestServer("localhost:9866", 10, 0, time.Second*10, t)
+	defer s.stop()
+	s.handle("ecblock-by-height", func(params json.RawMessage) interface{} {
+		return json.RawMessage(`{"ecblock":{"header":{"dbheight":10},"body":{"entries":[
+			{"ServerIndexNumber":0},
+			{"version":0,"millitime":"015cd3e0b2b8","chainidhash":"` + hash("c") + `","entryhash":"` + hash("1") + `","credits":11,"ecpubkey":"` + hash("e") + `"},
+			{"Number":1},
+			{"version":0,"millitime":"015cd3e0b2b8","entryhash":"` + hash("2") + `","credits":1,"ecpubkey":"` + hash("e") + `"},
+			{"ecpubkey":"` + hash("e") + `","txid":"` + hash("t") + `","index":0,"numec":500}
+		]}}}`)
+	})
+
+	m, err := NewMonitorWithConfig("http://localhost:9866/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	m.WatchECBlocks()
+	listener := m.NewECListener()
+	s.tick() // dbheight 10
+
+	for _, want := range []ECEvent{
+		{Type: ECCommitChain, EntryHash: hash("1"), Credits: 11},
+		{Type: ECCommitEntry, EntryHash: hash("2"), Credits: 1},
+		{Type: ECPurchase, TxID: hash("t"), Credits: 500},
+	} {
+		select {
+		case e := <-listener:
+			if e.DBHeight != 10 || e.Type != want.Type || e.EntryHash != want.EntryHash || e.TxID != want.TxID || e.Credits != want.Credits || e.ECPubKey != hash("e") {
+				t.Errorf("unexpected event. got = %+v, want = %+v", e, want)
+			}
+		case <-time.After(time.Second * 2):
+			t.Fatalf("%s not received", want.Type)
+		}
+	}
+}
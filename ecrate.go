@@ -0,0 +1,98 @@
+package monitor
+
+// ECRateResponse is a struct formed after the response from the factomd "entry-credit-rate" API.
+type ECRateResponse struct {
+	// Factoshis per entry credit
+	Rate int64 `json:"rate"`
+}
+
+// RateEvent is sent to rate listeners when the entry credit exchange rate changes.
+type RateEvent struct {
+	// The rates in factoshis per entry credit
+	Old int64 `json:"old"`
+	New int64 `json:"new"`
+	// The dbheight at which the change was detected
+	DBHeight int64 `json:"dbheight"`
+}
+
+// ECRateRequest sends an "entry-credit-rate" API request to the configured node.
+func (m *Monitor) ECRateRequest() (int64, error) {
+	res := new(ECRateResponse)
+	if err := m.call("entry-credit-rate", nil, res); err != nil {
+		return 0, err
+	}
+	return res.Rate, nil
+}
+
+// WatchECRate starts checking the entry credit exchange rate after every new dbheight.
+// Rate listeners receive an event whenever it changes.
+// The current rate is fetched immediately, returning an error if the request fails.
+// Calling it more than once has no effect.
+func (m *Monitor) WatchECRate() error {
+	m.watchMtx.Lock()
+	watching := m.ecRateWatched
+	m.watchMtx.Unlock()
+	if watching {
+		return nil
+	}
+
+	rate, err := m.ECRateRequest()
+	if err != nil {
+		return err
+	}
+
+	m.watchMtx.Lock()
+	defer m.watchMtx.Unlock()
+	m.watchECRate(rate)
+	return nil
+}
+
+// watchECRate starts watching the rate with a known value if it isn't watched yet,
+// must be called with watchMtx held
+func (m *Monitor) watchECRate(rate int64) {
+	if !m.ecRateWatched {
+		m.ecRateWatched = true
+		m.ecRate = rate
+		m.everyDBHeight(m.cadenced(WatcherECRate, m.checkECRate))
+	}
+}
+
+// NewECRateListener spawns a new listener that receives an event every time the entry
+// credit exchange rate changes.
+// Each reader must have its own listener.
+func (m *Monitor) NewECRateListener() <-chan RateEvent {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan RateEvent, 6)
+	m.rateListeners = append(m.rateListeners, l)
+	m.register("rate", l)
+	return l
+}
+
+func (m *Monitor) checkECRate(dbheight int64) {
+	rate, err := m.ECRateRequest()
+	if err != nil {
+		m.notifyError(err)
+		return
+	}
+
+	m.watchMtx.Lock()
+	old := m.ecRate
+	m.ecRate = rate
+	m.watchMtx.Unlock()
+	if rate == old {
+		return
+	}
+
+	e := RateEvent{Old: old, New: rate, DBHeight: dbheight}
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	for _, l := range m.rateListeners {
+		select {
+		case l <- e:
+			m.delivered(l)
+		default:
+			m.dropped(l)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package monitor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMonitor_WatchECRate(t *testing.T) {
+	s := newTestServer("localhost:9865", 10, 0, time.Second*10, t)
+	defer s.stop()
+	rate := int64(1000)
+	s.handle("entry-credit-rate", func(json.RawMessage) interface{} {
+		return ECRateResponse{Rate: rate}
+	})
+
+	m, err := NewMonitorWithConfig("http://localhost:9865/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	if err := m.WatchECRate(); err != nil {
+		t.Fatal(err)
+	}
+	listener := m.NewECRateListener()
+
+	s.mtx.Lock()
+	rate = 1200
+	s.mtx.Unlock()
+	s.tick() // dbheight 10
+
+	select {
+	case e := <-listener:
+		if e.Old != 1000 || e.New != 1200 || e.DBHeight != 10 {
+			t.Errorf("unexpected event %+v", e)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("rate change not received")
+	}
+}
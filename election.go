@@ -0,0 +1,297 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// Lock is a lock with a lease that expires unless it is renewed, shared by multiple
+// monitor instances to elect a leader. See Elector.
+// The redis and etcd sub-packages contain implementations backed by those stores.
+type Lock interface {
+	// Acquire takes the lock for the holder for the duration of ttl, or renews the lease
+	// if the holder already has it. Returns false if another holder has an unexpired lease.
+	Acquire(ctx context.Context, holder string, ttl time.Duration) (bool, error)
+	// Release gives up the lock if the holder has it
+	Release(ctx context.Context, holder string) error
+}
+
+// Leader is implemented by an Elector. Sinks and notifiers with a Leader only deliver
+// while it returns true.
+type Leader interface {
+	IsLeader() bool
+}
+
+// ElectionConfig contains the settings of an elector
+type ElectionConfig struct {
+	// ID identifies the instance to the lock. Defaults to the host name and process id.
+	ID string
+	// The duration of the lease. If the leader stops renewing it, another instance takes
+	// over after at most TTL. Defaults to 15 seconds.
+	TTL time.Duration
+	// The time between attempts to acquire or renew the lease. Defaults to a third of TTL.
+	RenewInterval time.Duration
+}
+
+// Elector campaigns for a lock on behalf of one of several monitor instances that run at
+// the same time. All instances poll and evaluate events, but only the instance holding
+// the lock is the leader and delivers notifications, so a pair of monitors is highly
+// available without sending every alert twice.
+type Elector struct {
+	lock Lock
+	conf ElectionConfig
+
+	mtx       sync.Mutex
+	leader    bool
+	renewed   time.Time // the last successful acquisition
+	listeners []chan bool
+
+	errors chan error
+
+	close  chan interface{}
+	done   chan interface{}
+	closer sync.Once
+}
+
+var _ Leader = (*Elector)(nil)
+
+// NewElector creates an elector that begins campaigning for the lock immediately.
+// Starts a goroutine that can be stopped via elector.Stop().
+func NewElector(lock Lock, conf ElectionConfig) *Elector {
+	if conf.ID == "" {
+		host, _ := os.Hostname()
+		conf.ID = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	if conf.TTL <= 0 {
+		conf.TTL = time.Second * 15
+	}
+	if conf.RenewInterval <= 0 {
+		conf.RenewInterval = conf.TTL / 3
+	}
+	e := new(Elector)
+	e.lock = lock
+	e.conf = conf
+	e.errors = make(chan error, 6)
+	e.close = make(chan interface{})
+	e.done = make(chan interface{})
+	go e.run()
+	return e
+}
+
+// ID returns the id the elector campaigns under
+func (e *Elector) ID() string {
+	return e.conf.ID
+}
+
+// IsLeader returns true while the elector holds the lock
+func (e *Elector) IsLeader() bool {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	return e.leader
+}
+
+// NewLeaderListener spawns a new listener that receives true when the elector becomes
+// the leader and false when it loses the leadership.
+// Each reader must have its own listener.
+func (e *Elector) NewLeaderListener() <-chan bool {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	l := make(chan bool, 6)
+	e.listeners = append(e.listeners, l)
+	return l
+}
+
+// Errors returns a channel that receives errors from the lock.
+// Errors are dropped if the channel is not read.
+func (e *Elector) Errors() <-chan error {
+	return e.errors
+}
+
+func (e *Elector) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(e.conf.RenewInterval)
+	defer ticker.Stop()
+
+	e.campaign()
+	for {
+		select {
+		case <-e.close:
+			return
+		case <-ticker.C:
+			e.campaign()
+		}
+	}
+}
+
+// campaign acquires or renews the lock. If the lock can't be reached, the elector
+// stays leader only while its lease is certain to outlast the next attempt.
+func (e *Elector) campaign() {
+	ctx, cancel := context.WithTimeout(context.Background(), e.conf.RenewInterval)
+	defer cancel()
+	now := time.Now()
+	ok, err := e.lock.Acquire(ctx, e.conf.ID, e.conf.TTL)
+
+	e.mtx.Lock()
+	if err != nil {
+		ok = e.leader && now.Add(e.conf.RenewInterval).Before(e.renewed.Add(e.conf.TTL))
+	} else if ok {
+		e.renewed = now
+	}
+	e.mtx.Unlock()
+
+	if err != nil {
+		e.notifyError(err)
+	}
+	e.setLeader(ok)
+}
+
+func (e *Elector) setLeader(leader bool) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	if e.leader == leader {
+		return
+	}
+	e.leader = leader
+	for _, l := range e.listeners {
+		select {
+		case l <- leader:
+		default:
+		}
+	}
+}
+
+func (e *Elector) notifyError(err error) {
+	select {
+	case e.errors <- err:
+	default:
+	}
+}
+
+// Stop halts the campaign and releases the lock, so another instance can take over
+// right away
+func (e *Elector) Stop() {
+	e.closer.Do(func() {
+		close(e.close)
+		<-e.done
+		e.setLeader(false)
+		ctx, cancel := context.WithTimeout(context.Background(), e.conf.RenewInterval)
+		defer cancel()
+		if err := e.lock.Release(ctx, e.conf.ID); err != nil {
+			e.notifyError(err)
+		}
+	})
+}
+
+// FileLock is a Lock kept in a file, for instances on the same host or with a shared
+// file system.
+type FileLock struct {
+	path string
+}
+
+var _ Lock = (*FileLock)(nil)
+
+// fileLease is the content of a FileLock's file
+type fileLease struct {
+	Holder  string    `json:"holder"`
+	Expires time.Time `json:"expires"`
+}
+
+// NewFileLock creates a lock that keeps the lease in the file at path.
+// A second file with the suffix ".lock" guards changes to the lease.
+func NewFileLock(path string) *FileLock {
+	fl := new(FileLock)
+	fl.path = path
+	return fl
+}
+
+// Acquire takes or renews the lease if it is free, expired, or already held by the holder
+func (fl *FileLock) Acquire(ctx context.Context, holder string, ttl time.Duration) (bool, error) {
+	unlock, err := fl.mutex(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	lease, err := fl.read()
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	if lease != nil && lease.Holder != holder && now.Before(lease.Expires) {
+		return false, nil
+	}
+
+	js, err := json.Marshal(fileLease{Holder: holder, Expires: now.Add(ttl)})
+	if err != nil {
+		return false, err
+	}
+	if err := writeFile(fl.path, js); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Release removes the lease if it is held by the holder
+func (fl *FileLock) Release(ctx context.Context, holder string) error {
+	unlock, err := fl.mutex(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	lease, err := fl.read()
+	if err != nil || lease == nil || lease.Holder != holder {
+		return err
+	}
+	return os.Remove(fl.path)
+}
+
+func (fl *FileLock) read() (*fileLease, error) {
+	data, err := ioutil.ReadFile(fl.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	lease := new(fileLease)
+	if err := json.Unmarshal(data, lease); err != nil {
+		return nil, err
+	}
+	return lease, nil
+}
+
+// staleMutex is the age after which the guard file of a process that crashed while
+// holding it is removed
+const staleMutex = time.Second * 10
+
+// mutex creates the guard file exclusively, waiting until the context is done for
+// other processes to remove it
+func (fl *FileLock) mutex(ctx context.Context) (func(), error) {
+	name := fl.path + ".lock"
+	for {
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(name) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, err := os.Stat(name); err == nil && time.Since(info.ModTime()) > staleMutex {
+			os.Remove(name)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Millisecond * 10):
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package monitor
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "election")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+	fl := NewFileLock(filepath.Join(dir, "leader"))
+
+	for _, tt := range []struct {
+		holder string
+		want   bool
+	}{{"a", true}, {"a", true}, {"b", false}} {
+		ok, err := fl.Acquire(ctx, tt.holder, time.Millisecond*200)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != tt.want {
+			t.Errorf("acquire by %s: got = %v, want = %v", tt.holder, ok, tt.want)
+		}
+	}
+
+	time.Sleep(time.Millisecond * 250)
+	if ok, _ := fl.Acquire(ctx, "b", time.Second); !ok {
+		t.Errorf("expired lease not taken over")
+	}
+	if err := fl.Release(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := fl.Acquire(ctx, "a", time.Second); ok {
+		t.Errorf("lease released by another holder")
+	}
+	if err := fl.Release(ctx, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := fl.Acquire(ctx, "a", time.Second); !ok {
+		t.Errorf("lease not free after release")
+	}
+}
+
+func TestElector(t *testing.T) {
+	dir, err := ioutil.TempDir("", "election")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fl := NewFileLock(filepath.Join(dir, "leader"))
+	conf := ElectionConfig{TTL: time.Millisecond * 300, RenewInterval: time.Millisecond * 50}
+
+	conf.ID = "a"
+	a := NewElector(fl, conf)
+	defer a.Stop()
+	leader := a.NewLeaderListener()
+
+	select {
+	case ok := <-leader:
+		if !ok {
+			t.Fatal("first elector lost the leadership")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("first elector did not become leader")
+	}
+
+	conf.ID = "b"
+	b := NewElector(fl, conf)
+	defer b.Stop()
+	follower := b.NewLeaderListener()
+	time.Sleep(time.Millisecond * 150)
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatalf("unexpected leaders a = %v, b = %v", a.IsLeader(), b.IsLeader())
+	}
+
+	a.Stop()
+	if a.IsLeader() {
+		t.Error("stopped elector is still leader")
+	}
+	select {
+	case ok := <-follower:
+		if !ok {
+			t.Fatal("second elector lost the leadership")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("second elector did not take over")
+	}
+}
@@ -0,0 +1,51 @@
+package monitor
+
+import "time"
+
+// EndOfMinuteProcessing is sent to sealing listeners when the node reports minute 10,
+// the internal state in which it processes the end of minute 9 and seals the block.
+// Minute listeners never see minute 10.
+type EndOfMinuteProcessing struct {
+	// The block being sealed, one more than the height of the response
+	Block    int64 `json:"block"`
+	DBHeight int64 `json:"dbheight"`
+	// The time the monitor observed the state
+	Time time.Time `json:"time"`
+	// The API response that reported minute 10. It is shared between all
+	// listeners and must not be modified.
+	Raw *MinuteResponse `json:"-"`
+}
+
+// NewSealingListener spawns a new listener that receives an event when the node is
+// sealing a block. The node is only in this state for a short time, so it may be missed
+// if the monitor doesn't poll at the right moment.
+// Each reader must have its own listener.
+func (m *Monitor) NewSealingListener() <-chan EndOfMinuteProcessing {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan EndOfMinuteProcessing, 6)
+	m.sealingListeners = append(m.sealingListeners, l)
+	m.register("sealing", l)
+	return l
+}
+
+// checkSealing sends an event the first time the node reports minute 10 for a block
+func (m *Monitor) checkSealing(resp *MinuteResponse) {
+	block := resp.LeaderHeight + 1
+	if resp.Minute != 10 || block <= m.sealed {
+		return
+	}
+	m.sealed = block
+
+	e := EndOfMinuteProcessing{Block: block, DBHeight: resp.DBHeight, Time: m.clock().Now(), Raw: resp}
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	for _, l := range m.sealingListeners {
+		select {
+		case l <- e:
+			m.delivered(l)
+		default:
+			m.dropped(l)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonitor_NewSealingListener(t *testing.T) {
+	s := newTestServer("localhost:9844", 10, 9, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitorWithConfig("http://localhost:9844/v2", Config{Interval: time.Millisecond * 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	sealing := m.NewSealingListener()
+	minutes := m.NewMinuteListener()
+
+	s.mtx.Lock()
+	s.minute = 10
+	s.mtx.Unlock()
+
+	select {
+	case e := <-sealing:
+		if e.Block != 11 || e.DBHeight != 10 || e.Raw == nil {
+			t.Errorf("unexpected sealing event %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("sealing event not received")
+	}
+
+	time.Sleep(time.Millisecond * 200) // several more polls at minute 10
+	select {
+	case e := <-sealing:
+		t.Errorf("block reported twice: %+v", e)
+	case e := <-minutes:
+		t.Errorf("minute 10 sent to minute listener: %+v", e)
+	default:
+	}
+
+	s.tick() // height 11, minute 0
+	select {
+	case e := <-minutes:
+		if e.Height != 11 || e.Minute != 0 {
+			t.Errorf("unexpected minute event %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("minute event not received")
+	}
+}
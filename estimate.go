@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNoEstimate is returned if there is not enough information to estimate a boundary,
+// which happens if the node doesn't report its block time or minute start times
+var ErrNoEstimate = errors.New("not enough information for an estimate")
+
+// MinuteDuration returns the expected duration of a minute as configured in the node.
+// If the node did not report a block time, zero is returned.
+func (m *Monitor) MinuteDuration() time.Duration {
+	m.heightMtx.Lock()
+	defer m.heightMtx.Unlock()
+	return m.minuteDuration()
+}
+
+func (m *Monitor) minuteDuration() time.Duration {
+	return time.Duration(m.blockSeconds) * time.Second / 10
+}
+
+// estimateNextMinute must be called with heightMtx held
+func (m *Monitor) estimateNextMinute() (time.Time, error) {
+	dur := m.minuteDuration()
+	if dur <= 0 {
+		return time.Time{}, ErrNoEstimate
+	}
+
+	// prefer the node's minute start, converted to the local clock
+	if !m.minuteStart.IsZero() {
+		return m.minuteStart.Add(m.clockOffset).Add(dur), nil
+	}
+
+	// fall back on when the monitor observed the current minute
+	if !m.lastEvent.IsZero() {
+		return m.lastEvent.Add(dur), nil
+	}
+
+	return time.Time{}, ErrNoEstimate
+}
+
+// EstimateNextMinute returns the local time the network is expected to move to the next minute.
+// The estimate is based on the node's reported minute start time and block time, adjusted
+// for the difference between the node's clock and the local clock.
+// If the node doesn't report a minute start time, the time the monitor observed the
+// current minute is used instead.
+//
+// Minutes can run late, so the estimate may be in the past.
+func (m *Monitor) EstimateNextMinute() (time.Time, error) {
+	m.heightMtx.Lock()
+	defer m.heightMtx.Unlock()
+	return m.estimateNextMinute()
+}
+
+// EstimateNextBlock returns the local time the network is expected to complete the block
+// it is currently working on, ie the next time the height increases.
+// See EstimateNextMinute for how the estimate is calculated.
+func (m *Monitor) EstimateNextBlock() (time.Time, error) {
+	m.heightMtx.Lock()
+	defer m.heightMtx.Unlock()
+
+	next, err := m.estimateNextMinute()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	remaining := 9 - m.minute
+	if remaining < 0 {
+		remaining = 0
+	}
+	return next.Add(time.Duration(remaining) * m.minuteDuration()), nil
+}
@@ -0,0 +1,120 @@
+package monitor
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestMonitor_Estimate(t *testing.T) {
+	m := new(Monitor)
+	if _, err := m.EstimateNextMinute(); err != ErrNoEstimate {
+		t.Errorf("unexpected error without block time. got = %v, want = %v", err, ErrNoEstimate)
+	}
+
+	start := time.Now().Add(-time.Second * 20)
+	m.blockSeconds = 600
+	m.minute = 7
+	m.minuteStart = start
+	m.clockOffset = time.Second * 2
+
+	next, err := m.EstimateNextMinute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := start.Add(time.Second * 62); !next.Equal(want) {
+		t.Errorf("unexpected next minute. got = %v, want = %v", next, want)
+	}
+
+	block, err := m.EstimateNextBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := start.Add(time.Second * 182); !block.Equal(want) {
+		t.Errorf("unexpected next block. got = %v, want = %v", block, want)
+	}
+
+	// no minute start reported by the node
+	m.minuteStart = time.Time{}
+	if _, err := m.EstimateNextMinute(); err != ErrNoEstimate {
+		t.Errorf("unexpected error without start times. got = %v, want = %v", err, ErrNoEstimate)
+	}
+
+	m.lastEvent = start
+	next, err = m.EstimateNextMinute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := start.Add(time.Minute); !next.Equal(want) {
+		t.Errorf("unexpected fallback estimate. got = %v, want = %v", next, want)
+	}
+}
+
+func TestMonitor_pollDelay(t *testing.T) {
+	m := new(Monitor)
+	if d := m.pollDelay(); d != Interval {
+		t.Errorf("unexpected delay without estimate. got = %v, want = %v", d, Interval)
+	}
+
+	m.blockSeconds = 600
+	tests := []struct {
+		name  string
+		start time.Duration // minute start relative to now
+		min   time.Duration
+		max   time.Duration
+	}{
+		{"start of minute", 0, SlowInterval, SlowInterval},
+		{"approaching boundary", -time.Second * 55, time.Second * 2, time.Second * 3},
+		{"around boundary", -time.Minute, FastInterval, FastInterval},
+		{"late minute", -time.Second * 70, Interval, Interval},
+	}
+
+	for _, tt := range tests {
+		m.minuteStart = time.Now().Add(tt.start)
+		if d := m.pollDelay(); d < tt.min || d > tt.max {
+			t.Errorf("%s: unexpected delay %v, want between %v and %v", tt.name, d, tt.min, tt.max)
+		}
+	}
+
+	// the config replaces the package's durations
+	m.conf = Config{Interval: time.Second * 3, FastInterval: time.Millisecond * 100, SlowInterval: time.Second * 20, BoundaryWindow: time.Second * 5}
+	tests = []struct {
+		name  string
+		start time.Duration
+		min   time.Duration
+		max   time.Duration
+	}{
+		{"start of minute", 0, time.Second * 20, time.Second * 20},
+		{"approaching boundary", -time.Second * 50, time.Second * 4, time.Second * 5},
+		{"around boundary", -time.Second * 63, time.Millisecond * 100, time.Millisecond * 100},
+		{"late minute", -time.Second * 70, time.Second * 3, time.Second * 3},
+	}
+	for _, tt := range tests {
+		m.minuteStart = time.Now().Add(tt.start)
+		if d := m.pollDelay(); d < tt.min || d > tt.max {
+			t.Errorf("config %s: unexpected delay %v, want between %v and %v", tt.name, d, tt.min, tt.max)
+		}
+	}
+}
+
+func TestMonitor_jitter(t *testing.T) {
+	m := new(Monitor)
+	m.random = rand.New(rand.NewSource(1))
+
+	if d := m.jitter(time.Second); d != time.Second {
+		t.Errorf("delay changed without jitter. got = %v, want = %v", d, time.Second)
+	}
+
+	m.conf.Jitter = 0.1
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 100; i++ {
+		d := m.jitter(time.Second)
+		if d < time.Millisecond*900 || d > time.Millisecond*1100 {
+			t.Errorf("delay out of bounds: %v", d)
+		}
+		seen[d] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("jitter did not randomize delays")
+	}
+}
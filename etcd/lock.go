@@ -0,0 +1,175 @@
+// Package etcd elects a leader among monitor instances with a key in etcd.
+//
+// Requests are sent to the JSON gateway of etcd's v3 API over HTTP, so the package
+// does not depend on the etcd client library.
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+// Lock is a monitor.Lock kept in an etcd key that is attached to a lease.
+// If the holder stops renewing the lease, etcd deletes the key when the lease expires.
+type Lock struct {
+	endpoint string
+	key      string
+	client   *http.Client
+
+	mtx    sync.Mutex
+	lease  int64 // the lease granted to holder, 0 if there is none
+	holder string
+}
+
+var _ monitor.Lock = (*Lock)(nil)
+
+// NewLock creates a lock stored in the key, ie "/factom/leader", on the etcd server at
+// the endpoint, ie "http://localhost:2379". If client is nil, http.DefaultClient is used.
+func NewLock(endpoint, key string, client *http.Client) *Lock {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	l := new(Lock)
+	l.endpoint = strings.TrimSuffix(endpoint, "/")
+	l.key = key
+	l.client = client
+	return l
+}
+
+// Acquire takes the key if it doesn't exist or already belongs to the holder, and
+// renews the holder's lease
+func (l *Lock) Acquire(ctx context.Context, holder string, ttl time.Duration) (bool, error) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if l.lease != 0 && l.holder == holder {
+		alive, err := l.keepAlive(ctx)
+		if err != nil {
+			return false, err
+		}
+		if !alive {
+			l.lease = 0
+		}
+	}
+	if l.lease == 0 || l.holder != holder {
+		id, err := l.grant(ctx, ttl)
+		if err != nil {
+			return false, err
+		}
+		l.lease, l.holder = id, holder
+	}
+
+	put := op{"request_put": map[string]string{"key": encode(l.key), "value": encode(holder), "lease": strconv.FormatInt(l.lease, 10)}}
+	created, err := l.txn(ctx, op{"target": "CREATE", "key": encode(l.key), "create_revision": "0"}, put)
+	if err != nil || created {
+		return created, err
+	}
+	// the key exists, take it over if it belongs to the holder, ie with an expired lease
+	return l.txn(ctx, op{"target": "VALUE", "key": encode(l.key), "value": encode(holder)}, put)
+}
+
+// Release deletes the key if it belongs to the holder and revokes the holder's lease
+func (l *Lock) Release(ctx context.Context, holder string) error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	del := op{"request_delete_range": map[string]string{"key": encode(l.key)}}
+	if _, err := l.txn(ctx, op{"target": "VALUE", "key": encode(l.key), "value": encode(holder)}, del); err != nil {
+		return err
+	}
+	if l.lease == 0 || l.holder != holder {
+		return nil
+	}
+	err := l.call(ctx, "/v3/lease/revoke", map[string]string{"ID": strconv.FormatInt(l.lease, 10)}, nil)
+	l.lease = 0
+	return err
+}
+
+// op is a single comparison or operation of a transaction
+type op map[string]interface{}
+
+func encode(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+// grant creates a lease of at least one second
+func (l *Lock) grant(ctx context.Context, ttl time.Duration) (int64, error) {
+	seconds := int64((ttl + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	var res struct {
+		ID json.Number `json:"ID"`
+	}
+	if err := l.call(ctx, "/v3/lease/grant", map[string]int64{"TTL": seconds}, &res); err != nil {
+		return 0, err
+	}
+	return res.ID.Int64()
+}
+
+// keepAlive renews the lease, returns false if it already expired
+func (l *Lock) keepAlive(ctx context.Context) (bool, error) {
+	var res struct {
+		Result struct {
+			TTL json.Number `json:"TTL"`
+		} `json:"result"`
+	}
+	if err := l.call(ctx, "/v3/lease/keepalive", map[string]string{"ID": strconv.FormatInt(l.lease, 10)}, &res); err != nil {
+		return false, err
+	}
+	ttl, _ := res.Result.TTL.Int64()
+	return ttl > 0, nil
+}
+
+// txn runs the operation if the comparison holds and returns whether it did
+func (l *Lock) txn(ctx context.Context, compare, then op) (bool, error) {
+	compare["result"] = "EQUAL"
+	req := map[string][]op{"compare": {compare}, "success": {then}}
+	var res struct {
+		Succeeded bool `json:"succeeded"`
+	}
+	if err := l.call(ctx, "/v3/kv/txn", req, &res); err != nil {
+		return false, err
+	}
+	return res.Succeeded, nil
+}
+
+// call posts the request to the gateway and decodes the response into res, if not nil
+func (l *Lock) call(ctx context.Context, path string, req, res interface{}) error {
+	js, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	r, err := http.NewRequest(http.MethodPost, l.endpoint+path, bytes.NewReader(js))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	resp, err := l.client.Do(r.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("etcd %s: %s: %s", path, resp.Status, bytes.TrimSpace(body))
+	}
+	if res == nil {
+		return nil
+	}
+	return json.Unmarshal(body, res)
+}
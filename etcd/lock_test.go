@@ -0,0 +1,146 @@
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeGateway emulates the parts of the etcd v3 JSON gateway used by the lock.
+// Leases never expire unless revoked.
+type fakeGateway struct {
+	*httptest.Server
+	mtx    sync.Mutex
+	leases map[int64]bool
+	keys   map[string]string // base64 key to base64 value
+	owners map[string]int64  // base64 key to lease
+	nextID int64
+}
+
+func newFakeGateway() *fakeGateway {
+	g := new(fakeGateway)
+	g.leases = make(map[int64]bool)
+	g.keys = make(map[string]string)
+	g.owners = make(map[string]int64)
+	g.Server = httptest.NewServer(http.HandlerFunc(g.serve))
+	return g
+}
+
+func (g *fakeGateway) serve(rw http.ResponseWriter, r *http.Request) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	var req map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id := func() int64 {
+		var s string
+		json.Unmarshal(req["ID"], &s)
+		n, _ := strconv.ParseInt(s, 10, 64)
+		return n
+	}
+
+	var res interface{}
+	switch r.URL.Path {
+	case "/v3/lease/grant":
+		g.nextID++
+		g.leases[g.nextID] = true
+		res = map[string]string{"ID": strconv.FormatInt(g.nextID, 10), "TTL": "15"}
+	case "/v3/lease/keepalive":
+		ttl := "0"
+		if g.leases[id()] {
+			ttl = "15"
+		}
+		res = map[string]interface{}{"result": map[string]string{"TTL": ttl}}
+	case "/v3/lease/revoke":
+		lease := id()
+		delete(g.leases, lease)
+		for k, owner := range g.owners {
+			if owner == lease {
+				delete(g.keys, k)
+				delete(g.owners, k)
+			}
+		}
+		res = map[string]string{}
+	case "/v3/kv/txn":
+		var compare []map[string]string
+		var success []map[string]map[string]string
+		json.Unmarshal(req["compare"], &compare)
+		json.Unmarshal(req["success"], &success)
+		c := compare[0]
+		v, exists := g.keys[c["key"]]
+		ok := (c["target"] == "CREATE" && !exists) || (c["target"] == "VALUE" && exists && v == c["value"])
+		if ok {
+			if put, found := success[0]["request_put"]; found {
+				lease, _ := strconv.ParseInt(put["lease"], 10, 64)
+				g.keys[put["key"]] = put["value"]
+				g.owners[put["key"]] = lease
+			}
+			if del, found := success[0]["request_delete_range"]; found {
+				delete(g.keys, del["key"])
+				delete(g.owners, del["key"])
+			}
+		}
+		res = map[string]bool{"succeeded": ok}
+	default:
+		http.NotFound(rw, r)
+		return
+	}
+	json.NewEncoder(rw).Encode(res)
+}
+
+func TestLock(t *testing.T) {
+	ctx := context.Background()
+	g := newFakeGateway()
+	defer g.Close()
+
+	a := NewLock(g.URL, "/factom/leader", nil)
+	b := NewLock(g.URL, "/factom/leader", nil)
+
+	for _, tt := range []struct {
+		lock   *Lock
+		holder string
+		want   bool
+	}{{a, "a", true}, {a, "a", true}, {b, "b", false}} {
+		ok, err := tt.lock.Acquire(ctx, tt.holder, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != tt.want {
+			t.Errorf("acquire by %s: got = %v, want = %v", tt.holder, ok, tt.want)
+		}
+	}
+	if g.nextID != 2 {
+		t.Errorf("lease not renewed, %d leases granted", g.nextID)
+	}
+
+	if err := b.Release(ctx, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.keys) != 1 {
+		t.Errorf("lock released by another holder")
+	}
+
+	// the lease expires, the key is gone
+	g.mtx.Lock()
+	delete(g.leases, a.lease)
+	g.keys, g.owners = make(map[string]string), make(map[string]int64)
+	g.mtx.Unlock()
+	if ok, err := a.Acquire(ctx, "a", time.Second); err != nil || !ok {
+		t.Errorf("lock not reacquired after the lease expired: %v %v", ok, err)
+	}
+
+	if err := a.Release(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := b.Acquire(ctx, "b", time.Second); !ok {
+		t.Errorf("lock not free after release")
+	}
+}
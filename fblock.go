@@ -0,0 +1,125 @@
+package monitor
+
+import "time"
+
+// FactoidBlockResponse is a struct formed after the response from the factomd "fblock-by-height" API.
+type FactoidBlockResponse struct {
+	FBlock struct {
+		BodyMR          string               `json:"bodymr"`
+		PrevKeyMR       string               `json:"prevkeymr"`
+		PrevLedgerKeyMR string               `json:"prevledgerkeymr"`
+		ExchRate        int64                `json:"exchrate"`
+		DBHeight        int64                `json:"dbheight"`
+		Transactions    []FactoidTransaction `json:"transactions"`
+		KeyMR           string               `json:"keymr"`
+		LedgerKeyMR     string               `json:"ledgerkeymr"`
+	} `json:"fblock"`
+	RawData string `json:"rawdata"`
+}
+
+// FactoidTransaction is a single transaction of a factoid block
+type FactoidTransaction struct {
+	TxID           string               `json:"txid"`
+	BlockHeight    int64                `json:"blockheight"`
+	MilliTimestamp int64                `json:"millitimestamp"`
+	Inputs         []TransactionAddress `json:"inputs"`
+	Outputs        []TransactionAddress `json:"outputs"`
+	OutECs         []TransactionAddress `json:"outecs"`
+}
+
+// TransactionEvent is sent to transaction listeners for every transaction in a new factoid block.
+type TransactionEvent struct {
+	DBHeight  int64                `json:"dbheight"`
+	TxID      string               `json:"txid"`
+	Timestamp time.Time            `json:"timestamp"`
+	Inputs    []TransactionAddress `json:"inputs"`
+	Outputs   []TransactionAddress `json:"outputs"`
+	ECOutputs []TransactionAddress `json:"ecoutputs"`
+	// The fee in factoshis, zero for the coinbase transaction
+	Fee int64 `json:"fee"`
+}
+
+// FactoidBlockRequest sends an "fblock-by-height" API request to the configured node.
+func (m *Monitor) FactoidBlockRequest(dbheight int64) (*FactoidBlockResponse, error) {
+	res := new(FactoidBlockResponse)
+	if err := m.call("fblock-by-height", map[string]int64{"height": dbheight}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// WatchTransactions starts fetching the factoid block of every new dbheight.
+// Transaction listeners receive an event for every transaction in the block.
+// Calling it more than once has no effect.
+func (m *Monitor) WatchTransactions() {
+	m.watchMtx.Lock()
+	defer m.watchMtx.Unlock()
+	if !m.fblocks {
+		m.fblocks = true
+		m.everyDBHeight(m.fetchFactoidBlock)
+	}
+}
+
+// NewTransactionListener spawns a new listener that receives an event for every transaction
+// in a new factoid block.
+// Each reader must have its own listener.
+func (m *Monitor) NewTransactionListener() <-chan TransactionEvent {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan TransactionEvent, 100)
+	m.txListeners = append(m.txListeners, l)
+	m.register("transaction", l)
+	return l
+}
+
+func (m *Monitor) fetchFactoidBlock(dbheight int64) {
+	res, err := m.FactoidBlockRequest(dbheight)
+	if err != nil {
+		m.notifyError(err)
+		return
+	}
+
+	events := make([]TransactionEvent, 0, len(res.FBlock.Transactions))
+	for _, tx := range res.FBlock.Transactions {
+		events = append(events, TransactionEvent{
+			DBHeight:  res.FBlock.DBHeight,
+			TxID:      tx.TxID,
+			Timestamp: time.Unix(0, tx.MilliTimestamp*int64(time.Millisecond)),
+			Inputs:    tx.Inputs,
+			Outputs:   tx.Outputs,
+			ECOutputs: tx.OutECs,
+			Fee:       fee(tx),
+		})
+	}
+
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	for _, e := range events {
+		for _, l := range m.txListeners {
+			select {
+			case l <- e:
+				m.delivered(l)
+			default:
+				m.dropped(l)
+			}
+		}
+	}
+}
+
+// fee is the difference between inputs and outputs of a transaction
+func fee(tx FactoidTransaction) int64 {
+	if len(tx.Inputs) == 0 { // coinbase
+		return 0
+	}
+	var f int64
+	for _, in := range tx.Inputs {
+		f += in.Amount
+	}
+	for _, out := range tx.Outputs {
+		f -= out.Amount
+	}
+	for _, out := range tx.OutECs {
+		f -= out.Amount
+	}
+	return f
+}
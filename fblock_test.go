@@ -0,0 +1,49 @@
+package monitor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMonitor_WatchTransactions(t *testing.T) {
+	s := newTestServer("localhost:9867", 10, 0, time.Second*10, t)
+	defer s.stop()
+	s.handle("fblock-by-height", func(params json.RawMessage) interface{} {
+		res := new(FactoidBlockResponse)
+		res.FBlock.DBHeight = 10
+		res.FBlock.Transactions = []FactoidTransaction{
+			{TxID: hash("1"), MilliTimestamp: 1500000000000, Outputs: []TransactionAddress{{Amount: 800}}},
+			{TxID: hash("2"), MilliTimestamp: 1500000000500,
+				Inputs:  []TransactionAddress{{Amount: 1000}},
+				Outputs: []TransactionAddress{{Amount: 700}},
+				OutECs:  []TransactionAddress{{Amount: 200}},
+			},
+		}
+		return res
+	})
+
+	m, err := NewMonitorWithConfig("http://localhost:9867/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	m.WatchTransactions()
+	listener := m.NewTransactionListener()
+	s.tick() // dbheight 10
+
+	for _, want := range []TransactionEvent{
+		{TxID: hash("1"), Fee: 0, Timestamp: time.Unix(1500000000, 0)},
+		{TxID: hash("2"), Fee: 100, Timestamp: time.Unix(1500000000, int64(time.Millisecond*500))},
+	} {
+		select {
+		case e := <-listener:
+			if e.DBHeight != 10 || e.TxID != want.TxID || e.Fee != want.Fee || !e.Timestamp.Equal(want.Timestamp) {
+				t.Errorf("unexpected event. got = %+v, want = %+v", e, want)
+			}
+		case <-time.After(time.Second * 2):
+			t.Fatalf("transaction %s not received", want.TxID)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package monitor
+
+// filteredListener is a minute listener that only receives events matching the filter
+type filteredListener struct {
+	ch     chan Event
+	filter func(Event) bool
+}
+
+// NewFilteredListener spawns a new listener that only receives the minute events for
+// which filter returns true, ie only minute 0 or only every 10th height.
+// The filter is called by the monitor for every event before it is sent, so it must
+// be fast and must not create listeners.
+// Each reader must have its own listener.
+func (m *Monitor) NewFilteredListener(filter func(Event) bool) <-chan Event {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := filteredListener{ch: make(chan Event, 25), filter: filter}
+	m.filteredListeners = append(m.filteredListeners, l)
+	m.register("filtered", l.ch)
+	return l.ch
+}
+
+// NewMinuteNListener spawns a new listener that receives an event every time the network
+// reaches minute n of a block, ie 9 for the last minute of each block.
+// Minutes outside of 0-9 are never reached.
+// Each reader must have its own listener.
+func (m *Monitor) NewMinuteNListener(n int) <-chan Event {
+	minute := int64(n)
+	return m.NewFilteredListener(func(e Event) bool { return e.Minute == minute })
+}
+
+// notifyFiltered must be called with listenerMtx held
+func (m *Monitor) notifyFiltered(e Event) {
+	for _, l := range m.filteredListeners {
+		if !l.filter(e) {
+			continue
+		}
+		select {
+		case l.ch <- e:
+			m.delivered(l.ch)
+		default:
+			m.dropped(l.ch)
+		}
+	}
+}
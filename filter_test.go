@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonitor_NewFilteredListener(t *testing.T) {
+	s := newTestServer("localhost:9861", 10, 0, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitorWithConfig("http://localhost:9861/v2", Config{Interval: time.Millisecond * 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	even := m.NewFilteredListener(func(e Event) bool { return e.Minute%2 == 0 })
+	all := m.NewMinuteListener()
+
+	for i := 0; i < 4; i++ {
+		s.tick()
+		<-all
+	}
+
+	for _, want := range []int64{2, 4} {
+		select {
+		case e := <-even:
+			if e.Minute != want {
+				t.Errorf("unexpected minute. got = %d, want = %d", e.Minute, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("minute %d not received", want)
+		}
+	}
+	select {
+	case e := <-even:
+		t.Errorf("unexpected event %+v", e)
+	default:
+	}
+}
+
+func TestMonitor_NewMinuteNListener(t *testing.T) {
+	s := newTestServer("localhost:9860", 10, 7, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitorWithConfig("http://localhost:9860/v2", Config{Interval: time.Millisecond * 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	last := m.NewMinuteNListener(9)
+	all := m.NewMinuteListener()
+
+	for i := 0; i < 3; i++ {
+		s.tick()
+		<-all
+	}
+
+	select {
+	case e := <-last:
+		if e.Height != 10 || e.Minute != 9 {
+			t.Errorf("unexpected event %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("minute 9 not received")
+	}
+	select {
+	case e := <-last:
+		t.Errorf("unexpected event %+v", e)
+	default:
+	}
+}
@@ -0,0 +1,73 @@
+package monitor
+
+import (
+	"sort"
+	"time"
+)
+
+// FinalityEvent is sent to finality listeners when a leader height the monitor observed
+// is reflected in the node's DBHeight, meaning the node saved the block
+type FinalityEvent struct {
+	Height int64 `json:"height"`
+	// The time the height was first observed as the leader height
+	Observed time.Time `json:"observed"`
+	// The time the block was observed as saved
+	Time time.Time `json:"time"`
+}
+
+// Delay returns the time between observing the height and observing the saved block
+func (e FinalityEvent) Delay() time.Duration {
+	return e.Time.Sub(e.Observed)
+}
+
+// NewFinalityListener spawns a new listener that receives an event for every leader height
+// once the node has saved its block, for consumers that must not act on blocks that were
+// not persisted. Heights are sent in ascending order. Heights that were never observed as
+// the leader height, ie while the node was syncing or the monitor was down, are skipped.
+// Each reader must have its own listener.
+func (m *Monitor) NewFinalityListener() <-chan FinalityEvent {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan FinalityEvent, 6)
+	m.finalityListeners = append(m.finalityListeners, l)
+	m.register("finality", l)
+	return l
+}
+
+// checkFinality remembers the event's leader height and notifies finality listeners of
+// all remembered heights that are covered by the event's dbheight
+func (m *Monitor) checkFinality(e Event, now time.Time) {
+	m.heightMtx.Lock()
+	if m.unsaved == nil {
+		m.unsaved = make(map[int64]time.Time)
+	}
+	if _, ok := m.unsaved[e.Height]; !ok {
+		m.unsaved[e.Height] = now
+	}
+	var final []FinalityEvent
+	for height, observed := range m.unsaved {
+		if height <= e.DBHeight {
+			final = append(final, FinalityEvent{Height: height, Observed: observed, Time: now})
+			delete(m.unsaved, height)
+		}
+	}
+	m.heightMtx.Unlock()
+
+	if len(final) == 0 {
+		return
+	}
+	sort.Slice(final, func(i, j int) bool { return final[i].Height < final[j].Height })
+
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	for _, f := range final {
+		for _, l := range m.finalityListeners {
+			select {
+			case l <- f:
+				m.delivered(l)
+			default:
+				m.dropped(l)
+			}
+		}
+	}
+}
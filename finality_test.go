@@ -0,0 +1,47 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonitor_NewFinalityListener(t *testing.T) {
+	s := newTestServer("localhost:9833", 10, 0, time.Second*10, t)
+	defer s.stop()
+
+	m, err := New("http://localhost:9833/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener := m.NewFinalityListener()
+	if err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+	expect := func(height int64) {
+		select {
+		case f := <-listener:
+			if f.Height != height || f.Delay() < 0 {
+				t.Errorf("unexpected finality event %+v, want height %d", f, height)
+			}
+		case <-time.After(time.Second * 2):
+			t.Fatalf("height %d not finalized", height)
+		}
+	}
+
+	s.tick() // 10 minute 1, block 10 is saved
+	expect(10)
+
+	for i := 0; i < 9; i++ {
+		s.tick() // until 11 minute 0, block 11 is not saved yet
+	}
+	time.Sleep(time.Millisecond * 300)
+	select {
+	case f := <-listener:
+		t.Fatalf("unsaved height finalized %+v", f)
+	default:
+	}
+
+	s.tick() // 11 minute 1
+	expect(11)
+}
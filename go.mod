@@ -2,4 +2,7 @@ module github.com/WhoSoup/factom-monitor
 
 go 1.14
 
-require github.com/AdamSLevy/jsonrpc2/v14 v14.0.0
+require (
+	github.com/AdamSLevy/jsonrpc2/v14 v14.0.0
+	github.com/gorilla/websocket v1.4.2
+)
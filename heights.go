@@ -0,0 +1,135 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNotStarted is returned by Backfill if the monitor hasn't been started yet
+var ErrNotStarted = errors.New("monitor has not been started")
+
+// HeightsResponse is a struct formed after the response from the factomd "heights" API.
+// See: https://github.com/FactomProject/factomd/blob/0ff77090ab055d4c069612ca1a6814bde88155ab/wsapi/wsapiStructs.go
+type HeightsResponse struct {
+	DirectoryBlockHeight int64 `json:"directoryblockheight"`
+	LeaderHeight         int64 `json:"leaderheight"`
+	EntryBlockHeight     int64 `json:"entryblockheight"`
+	EntryHeight          int64 `json:"entryheight"`
+}
+
+// HeightsRequest sends a "heights" API request to the configured node.
+func (m *Monitor) HeightsRequest(ctx context.Context) (*HeightsResponse, error) {
+	res := new(HeightsResponse)
+	if err := m.request(ctx, "heights", nil, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// backfillBuffer is the most heights Backfill buffers ahead of the reader
+const backfillBuffer = 100
+
+// Backfill returns every height that was completed after the provided height, up to and
+// including the height the monitor started at. Height listeners only receive heights
+// after that, so a height listener created before calling Backfill together with the
+// backfill delivers every height exactly once.
+// The returned channel is closed after the last height, or when ctx is done.
+//
+// The node's "heights" API is used to verify that the node has reached the provided height.
+// An error is returned if it hasn't, which happens when the node is resyncing or belongs to
+// a different network. ErrNotStarted is returned before the monitor was started, since the
+// height it starts at isn't known yet.
+func (m *Monitor) Backfill(ctx context.Context, from int64) (<-chan int64, error) {
+	m.runMtx.Lock()
+	started := m.started
+	m.runMtx.Unlock()
+	if !started {
+		return nil, ErrNotStarted
+	}
+
+	heights, err := m.HeightsRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if from > heights.LeaderHeight {
+		return nil, fmt.Errorf("node is at height %d, which is behind the requested height %d", heights.LeaderHeight, from)
+	}
+
+	m.heightMtx.Lock()
+	to := m.startHeight
+	m.heightMtx.Unlock()
+	if from >= to {
+		l := make(chan int64)
+		close(l)
+		return l, nil
+	}
+
+	size := to - from
+	if size > backfillBuffer {
+		size = backfillBuffer
+	}
+	l := make(chan int64, size)
+	go func() {
+		defer close(l)
+		for h := from + 1; h <= to; h++ {
+			select {
+			case l <- h:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return l, nil
+}
+
+// defaultHeightsTolerance is used if Config.HeightsTolerance is zero
+const defaultHeightsTolerance = 1
+
+// HeightsMismatchError is sent to error listeners when Config.CrossCheckHeights is enabled
+// and the node's "heights" API disagrees with its "current-minute" API, which is a known
+// symptom of an unhealthy node, such as one that stopped processing entries. It is a
+// warning, the monitor still acts on the "current-minute" response.
+// Error listeners receive it once when the disagreement starts and again when it changes.
+type HeightsMismatchError struct {
+	Response *MinuteResponse
+	Heights  *HeightsResponse
+	Reason   string
+}
+
+func (e *HeightsMismatchError) Error() string {
+	return fmt.Sprintf("heights disagree with current-minute: %s", e.Reason)
+}
+
+// crossCheck reconciles the heights with the current-minute response. The node may move
+// on between the two requests, so differences up to Config.HeightsTolerance are accepted.
+func (m *Monitor) crossCheck(resp *MinuteResponse, heights *HeightsResponse) {
+	tolerance := m.conf.HeightsTolerance
+	if tolerance <= 0 {
+		tolerance = defaultHeightsTolerance
+	}
+	apart := func(a, b int64) bool {
+		return a-b > tolerance || b-a > tolerance
+	}
+
+	var reason string
+	switch {
+	case apart(heights.LeaderHeight, resp.LeaderHeight):
+		reason = fmt.Sprintf("leader height %d, current-minute has %d", heights.LeaderHeight, resp.LeaderHeight)
+	case apart(heights.DirectoryBlockHeight, resp.DBHeight):
+		reason = fmt.Sprintf("directory block height %d, current-minute has dbheight %d", heights.DirectoryBlockHeight, resp.DBHeight)
+	case heights.DirectoryBlockHeight-heights.EntryBlockHeight > tolerance:
+		reason = fmt.Sprintf("entry block height %d lags behind directory block height %d", heights.EntryBlockHeight, heights.DirectoryBlockHeight)
+	case heights.DirectoryBlockHeight-heights.EntryHeight > tolerance:
+		reason = fmt.Sprintf("entry height %d lags behind directory block height %d", heights.EntryHeight, heights.DirectoryBlockHeight)
+	}
+
+	m.heightMtx.Lock()
+	changed := reason != m.heightsMismatch
+	m.heightsMismatch = reason
+	m.heightMtx.Unlock()
+
+	if changed && reason != "" {
+		m.notifyError(&HeightsMismatchError{Response: resp, Heights: heights, Reason: reason})
+	}
+}
@@ -0,0 +1,143 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMonitor_Backfill(t *testing.T) {
+	s := newTestServer("localhost:9884", 10, 5, time.Second*10, t)
+	defer s.stop()
+
+	m, err := New("http://localhost:9884/v2", Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	if _, err := m.Backfill(ctx, 6); err != ErrNotStarted {
+		t.Errorf("unexpected error before start. got = %v, want = %v", err, ErrNotStarted)
+	}
+	if err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := m.Backfill(ctx, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := int64(7)
+	for h := range l {
+		if h != want {
+			t.Errorf("out of sequence height. got = %d, want = %d", h, want)
+		}
+		want++
+	}
+	if want != 11 {
+		t.Errorf("backfill ended early. last = %d, want = 10", want-1)
+	}
+
+	l, err = m.Backfill(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h, ok := <-l; ok {
+		t.Errorf("unexpected backfill height %d", h)
+	}
+
+	if _, err := m.Backfill(ctx, 20); err == nil {
+		t.Errorf("no error when backfilling from a height the node hasn't reached")
+	}
+}
+
+func TestMonitor_BackfillLarge(t *testing.T) {
+	s := newTestServer("localhost:9817", 5000, 5, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitor("http://localhost:9817/v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	l, err := m.Backfill(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cap(l) != backfillBuffer {
+		t.Errorf("unexpected buffer size. got = %d, want = %d", cap(l), backfillBuffer)
+	}
+	want := int64(1)
+	for h := range l {
+		if h != want {
+			t.Fatalf("out of sequence height. got = %d, want = %d", h, want)
+		}
+		want++
+	}
+	if want != 5001 {
+		t.Errorf("backfill ended early. last = %d, want = 5000", want-1)
+	}
+
+	// a cancelled backfill closes the channel without being read to the end
+	bctx, bcancel := context.WithCancel(ctx)
+	if l, err = m.Backfill(bctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	bcancel()
+	n := 0
+	for range l {
+		n++
+	}
+	if n > backfillBuffer+1 {
+		t.Errorf("cancelled backfill sent %d heights", n)
+	}
+}
+
+func TestMonitor_CrossCheckHeights(t *testing.T) {
+	s := newTestServer("localhost:9825", 10, 5, time.Second*10, t)
+	defer s.stop()
+	lag := int64(0)
+	s.handle("heights", func(params json.RawMessage) interface{} {
+		resp := s.heights(params).(*HeightsResponse)
+		resp.EntryHeight -= lag
+		return resp
+	})
+
+	m, err := NewMonitorWithConfig("http://localhost:9825/v2", Config{Interval: time.Millisecond * 50, CrossCheckHeights: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+	errs := m.NewErrorListener()
+
+	expectNone := func() {
+		select {
+		case err := <-errs:
+			t.Errorf("unexpected error %v", err)
+		case <-time.After(time.Millisecond * 300):
+		}
+	}
+	expectNone() // healthy node
+
+	s.mtx.Lock()
+	lag = 5
+	s.mtx.Unlock()
+	select {
+	case err := <-errs:
+		mismatch, ok := err.(*HeightsMismatchError)
+		if !ok || mismatch.Heights.EntryHeight != 5 || mismatch.Response.DBHeight != 10 {
+			t.Errorf("unexpected error %v", err)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("mismatch not reported")
+	}
+	expectNone() // reported only once
+}
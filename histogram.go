@@ -0,0 +1,179 @@
+package monitor
+
+import (
+	"math/bits"
+	"sync"
+	"time"
+)
+
+// LatencyWindow is the time covered by the latency histogram. Requests older than
+// one to two windows are forgotten, so the percentiles follow a node that degrades.
+var LatencyWindow time.Duration = time.Minute * 5
+
+// histSubBits determines the precision of the histogram: every power of two is split
+// into 2^(histSubBits-1) buckets, which keeps the error of a percentile below 1/32
+const histSubBits = 6
+
+const (
+	histSub     = 1 << histSubBits
+	histHalf    = histSub / 2
+	histBuckets = histSub + (64-histSubBits)*histHalf
+)
+
+// histIndex returns the bucket of v
+func histIndex(v uint64) int {
+	if v < histSub {
+		return int(v)
+	}
+	shift := bits.Len64(v) - histSubBits
+	return histSub + (shift-1)*histHalf + int(v>>uint(shift)) - histHalf
+}
+
+// histValue returns the highest value that falls into bucket i
+func histValue(i int) uint64 {
+	if i < histSub {
+		return uint64(i)
+	}
+	shift := (i-histSub)/histHalf + 1
+	j := (i-histSub)%histHalf + histHalf
+	return uint64(j+1)<<uint(shift) - 1
+}
+
+// histogram counts durations in logarithmic buckets with linear sub-buckets, like
+// an HDR histogram, with microsecond resolution
+type histogram struct {
+	counts [histBuckets]uint64
+	total  uint64
+	sum    time.Duration
+	max    time.Duration
+}
+
+func (h *histogram) add(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	h.counts[histIndex(uint64(d/time.Microsecond))]++
+	h.total++
+	h.sum += d
+	if d > h.max {
+		h.max = d
+	}
+}
+
+// merge adds the counts of o to h
+func (h *histogram) merge(o *histogram) {
+	for i, c := range o.counts {
+		h.counts[i] += c
+	}
+	h.total += o.total
+	h.sum += o.sum
+	if o.max > h.max {
+		h.max = o.max
+	}
+}
+
+// percentile returns the p-th percentile (0-100), zero if there are no samples.
+// The result is the upper bound of the bucket holding the nearest rank.
+func (h *histogram) percentile(p float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	rank := uint64(p/100*float64(h.total) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+	var seen uint64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= rank {
+			d := time.Duration(histValue(i)) * time.Microsecond
+			if d > h.max {
+				d = h.max
+			}
+			return d
+		}
+	}
+	return h.max
+}
+
+// windowedHistogram keeps the samples of the current and the previous window
+type windowedHistogram struct {
+	mtx     sync.Mutex
+	window  time.Duration
+	start   time.Time // the start of the current window
+	current *histogram
+	prev    *histogram
+}
+
+func newWindowedHistogram(window time.Duration) *windowedHistogram {
+	return &windowedHistogram{window: window, current: new(histogram), prev: new(histogram)}
+}
+
+// rotate starts a new window if the current one is over, must be called with mtx held
+func (w *windowedHistogram) rotate(now time.Time) {
+	if w.start.IsZero() {
+		w.start = now
+	}
+	elapsed := now.Sub(w.start)
+	if elapsed < w.window {
+		return
+	}
+	if elapsed < 2*w.window {
+		w.prev = w.current
+	} else {
+		w.prev = new(histogram)
+	}
+	w.current = new(histogram)
+	w.start = now
+}
+
+func (w *windowedHistogram) add(now time.Time, d time.Duration) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.rotate(now)
+	w.current.add(d)
+}
+
+// snapshot returns the merged samples of the current and the previous window
+func (w *windowedHistogram) snapshot(now time.Time) *histogram {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.rotate(now)
+	h := new(histogram)
+	h.merge(w.prev)
+	h.merge(w.current)
+	return h
+}
+
+// LatencyHistogram contains the percentiles of the latencies of the API requests
+// of the last one to two LatencyWindows. Percentiles are accurate to about 3%.
+type LatencyHistogram struct {
+	// The number of requests the statistics are based on
+	Count uint64        `json:"count"`
+	Mean  time.Duration `json:"mean"`
+	Max   time.Duration `json:"max"`
+	P50   time.Duration `json:"p50"`
+	P95   time.Duration `json:"p95"`
+	P99   time.Duration `json:"p99"`
+}
+
+// LatencyHistogram returns the percentiles of the latencies of recent API requests.
+// Requests that timed out count with the full timeout, other failed requests are not counted.
+func (m *Monitor) LatencyHistogram() LatencyHistogram {
+	return m.histogram.snapshot(time.Now()).summary()
+}
+
+// summary returns the statistics of the samples
+func (h *histogram) summary() LatencyHistogram {
+	var s LatencyHistogram
+	s.Count = h.total
+	if s.Count == 0 {
+		return s
+	}
+	s.Mean = h.sum / time.Duration(h.total)
+	s.Max = h.max
+	s.P50 = h.percentile(50)
+	s.P95 = h.percentile(95)
+	s.P99 = h.percentile(99)
+	return s
+}
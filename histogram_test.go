@@ -0,0 +1,51 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistIndex(t *testing.T) {
+	prev := -1
+	for _, v := range []uint64{0, 1, 63, 64, 65, 127, 128, 1000, 123456, 1 << 40, 1<<64 - 1} {
+		i := histIndex(v)
+		if i < prev || i >= histBuckets {
+			t.Errorf("index %d of %d out of order or range", i, v)
+		}
+		prev = i
+		if max := histValue(i); v > max || float64(max-v) > float64(v)/32+1 {
+			t.Errorf("value %d in bucket %d with upper bound %d", v, i, max)
+		}
+	}
+}
+
+func TestWindowedHistogram(t *testing.T) {
+	w := newWindowedHistogram(time.Minute)
+	now := time.Unix(1600000000, 0)
+
+	for i := 1; i <= 100; i++ {
+		w.add(now, time.Duration(i)*time.Millisecond)
+	}
+	h := w.snapshot(now)
+	if h.total != 100 || h.max != time.Millisecond*100 {
+		t.Errorf("unexpected histogram total %d max %v", h.total, h.max)
+	}
+	for _, tt := range []struct {
+		p    float64
+		want time.Duration
+	}{{50, time.Millisecond * 50}, {95, time.Millisecond * 95}, {99, time.Millisecond * 99}, {100, time.Millisecond * 100}} {
+		got := h.percentile(tt.p)
+		if got < tt.want || got > tt.want+tt.want/32 {
+			t.Errorf("p%v: got = %v, want = %v", tt.p, got, tt.want)
+		}
+	}
+
+	// the samples are kept for one more window
+	w.add(now.Add(time.Second*90), time.Second)
+	if h := w.snapshot(now.Add(time.Second * 90)); h.total != 101 || h.max != time.Second {
+		t.Errorf("unexpected histogram after one window: total %d max %v", h.total, h.max)
+	}
+	if h := w.snapshot(now.Add(time.Minute * 5)); h.total != 0 {
+		t.Errorf("samples not forgotten: total %d", h.total)
+	}
+}
@@ -0,0 +1,59 @@
+package monitor
+
+// history is a ring buffer of the most recent events
+type history struct {
+	events []Event
+	next   int
+	full   bool
+}
+
+func newHistory(size int) *history {
+	h := new(history)
+	h.events = make([]Event, size)
+	return h
+}
+
+func (h *history) add(e Event) {
+	if len(h.events) == 0 {
+		return
+	}
+	h.events[h.next] = e
+	h.next = (h.next + 1) % len(h.events)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// all returns a copy of the buffered events from oldest to newest
+func (h *history) all() []Event {
+	if !h.full {
+		return append([]Event(nil), h.events[:h.next]...)
+	}
+	out := make([]Event, 0, len(h.events))
+	out = append(out, h.events[h.next:]...)
+	return append(out, h.events[:h.next]...)
+}
+
+// RecentEvents returns up to Config.HistorySize of the most recent minute events,
+// from oldest to newest.
+func (m *Monitor) RecentEvents() []Event {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	return m.history.all()
+}
+
+// NewMinuteListenerWithHistory spawns a new minute listener that first receives
+// the buffered events returned by RecentEvents, followed by live events.
+// Each reader must have its own listener.
+func (m *Monitor) NewMinuteListenerWithHistory() <-chan Event {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	recent := m.history.all()
+	l := make(chan Event, 25+len(recent))
+	for _, e := range recent {
+		l <- e
+	}
+	m.minuteListeners = append(m.minuteListeners, l)
+	m.register("minute", l)
+	return l
+}
@@ -0,0 +1,53 @@
+package monitor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	h := newHistory(3)
+	if got := h.all(); len(got) != 0 {
+		t.Errorf("empty history returned events: %v", got)
+	}
+
+	var events []Event
+	for i := int64(0); i < 5; i++ {
+		e := Event{Height: i}
+		events = append(events, e)
+		h.add(e)
+
+		want := events
+		if len(want) > 3 {
+			want = want[len(want)-3:]
+		}
+		if got := h.all(); !reflect.DeepEqual(got, want) {
+			t.Errorf("after %d events: got = %v, want = %v", i+1, got, want)
+		}
+	}
+
+	// disabled history must not panic
+	newHistory(0).add(Event{})
+}
+
+func TestMonitor_NewMinuteListenerWithHistory(t *testing.T) {
+	m := new(Monitor)
+	m.history = newHistory(2)
+
+	m.notify(Event{Height: 1, Minute: 1}, false, false)
+	m.notify(Event{Height: 1, Minute: 2}, false, false)
+	m.notify(Event{Height: 1, Minute: 3}, false, false)
+
+	l := m.NewMinuteListenerWithHistory()
+	m.notify(Event{Height: 1, Minute: 4}, false, false)
+
+	for _, want := range []int64{2, 3, 4} {
+		if e := <-l; e.Minute != want {
+			t.Errorf("out of order event. got = %d, want = %d", e.Minute, want)
+		}
+	}
+
+	if got := m.RecentEvents(); len(got) != 2 || got[1].Minute != 4 {
+		t.Errorf("unexpected recent events: %v", got)
+	}
+}
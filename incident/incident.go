@@ -0,0 +1,222 @@
+// Package incident opens incidents in PagerDuty or Opsgenie when monitor alerts fire and
+// resolves them when the alerts resolve, so on-call engineers get paged directly.
+package incident
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+// Provider opens and resolves incidents. The key identifies the incident, so an
+// incident opened with a key is resolved by the same key.
+type Provider interface {
+	Trigger(ctx context.Context, key string, a monitor.Alert) error
+	Resolve(ctx context.Context, key string, a monitor.Alert) error
+}
+
+// Source is the subset of a monitor used by the sink
+type Source interface {
+	NewAlertListener() <-chan monitor.Alert
+}
+
+// Config contains the settings of a sink
+type Config struct {
+	// Identifies the monitor in incident keys, so multiple monitors can page the same
+	// service. Defaults to "factom-monitor".
+	Name string
+	// Alerts below the severity are ignored. The zero value forwards every alert,
+	// DefaultConfig sets it to warning.
+	MinSeverity monitor.Severity
+	// The number of attempts of a request before it is given up, defaults to 5
+	MaxAttempts int
+	// The time to wait before the first retry, doubling with every further retry.
+	// Defaults to one second.
+	RetryDelay time.Duration
+	// Retry decides when failed requests are retried and when they are given up.
+	// Defaults to monitor.ExponentialRetry(RetryDelay, 0, MaxAttempts).
+	Retry monitor.RetryPolicy
+	// Leader, if set, limits incidents to the times it is the leader, so only one of
+	// several monitor instances opens and resolves them. See monitor.Elector.
+	Leader monitor.Leader
+}
+
+// DefaultConfig returns a config for the given name that ignores info alerts
+func DefaultConfig(name string) Config {
+	return Config{
+		Name:        name,
+		MinSeverity: monitor.SeverityWarning,
+		MaxAttempts: 5,
+		RetryDelay:  time.Second,
+	}
+}
+
+// Timeout specifies the maximum time a single request can take
+var Timeout = time.Second * 10
+
+// Sink forwards alerts to a provider, retrying failed requests
+type Sink struct {
+	provider Provider
+	conf     Config
+
+	errors chan error
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan interface{}
+	closer sync.Once
+}
+
+// NewSink creates a new sink that begins forwarding alerts from the source immediately.
+// Starts a goroutine that can be stopped via sink.Stop().
+func NewSink(provider Provider, src Source, conf Config) *Sink {
+	if conf.Name == "" {
+		conf.Name = "factom-monitor"
+	}
+	if conf.MaxAttempts < 1 {
+		conf.MaxAttempts = 5
+	}
+	if conf.RetryDelay <= 0 {
+		conf.RetryDelay = time.Second
+	}
+	if conf.Retry == nil {
+		conf.Retry = monitor.ExponentialRetry(conf.RetryDelay, 0, conf.MaxAttempts)
+	}
+	s := new(Sink)
+	s.provider = provider
+	s.conf = conf
+	s.errors = make(chan error, 6)
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.done = make(chan interface{})
+	go s.run(src.NewAlertListener())
+	return s
+}
+
+// Errors returns a channel that receives errors from requests that were given up.
+// Errors are dropped if the channel is not read.
+func (s *Sink) Errors() <-chan error {
+	return s.errors
+}
+
+// Key returns the incident key of an alert
+func (s *Sink) Key(a monitor.Alert) string {
+	if a.Network != "" {
+		return s.conf.Name + "/" + a.Network + "/" + a.Rule
+	}
+	return s.conf.Name + "/" + a.Rule
+}
+
+func (s *Sink) run(alerts <-chan monitor.Alert) {
+	defer close(s.done)
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case a, ok := <-alerts:
+			if !ok { // drained
+				return
+			}
+			if a.Severity < s.conf.MinSeverity {
+				continue
+			}
+			if s.conf.Leader != nil && !s.conf.Leader.IsLeader() {
+				continue
+			}
+			s.forward(a)
+		}
+	}
+}
+
+// forward retries the request until it succeeds, the retry policy gives up, or the sink is stopped
+func (s *Sink) forward(a monitor.Alert) {
+	key := s.Key(a)
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(s.ctx, Timeout)
+		var err error
+		if a.Status == monitor.AlertResolved {
+			err = s.provider.Resolve(ctx, key, a)
+		} else {
+			err = s.provider.Trigger(ctx, key, a)
+		}
+		cancel()
+		if err == nil {
+			return
+		}
+		delay, retry := s.conf.Retry.NextDelay(attempt, err)
+		if !retry {
+			select {
+			case s.errors <- fmt.Errorf("incident %s: %v", key, err):
+			default:
+			}
+			return
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// Stop halts the sink
+func (s *Sink) Stop() {
+	s.closer.Do(func() {
+		s.cancel()
+		<-s.done
+	})
+}
+
+// post sends a JSON request and checks for a 2xx status
+func post(ctx context.Context, client *http.Client, url string, header http.Header, body interface{}) error {
+	js, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(js))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// details are the alert fields sent along with incidents
+func details(a monitor.Alert) map[string]interface{} {
+	d := map[string]interface{}{
+		"rule":     a.Rule,
+		"height":   a.Height,
+		"dbheight": a.DBHeight,
+		"minute":   a.Minute,
+		"since":    a.Since.UTC().Format(time.RFC3339),
+	}
+	if a.Network != "" {
+		d["network"] = a.Network
+	}
+	return d
+}
@@ -0,0 +1,160 @@
+package incident
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+	"github.com/WhoSoup/factom-monitor/monitortest"
+)
+
+type call struct {
+	action, key string
+}
+
+type fakeProvider struct {
+	mtx      sync.Mutex
+	calls    []call
+	failures int
+}
+
+func (p *fakeProvider) record(action, key string) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("unavailable")
+	}
+	p.calls = append(p.calls, call{action, key})
+	return nil
+}
+
+func (p *fakeProvider) Trigger(ctx context.Context, key string, a monitor.Alert) error {
+	return p.record("trigger", key)
+}
+
+func (p *fakeProvider) Resolve(ctx context.Context, key string, a monitor.Alert) error {
+	return p.record("resolve", key)
+}
+
+// drain drains the fake and waits for the sink to forward the remaining alerts
+func drain(t *testing.T, fake *monitortest.FakeMonitor, s *Sink) {
+	t.Helper()
+	fake.Drain()
+	select {
+	case <-s.done:
+	case <-time.After(time.Second):
+		t.Fatal("sink did not exit after the source was drained")
+	}
+}
+
+func TestSink(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(10, 1)
+	p := &fakeProvider{failures: 1}
+	conf := DefaultConfig("node1")
+	conf.RetryDelay = time.Millisecond
+	s := NewSink(p, fake, conf)
+	defer s.Stop()
+
+	stall := monitor.Alert{Rule: "no-new-block", Severity: monitor.SeverityCritical, Status: monitor.AlertFiring, Network: "mainnet"}
+	fake.SendAlert(stall)
+	fake.SendAlert(monitor.Alert{Rule: "custom", Severity: monitor.SeverityInfo, Status: monitor.AlertFiring})
+	stall.Status = monitor.AlertResolved
+	fake.SendAlert(stall)
+	drain(t, fake, s)
+
+	want := []call{{"trigger", "node1/mainnet/no-new-block"}, {"resolve", "node1/mainnet/no-new-block"}}
+	if len(p.calls) != len(want) || p.calls[0] != want[0] || p.calls[1] != want[1] {
+		t.Errorf("unexpected calls. got = %v, want = %v", p.calls, want)
+	}
+}
+
+func TestSink_Info(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(10, 1)
+	p := new(fakeProvider)
+	conf := DefaultConfig("node1")
+	conf.MinSeverity = monitor.SeverityInfo
+	s := NewSink(p, fake, conf)
+	defer s.Stop()
+
+	fake.SendAlert(monitor.Alert{Rule: "balance-above", Severity: monitor.SeverityInfo, Status: monitor.AlertFiring})
+	drain(t, fake, s)
+
+	if len(p.calls) != 1 || p.calls[0] != (call{"trigger", "node1/balance-above"}) {
+		t.Errorf("info alert did not open an incident: %v", p.calls)
+	}
+}
+
+func TestPagerDuty(t *testing.T) {
+	var events []pagerDutyEvent
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var e pagerDutyEvent
+		json.NewDecoder(r.Body).Decode(&e)
+		events = append(events, e)
+		rw.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	pd := &PagerDuty{RoutingKey: "key", URL: server.URL}
+	a := monitor.Alert{Rule: "no-new-block", Severity: monitor.SeverityCritical, Message: "stalled"}
+	if err := pd.Trigger(context.Background(), "k", a); err != nil {
+		t.Fatal(err)
+	}
+	if err := pd.Resolve(context.Background(), "k", a); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if e := events[0]; e.EventAction != "trigger" || e.DedupKey != "k" || e.RoutingKey != "key" || e.Payload.Severity != "critical" || e.Payload.Summary != "no-new-block: stalled" {
+		t.Errorf("unexpected trigger %+v", e)
+	}
+	if e := events[1]; e.EventAction != "resolve" || e.DedupKey != "k" || e.Payload != nil {
+		t.Errorf("unexpected resolve %+v", e)
+	}
+}
+
+func TestOpsgenie(t *testing.T) {
+	var paths []string
+	var created map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "GenieKey secret" {
+			http.Error(rw, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		paths = append(paths, r.URL.RequestURI())
+		if created == nil {
+			json.NewDecoder(r.Body).Decode(&created)
+		}
+		rw.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	og := &Opsgenie{APIKey: "secret", URL: server.URL}
+	a := monitor.Alert{Rule: "dbheight-lag", Severity: monitor.SeverityWarning, Message: "lagging", Height: 5}
+	if err := og.Trigger(context.Background(), "node1/dbheight-lag", a); err != nil {
+		t.Fatal(err)
+	}
+	if err := og.Resolve(context.Background(), "node1/dbheight-lag", a); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(paths) != 2 || paths[0] != "/v2/alerts" || paths[1] != "/v2/alerts/node1%2Fdbheight-lag/close?identifierType=alias" {
+		t.Errorf("unexpected requests: %v", paths)
+	}
+	if created["priority"] != "P3" || created["alias"] != "node1/dbheight-lag" || created["details"].(map[string]interface{})["height"] != "5" {
+		t.Errorf("unexpected alert: %v", created)
+	}
+
+	og.APIKey = "wrong"
+	if err := og.Trigger(context.Background(), "k", a); err == nil {
+		t.Error("no error for rejected request")
+	}
+}
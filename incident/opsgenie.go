@@ -0,0 +1,78 @@
+package incident
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+// OpsgenieURL is the endpoint of the Opsgenie Alert API. Accounts in the EU use
+// "https://api.eu.opsgenie.com".
+const OpsgenieURL = "https://api.opsgenie.com"
+
+// Opsgenie creates and closes alerts via the Opsgenie Alert API
+type Opsgenie struct {
+	// The key of an API integration
+	APIKey string
+	// The url of the API, defaults to OpsgenieURL
+	URL string
+	// The client used for requests, defaults to http.DefaultClient
+	Client *http.Client
+}
+
+var _ Provider = (*Opsgenie)(nil)
+
+// Trigger creates an alert, using the key as the alias so duplicates are merged
+func (og *Opsgenie) Trigger(ctx context.Context, key string, a monitor.Alert) error {
+	priority := "P5"
+	switch a.Severity {
+	case monitor.SeverityCritical:
+		priority = "P1"
+	case monitor.SeverityWarning:
+		priority = "P3"
+	}
+
+	d := make(map[string]string)
+	for k, v := range details(a) {
+		d[k] = fmt.Sprint(v) // opsgenie only accepts string details
+	}
+	body := map[string]interface{}{
+		"message":     truncate(a.Rule+": "+a.Message, 130),
+		"alias":       key,
+		"description": a.Message,
+		"priority":    priority,
+		"source":      key,
+		"details":     d,
+	}
+	return post(ctx, og.Client, og.url()+"/v2/alerts", og.header(), body)
+}
+
+// Resolve closes the alert with the key as alias
+func (og *Opsgenie) Resolve(ctx context.Context, key string, a monitor.Alert) error {
+	u := og.url() + "/v2/alerts/" + url.PathEscape(key) + "/close?identifierType=alias"
+	return post(ctx, og.Client, u, og.header(), map[string]string{"source": key})
+}
+
+func (og *Opsgenie) url() string {
+	if og.URL == "" {
+		return OpsgenieURL
+	}
+	return strings.TrimSuffix(og.URL, "/")
+}
+
+func (og *Opsgenie) header() http.Header {
+	return http.Header{"Authorization": {"GenieKey " + og.APIKey}}
+}
+
+// truncate shortens s to at most n characters
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
@@ -0,0 +1,79 @@
+package incident
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+// PagerDutyURL is the endpoint of the PagerDuty Events API v2
+const PagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDuty sends events to a service's Events API v2 integration
+type PagerDuty struct {
+	// The integration key of the service
+	RoutingKey string
+	// The url of the Events API, defaults to PagerDutyURL
+	URL string
+	// The client used for requests, defaults to http.DefaultClient
+	Client *http.Client
+}
+
+var _ Provider = (*PagerDuty)(nil)
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string                 `json:"summary"`
+	Source        string                 `json:"source"`
+	Severity      string                 `json:"severity"`
+	Timestamp     string                 `json:"timestamp"`
+	CustomDetails map[string]interface{} `json:"custom_details"`
+}
+
+// Trigger opens an incident, or adds to the open incident with the same key
+func (pd *PagerDuty) Trigger(ctx context.Context, key string, a monitor.Alert) error {
+	severity := "info"
+	switch a.Severity {
+	case monitor.SeverityCritical:
+		severity = "critical"
+	case monitor.SeverityWarning:
+		severity = "warning"
+	}
+	return pd.send(ctx, pagerDutyEvent{
+		RoutingKey:  pd.RoutingKey,
+		EventAction: "trigger",
+		DedupKey:    key,
+		Payload: &pagerDutyPayload{
+			Summary:       a.Rule + ": " + a.Message,
+			Source:        key,
+			Severity:      severity,
+			Timestamp:     a.Time.UTC().Format(time.RFC3339),
+			CustomDetails: details(a),
+		},
+	})
+}
+
+// Resolve resolves the incident with the key
+func (pd *PagerDuty) Resolve(ctx context.Context, key string, a monitor.Alert) error {
+	return pd.send(ctx, pagerDutyEvent{
+		RoutingKey:  pd.RoutingKey,
+		EventAction: "resolve",
+		DedupKey:    key,
+	})
+}
+
+func (pd *PagerDuty) send(ctx context.Context, e pagerDutyEvent) error {
+	url := pd.URL
+	if url == "" {
+		url = PagerDutyURL
+	}
+	return post(ctx, pd.Client, url, nil, e)
+}
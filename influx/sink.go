@@ -0,0 +1,362 @@
+// Package influx writes block times, minute durations, request latencies, and error
+// counts to InfluxDB using the line protocol.
+//
+// Points are sent over HTTP to the write endpoint of InfluxDB 1.x or 2.x, or any other
+// database that accepts the line protocol, without a client library.
+package influx
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+// Source is the subset of a monitor used by the sink
+type Source interface {
+	NewMinuteListener() <-chan monitor.Event
+	NewMinuteTimingListener() <-chan monitor.MinuteTiming
+	NewErrorListener() <-chan error
+	LatencyHistogram() monitor.LatencyHistogram
+	RevealLatency() monitor.LatencyHistogram
+	ThroughputStats() monitor.ThroughputStats
+}
+
+// Config contains the settings of a sink
+type Config struct {
+	// The write endpoint, including the database or bucket. For example
+	// "http://localhost:8086/write?db=factom" for InfluxDB 1.x or
+	// "http://localhost:8086/api/v2/write?org=my-org&bucket=factom" for InfluxDB 2.x.
+	// Timestamps are written with nanosecond precision.
+	URL string
+	// The API token for InfluxDB 2.x, sent as "Authorization: Token <token>"
+	Token string
+	// Tags added to every point, ie the host name or the network
+	Tags map[string]string
+	// The time between writes. Latency and error counts are sampled once per write.
+	Interval time.Duration
+	// The maximum amount of points held while the database can't be reached.
+	// The oldest points are dropped first.
+	MaxPending int
+	// The client used for requests, defaults to http.DefaultClient
+	Client *http.Client
+}
+
+// DefaultConfig returns a config for the write endpoint with default settings
+func DefaultConfig(url string) Config {
+	return Config{
+		URL:        url,
+		Interval:   time.Second * 10,
+		MaxPending: 10000,
+	}
+}
+
+// Timeout specifies the maximum time a single write can take
+var Timeout = time.Second * 10
+
+// Point is a single line protocol point
+type Point struct {
+	Measurement string
+	Tags        map[string]string
+	// Field values can be int64, float64, bool, or string
+	Fields map[string]interface{}
+	Time   time.Time
+}
+
+// String formats the point in the line protocol. Tags and fields are sorted by key.
+func (p Point) String() string {
+	var sb strings.Builder
+	sb.WriteString(escape(p.Measurement, ", "))
+	for _, k := range sortedKeys(p.Tags) {
+		sb.WriteString("," + escape(k, ",= ") + "=" + escape(p.Tags[k], ",= "))
+	}
+
+	fields := make([]string, 0, len(p.Fields))
+	for k := range p.Fields {
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+	for i, k := range fields {
+		if i == 0 {
+			sb.WriteByte(' ')
+		} else {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(escape(k, ",= ") + "=" + formatField(p.Fields[k]))
+	}
+
+	sb.WriteString(" " + strconv.FormatInt(p.Time.UnixNano(), 10))
+	return sb.String()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// escape adds a backslash before every character in chars
+func escape(s, chars string) string {
+	if !strings.ContainsAny(s, chars) {
+		return s
+	}
+	var sb strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(chars, r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func formatField(v interface{}) string {
+	switch v := v.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10) + "i"
+	case int:
+		return strconv.Itoa(v) + "i"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case string:
+		return `"` + strings.Replace(strings.Replace(v, `\`, `\\`, -1), `"`, `\"`, -1) + `"`
+	default:
+		return `"` + fmt.Sprint(v) + `"`
+	}
+}
+
+// Sink collects points from a monitor and writes them to InfluxDB:
+//
+//	factom_block   height, duration (seconds) for every block observed in full
+//	factom_minute  height, minute, duration, expected (seconds) for every minute observed in full
+//	factom_poll    latency_mean, latency_p50, latency_p95, latency_p99, latency_max (seconds),
+//	               errors (count since the last write), and reveal_latency_p50, reveal_latency_p95
+//	               (seconds) once entries were tracked, and entries_per_block, chains_per_block,
+//	               entries_per_second once directory blocks were counted
+type Sink struct {
+	src  Source
+	conf Config
+
+	mtx     sync.Mutex
+	pending []Point
+	errs    int64
+
+	errors chan error
+
+	close  chan interface{}
+	done   chan interface{}
+	closer sync.Once
+}
+
+// NewSink creates a new sink that begins collecting points from the source immediately.
+// Starts a goroutine that can be stopped via sink.Stop().
+func NewSink(src Source, conf Config) *Sink {
+	if conf.Interval <= 0 {
+		conf.Interval = DefaultConfig("").Interval
+	}
+	if conf.MaxPending < 1 {
+		conf.MaxPending = DefaultConfig("").MaxPending
+	}
+	if conf.Client == nil {
+		conf.Client = http.DefaultClient
+	}
+	s := new(Sink)
+	s.src = src
+	s.conf = conf
+	s.errors = make(chan error, 6)
+	s.close = make(chan interface{})
+	s.done = make(chan interface{})
+	go s.run(src.NewMinuteListener(), src.NewMinuteTimingListener(), src.NewErrorListener())
+	return s
+}
+
+// Errors returns a channel that receives errors from failed writes.
+// Errors are dropped if the channel is not read.
+func (s *Sink) Errors() <-chan error {
+	return s.errors
+}
+
+func (s *Sink) run(minutes <-chan monitor.Event, timings <-chan monitor.MinuteTiming, errs <-chan error) {
+	defer close(s.done)
+	ticker := time.NewTicker(s.conf.Interval)
+	defer ticker.Stop()
+
+	var prev monitor.Event
+	for {
+		select {
+		case <-s.close:
+			s.flush(time.Now())
+			return
+		case e, ok := <-minutes:
+			if !ok { // drained
+				minutes = nil
+				continue
+			}
+			if e.Minute == 0 && prev.Minute == 0 && e.Height == prev.Height+1 && !prev.BlockStart.IsZero() && !e.BlockStart.IsZero() {
+				s.add(Point{
+					Measurement: "factom_block",
+					Fields: map[string]interface{}{
+						"height":   prev.Height,
+						"duration": e.BlockStart.Sub(prev.BlockStart).Seconds(),
+					},
+					Time: prev.BlockStart,
+				})
+			}
+			if e.Minute == 0 {
+				prev = e
+			}
+		case t, ok := <-timings:
+			if !ok {
+				timings = nil
+				continue
+			}
+			s.add(Point{
+				Measurement: "factom_minute",
+				Fields: map[string]interface{}{
+					"height":   t.Height,
+					"minute":   t.Minute,
+					"duration": t.Duration.Seconds(),
+					"expected": t.Expected.Seconds(),
+				},
+				Time: time.Now(),
+			})
+		case _, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			s.mtx.Lock()
+			s.errs++
+			s.mtx.Unlock()
+		case now := <-ticker.C:
+			s.flush(now)
+		}
+	}
+}
+
+// add queues a point with the configured tags, dropping the oldest point if the queue is full
+func (s *Sink) add(p Point) {
+	tags := make(map[string]string, len(s.conf.Tags)+len(p.Tags))
+	for k, v := range s.conf.Tags {
+		tags[k] = v
+	}
+	for k, v := range p.Tags {
+		tags[k] = v
+	}
+	p.Tags = tags
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if len(s.pending) >= s.conf.MaxPending {
+		s.pending = s.pending[1:]
+	}
+	s.pending = append(s.pending, p)
+}
+
+// flush samples the poll statistics and writes all pending points.
+// Points are kept for the next flush if the write fails.
+func (s *Sink) flush(now time.Time) {
+	lat := s.src.LatencyHistogram()
+	s.mtx.Lock()
+	errs := s.errs
+	s.errs = 0
+	s.mtx.Unlock()
+
+	poll := Point{
+		Measurement: "factom_poll",
+		Fields: map[string]interface{}{
+			"latency_mean": lat.Mean.Seconds(),
+			"latency_p50":  lat.P50.Seconds(),
+			"latency_p95":  lat.P95.Seconds(),
+			"latency_p99":  lat.P99.Seconds(),
+			"latency_max":  lat.Max.Seconds(),
+			"errors":       errs,
+		},
+		Time: now,
+	}
+	if rev := s.src.RevealLatency(); rev.Count > 0 {
+		poll.Fields["reveal_latency_p50"] = rev.P50.Seconds()
+		poll.Fields["reveal_latency_p95"] = rev.P95.Seconds()
+	}
+	if tp := s.src.ThroughputStats(); tp.Blocks > 0 {
+		poll.Fields["entries_per_block"] = tp.EntriesPerBlock
+		poll.Fields["chains_per_block"] = tp.ChainsPerBlock
+		poll.Fields["entries_per_second"] = tp.EntriesPerSecond
+	}
+	s.add(poll)
+
+	s.mtx.Lock()
+	points := s.pending
+	s.pending = nil
+	s.mtx.Unlock()
+
+	if err := s.write(points); err != nil {
+		s.notifyError(err)
+		s.mtx.Lock()
+		s.pending = append(points, s.pending...)
+		if over := len(s.pending) - s.conf.MaxPending; over > 0 {
+			s.pending = s.pending[over:]
+		}
+		s.mtx.Unlock()
+	}
+}
+
+func (s *Sink) write(points []Point) error {
+	var body bytes.Buffer
+	for _, p := range points {
+		body.WriteString(p.String())
+		body.WriteByte('\n')
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodPost, s.conf.URL, &body)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if s.conf.Token != "" {
+		req.Header.Set("Authorization", "Token "+s.conf.Token)
+	}
+
+	resp, err := s.conf.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("influx: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+func (s *Sink) notifyError(err error) {
+	select {
+	case s.errors <- err:
+	default:
+	}
+}
+
+// Stop halts the sink after one last attempt to write the pending points
+func (s *Sink) Stop() {
+	s.closer.Do(func() {
+		close(s.close)
+		<-s.done
+	})
+}
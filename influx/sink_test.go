@@ -0,0 +1,117 @@
+package influx
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+	"github.com/WhoSoup/factom-monitor/monitortest"
+)
+
+func TestPoint_String(t *testing.T) {
+	p := Point{
+		Measurement: "factom block",
+		Tags:        map[string]string{"node": "a,b", "network": "mainnet"},
+		Fields:      map[string]interface{}{"height": int64(10), "duration": 600.5, "note": `say "hi"`, "ok": true},
+		Time:        time.Unix(1600000000, 0),
+	}
+	want := `factom\ block,network=mainnet,node=a\,b duration=600.5,height=10i,note="say \"hi\"",ok=true 1600000000000000000`
+	if got := p.String(); got != want {
+		t.Errorf("unexpected line.\n got = %s\nwant = %s", got, want)
+	}
+}
+
+func TestSink(t *testing.T) {
+	var mtx sync.Mutex
+	var bodies []string
+	fail := true
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		if r.Header.Get("Authorization") != "Token secret" {
+			t.Errorf("missing token: %v", r.Header)
+		}
+		if fail {
+			fail = false
+			http.Error(rw, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	fake := monitortest.NewFakeMonitor(10, 0)
+	ms := time.Millisecond * 250
+	fake.SetLatencyHistogram(monitor.LatencyHistogram{Count: 1, Mean: ms, Max: ms, P50: ms, P95: ms, P99: ms})
+	fake.SetRevealLatency(monitor.LatencyHistogram{Count: 2, P50: time.Second * 4, P95: time.Second * 9})
+	fake.SetThroughputStats(monitor.ThroughputStats{Blocks: 2, EntriesPerBlock: 4, ChainsPerBlock: 1.5, EntriesPerSecond: 0.4})
+	conf := DefaultConfig(server.URL + "/write?db=factom")
+	conf.Token = "secret"
+	conf.Tags = map[string]string{"network": "mainnet"}
+	conf.Interval = time.Hour
+	s := NewSink(fake, conf)
+
+	// the listeners are independent, so wait for each to be processed to
+	// keep the points in order
+	wait := func(pending int, errs int64) {
+		for i := 0; i < 100; i++ {
+			s.mtx.Lock()
+			done := len(s.pending) == pending && s.errs == errs
+			s.mtx.Unlock()
+			if done {
+				return
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+		t.Fatalf("timed out waiting for %d points and %d errors", pending, errs)
+	}
+
+	start := time.Unix(1600000000, 0)
+	fake.SendEvent(monitor.Event{Height: 10, BlockStart: start})
+	fake.SendEvent(monitor.Event{Height: 10, Minute: 5, BlockStart: start})
+	fake.SendEvent(monitor.Event{Height: 11, BlockStart: start.Add(time.Second * 610)})
+	wait(1, 0)
+	fake.SendMinuteTiming(monitor.MinuteTiming{Height: 11, Minute: 0, Duration: time.Minute, Expected: time.Minute})
+	wait(2, 0)
+	fake.SendError(errors.New("timeout"))
+	wait(2, 1)
+
+	// first write fails and is kept for the next
+	s.flush(time.Unix(1600001000, 0))
+	select {
+	case <-s.Errors():
+	case <-time.After(time.Second):
+		t.Fatal("failed write not reported")
+	}
+	s.Stop()
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	if len(bodies) != 1 {
+		t.Fatalf("unexpected writes: %v", bodies)
+	}
+	lines := strings.Split(strings.TrimSpace(bodies[0]), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("unexpected lines: %v", lines)
+	}
+	if want := "factom_block,network=mainnet duration=610,height=10i 1600000000000000000"; lines[0] != want {
+		t.Errorf("unexpected block point. got = %s, want = %s", lines[0], want)
+	}
+	if !strings.HasPrefix(lines[1], "factom_minute,network=mainnet duration=60,expected=60,height=11i,minute=0i ") {
+		t.Errorf("unexpected minute point: %s", lines[1])
+	}
+	if want := "factom_poll,network=mainnet chains_per_block=1.5,entries_per_block=4,entries_per_second=0.4,errors=1i,latency_max=0.25,latency_mean=0.25,latency_p50=0.25,latency_p95=0.25,latency_p99=0.25,reveal_latency_p50=4,reveal_latency_p95=9 1600001000000000000"; lines[2] != want {
+		t.Errorf("unexpected poll point. got = %s, want = %s", lines[2], want)
+	}
+	if !strings.HasPrefix(lines[3], "factom_poll,network=mainnet chains_per_block=1.5,entries_per_block=4,entries_per_second=0.4,errors=0i,") {
+		t.Errorf("unexpected final poll point: %s", lines[3])
+	}
+}
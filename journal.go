@@ -0,0 +1,108 @@
+package monitor
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+)
+
+// ErrNoJournal is returned when replaying events from a monitor without a journal
+var ErrNoJournal = errors.New("monitor has no journal")
+
+// Journal is an append-only record of all events sent to minute listeners.
+type Journal interface {
+	// Append adds the event to the end of the journal
+	Append(e Event) error
+	// Read returns all events with a height between from and to (inclusive)
+	// in the order they were appended
+	Read(from, to int64) ([]Event, error)
+}
+
+// FileJournal is a Journal that appends events as lines of JSON to a file.
+type FileJournal struct {
+	mtx  sync.Mutex
+	path string
+}
+
+var _ Journal = (*FileJournal)(nil)
+
+// NewFileJournal creates a journal that appends events to the specified path.
+// The file is created on the first append.
+func NewFileJournal(path string) *FileJournal {
+	fj := new(FileJournal)
+	fj.path = path
+	return fj
+}
+
+// Append writes the event to the end of the file
+func (fj *FileJournal) Append(e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	fj.mtx.Lock()
+	defer fj.mtx.Unlock()
+
+	f, err := os.OpenFile(fj.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Read scans the file for events in the given height range
+func (fj *FileJournal) Read(from, to int64) ([]Event, error) {
+	fj.mtx.Lock()
+	defer fj.mtx.Unlock()
+
+	f, err := os.Open(fj.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, err
+		}
+		if e.Height >= from && e.Height <= to {
+			events = append(events, e)
+		}
+	}
+	return events, scanner.Err()
+}
+
+// Replay re-delivers all journaled events with a height between from and to (inclusive).
+// The returned channel contains the events in their original order and is closed
+// after the last one.
+// If the monitor has no journal, ErrNoJournal is returned.
+func (m *Monitor) Replay(from, to int64) (<-chan Event, error) {
+	if m.conf.Journal == nil {
+		return nil, ErrNoJournal
+	}
+
+	events, err := m.conf.Journal.Read(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	l := make(chan Event, len(events))
+	for _, e := range events {
+		l <- e
+	}
+	close(l)
+	return l, nil
+}
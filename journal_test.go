@@ -0,0 +1,60 @@
+package monitor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFileJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "factom-monitor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fj := NewFileJournal(filepath.Join(dir, "journal.ndjson"))
+
+	events, err := fj.Read(0, 100)
+	if err != nil || len(events) != 0 {
+		t.Fatalf("unexpected result for empty journal. got = (%v, %v)", events, err)
+	}
+
+	var all []Event
+	for h := int64(1); h <= 3; h++ {
+		for min := int64(0); min < 10; min++ {
+			e := Event{Height: h, DBHeight: h, Minute: min}
+			all = append(all, e)
+			if err := fj.Append(e); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	events, err = fj.Read(2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(events, all[10:20]) {
+		t.Errorf("unexpected events. got = %v, want = %v", events, all[10:20])
+	}
+
+	m := &Monitor{conf: Config{Journal: fj}}
+	replay, err := m.Replay(2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var replayed []Event
+	for e := range replay {
+		replayed = append(replayed, e)
+	}
+	if !reflect.DeepEqual(replayed, all[10:]) {
+		t.Errorf("unexpected replay. got = %v, want = %v", replayed, all[10:])
+	}
+
+	if _, err := new(Monitor).Replay(0, 1); err != ErrNoJournal {
+		t.Errorf("unexpected error without journal. got = %v, want = %v", err, ErrNoJournal)
+	}
+}
@@ -0,0 +1,255 @@
+// Package kafka writes monitor events to a Kafka topic.
+//
+// The package does not depend on a Kafka client library. Any client can be
+// used by wrapping it in a Producer, for example a segmentio/kafka-go Writer
+// or a sarama SyncProducer.
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+// Message is a single Kafka record
+type Message struct {
+	Key   []byte
+	Value []byte
+	Time  time.Time
+}
+
+// Producer writes a batch of messages to a topic.
+// It must only return nil if every message in the batch was acknowledged by the broker.
+type Producer interface {
+	Produce(ctx context.Context, topic string, msgs []Message) error
+}
+
+// Source is the subset of a monitor used by the sink
+type Source interface {
+	NewMinuteListener() <-chan monitor.Event
+}
+
+// Config contains the settings of a sink
+type Config struct {
+	// The topic to write events to
+	Topic string
+	// The maximum amount of events written in one batch
+	BatchSize int
+	// The maximum time an event is held before the batch is written.
+	// Defaults to DefaultConfig's if not set.
+	BatchTimeout time.Duration
+	// The time to wait before retrying a failed batch. Defaults to DefaultConfig's
+	// if not set.
+	RetryDelay time.Duration
+	// Retry decides when a failed batch is retried. If it gives up, the batch is
+	// dropped. Defaults to retrying every RetryDelay until the sink is stopped.
+	Retry monitor.RetryPolicy
+	// Marshal encodes the value of a message. Defaults to JSON; pb.MarshalEvent
+	// writes protocol buffers instead.
+	Marshal func(monitor.Event) []byte
+	// Leader, if set, limits writing to the times it is the leader, so only one of
+	// several monitor instances writes events. Events that arrive while it isn't
+	// are discarded. See monitor.Elector.
+	Leader monitor.Leader
+}
+
+// DefaultConfig returns a config for the given topic with default batch settings
+func DefaultConfig(topic string) Config {
+	return Config{
+		Topic:        topic,
+		BatchSize:    100,
+		BatchTimeout: time.Second,
+		RetryDelay:   time.Second,
+	}
+}
+
+// Sink writes minute events to Kafka, keyed by height so all events of one
+// block end up in the same partition.
+// Delivery is at-least-once: a failed batch is retried until it succeeds or the
+// sink is stopped.
+type Sink struct {
+	producer Producer
+	conf     Config
+
+	errors chan error
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan interface{}
+	closer sync.Once
+}
+
+var _ monitor.Sink = (*Sink)(nil)
+
+// NewSink creates a new sink that begins writing events from the source immediately.
+// Starts a goroutine that can be stopped via sink.Stop().
+// If src is nil, the sink only writes the events passed to HandleEvent, ie by a
+// monitor.Dispatcher. They are written one at a time and retried by the dispatcher.
+func NewSink(producer Producer, src Source, conf Config) *Sink {
+	if conf.BatchSize < 1 {
+		conf.BatchSize = 1
+	}
+	if conf.BatchTimeout <= 0 {
+		conf.BatchTimeout = DefaultConfig("").BatchTimeout
+	}
+	if conf.RetryDelay <= 0 {
+		conf.RetryDelay = DefaultConfig("").RetryDelay
+	}
+	if conf.Retry == nil {
+		conf.Retry = monitor.ConstantRetry(conf.RetryDelay, 0)
+	}
+	s := new(Sink)
+	s.producer = producer
+	s.conf = conf
+	s.errors = make(chan error, 6)
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.done = make(chan interface{})
+	if src == nil {
+		close(s.done)
+		return s
+	}
+	go s.run(src.NewMinuteListener())
+	return s
+}
+
+// Errors returns a channel that receives errors from failed batches.
+// Errors are dropped if the channel is not read.
+func (s *Sink) Errors() <-chan error {
+	return s.errors
+}
+
+// NewMessage converts an event to a message with a JSON value
+func NewMessage(e monitor.Event) (Message, error) {
+	js, err := json.Marshal(e)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{
+		Key:   []byte(strconv.FormatInt(e.Height, 10)),
+		Value: js,
+		Time:  time.Now(),
+	}, nil
+}
+
+// HandleEvent writes a single event as a batch of one message. It implements
+// monitor.Sink, so a dispatcher can retry events that were not acknowledged.
+func (s *Sink) HandleEvent(ctx context.Context, e monitor.Event) error {
+	if s.follower() {
+		return nil
+	}
+	msg, err := s.message(e)
+	if err != nil {
+		return err
+	}
+	return s.producer.Produce(ctx, s.conf.Topic, []Message{msg})
+}
+
+// follower returns true while the sink isn't the leader
+func (s *Sink) follower() bool {
+	return s.conf.Leader != nil && !s.conf.Leader.IsLeader()
+}
+
+// message converts an event to a message using Config.Marshal
+func (s *Sink) message(e monitor.Event) (Message, error) {
+	if s.conf.Marshal == nil {
+		return NewMessage(e)
+	}
+	return Message{
+		Key:   []byte(strconv.FormatInt(e.Height, 10)),
+		Value: s.conf.Marshal(e),
+		Time:  time.Now(),
+	}, nil
+}
+
+func (s *Sink) run(l <-chan monitor.Event) {
+	defer close(s.done)
+
+	var batch []Message
+	timer := time.NewTimer(s.conf.BatchTimeout)
+	timer.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			// one last attempt for anything that is still pending
+			if len(batch) > 0 {
+				ctx, cancel := context.WithTimeout(context.Background(), s.conf.BatchTimeout)
+				if err := s.producer.Produce(ctx, s.conf.Topic, batch); err != nil {
+					s.notifyError(err)
+				}
+				cancel()
+			}
+			return
+		case e, ok := <-l:
+			if !ok { // drained, write the pending batch
+				timer.Stop()
+				if len(batch) > 0 {
+					s.flush(batch)
+				}
+				return
+			}
+			if s.follower() {
+				continue
+			}
+			msg, err := s.message(e)
+			if err != nil {
+				s.notifyError(err)
+				continue
+			}
+			if len(batch) == 0 {
+				timer.Reset(s.conf.BatchTimeout)
+			}
+			batch = append(batch, msg)
+			if len(batch) < s.conf.BatchSize {
+				continue
+			}
+			timer.Stop()
+		case <-timer.C:
+		}
+
+		if s.flush(batch) {
+			batch = nil
+		}
+	}
+}
+
+// flush retries the batch until it is written or the retry policy gives up.
+// returns false if the sink was stopped first.
+func (s *Sink) flush(batch []Message) bool {
+	for attempt := 1; ; attempt++ {
+		err := s.producer.Produce(s.ctx, s.conf.Topic, batch)
+		if err == nil {
+			return true
+		}
+		s.notifyError(err)
+		delay, retry := s.conf.Retry.NextDelay(attempt, err)
+		if !retry {
+			return true
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return false
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (s *Sink) notifyError(err error) {
+	select {
+	case s.errors <- err:
+	default:
+	}
+}
+
+// Stop halts the sink and waits for the pending batch to be written or dropped.
+func (s *Sink) Stop() {
+	s.closer.Do(func() {
+		s.cancel()
+		<-s.done
+	})
+}
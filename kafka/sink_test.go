@@ -0,0 +1,202 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+	"github.com/WhoSoup/factom-monitor/monitortest"
+	"github.com/WhoSoup/factom-monitor/pb"
+)
+
+type fakeProducer struct {
+	mtx      sync.Mutex
+	batches  [][]Message
+	failures int
+}
+
+func (p *fakeProducer) Produce(ctx context.Context, topic string, msgs []Message) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("broker unavailable")
+	}
+	p.batches = append(p.batches, msgs)
+	return nil
+}
+
+func (p *fakeProducer) wait(n int) [][]Message {
+	for i := 0; i < 100; i++ {
+		p.mtx.Lock()
+		b := p.batches
+		p.mtx.Unlock()
+		if len(b) >= n {
+			return b
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	return nil
+}
+
+func TestSink_BatchSize(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(0, 6)
+	p := new(fakeProducer)
+	conf := DefaultConfig("factom")
+	conf.BatchSize = 3
+	conf.BatchTimeout = time.Hour
+	s := NewSink(p, fake, conf)
+	defer s.Stop()
+
+	for i := 0; i < 6; i++ { // 0/7 to 1/2
+		fake.AdvanceMinute()
+	}
+
+	b := p.wait(2)
+	if len(b) != 2 || len(b[0]) != 3 || len(b[1]) != 3 {
+		t.Fatalf("unexpected batches: %v", b)
+	}
+	if string(b[1][0].Key) != "1" {
+		t.Errorf("unexpected key. got = %s, want = 1", b[1][0].Key)
+	}
+}
+
+func TestSink_Marshal(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(4, 1)
+	p := new(fakeProducer)
+	conf := DefaultConfig("factom")
+	conf.BatchSize = 1
+	conf.Marshal = pb.MarshalEvent
+	s := NewSink(p, fake, conf)
+	defer s.Stop()
+
+	fake.AdvanceMinute()
+
+	b := p.wait(1)
+	if len(b) != 1 {
+		t.Fatalf("unexpected batches: %v", b)
+	}
+	if e, err := pb.UnmarshalEvent(b[0][0].Value); err != nil || e.Height != 4 || e.Minute != 2 {
+		t.Errorf("unexpected value. got = (%+v, %v)", e, err)
+	}
+}
+
+func TestSink_BatchTimeout(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(1, 0)
+	p := new(fakeProducer)
+	conf := DefaultConfig("factom")
+	conf.BatchTimeout = time.Millisecond * 50
+	s := NewSink(p, fake, conf)
+	defer s.Stop()
+
+	fake.AdvanceMinute()
+
+	if b := p.wait(1); len(b) != 1 || len(b[0]) != 1 {
+		t.Errorf("batch not flushed after timeout: %v", b)
+	}
+}
+
+func TestSink_Defaults(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(1, 0)
+	p := new(fakeProducer)
+	s := NewSink(p, fake, Config{Topic: "factom", BatchSize: 1})
+	defer s.Stop()
+
+	def := DefaultConfig("")
+	if s.conf.BatchTimeout != def.BatchTimeout || s.conf.RetryDelay != def.RetryDelay {
+		t.Errorf("unexpected defaults. got = (%s, %s), want = (%s, %s)", s.conf.BatchTimeout, s.conf.RetryDelay, def.BatchTimeout, def.RetryDelay)
+	}
+
+	fake.AdvanceMinute()
+
+	if b := p.wait(1); len(b) != 1 {
+		t.Errorf("batch not written: %v", b)
+	}
+}
+
+func TestSink_Retry(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(1, 0)
+	p := &fakeProducer{failures: 2}
+	conf := DefaultConfig("factom")
+	conf.BatchSize = 1
+	conf.RetryDelay = time.Millisecond * 10
+	s := NewSink(p, fake, conf)
+	defer s.Stop()
+
+	fake.AdvanceMinute()
+
+	if b := p.wait(1); len(b) != 1 {
+		t.Errorf("batch was not retried: %v", b)
+	}
+}
+
+func TestSink_Drained(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(1, 0)
+	p := new(fakeProducer)
+	conf := DefaultConfig("factom")
+	conf.BatchTimeout = time.Hour
+	s := NewSink(p, fake, conf)
+	defer s.Stop()
+
+	fake.AdvanceMinute()
+	fake.Drain()
+
+	select {
+	case <-s.done:
+	case <-time.After(time.Second):
+		t.Fatal("sink did not exit after the source was drained")
+	}
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if len(p.batches) != 1 || len(p.batches[0]) != 1 {
+		t.Errorf("pending batch not written after drain: %v", p.batches)
+	}
+}
+
+func TestSink_Dispatcher(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(10, 1)
+	d := monitor.NewDispatcher(fake, monitor.DispatcherConfig{RetryDelay: time.Millisecond * 10})
+	defer d.Stop()
+	p := &fakeProducer{failures: 1}
+	s := NewSink(p, nil, DefaultConfig("factom"))
+	defer s.Stop()
+	if err := d.Add("kafka", s); err != nil {
+		t.Fatal(err)
+	}
+
+	fake.AdvanceMinute()
+	b := p.wait(1)
+	if len(b) != 1 || len(b[0]) != 1 || string(b[0][0].Key) != "10" {
+		t.Errorf("failed event not retried by the dispatcher: %v", b)
+	}
+}
+
+func TestSink_Follower(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(1, 0)
+	p := new(fakeProducer)
+	conf := DefaultConfig("factom")
+	conf.BatchSize = 1
+	conf.Leader = monitortest.NewFakeLeader(false)
+	s := NewSink(p, fake, conf)
+	defer s.Stop()
+
+	fake.AdvanceMinute()
+	if err := s.HandleEvent(context.Background(), monitor.Event{Height: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	fake.Drain()
+	select {
+	case <-s.done:
+	case <-time.After(time.Second):
+		t.Fatal("sink did not exit after the source was drained")
+	}
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if len(p.batches) > 0 {
+		t.Errorf("follower wrote %v", p.batches)
+	}
+}
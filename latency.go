@@ -0,0 +1,145 @@
+package monitor
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MinTimeout is the lower bound of adaptive timeouts
+var MinTimeout time.Duration = time.Millisecond * 250
+
+// latencySamples is the number of recent request latencies that are kept
+const latencySamples = 100
+
+// minLatencySamples is the number of samples required before timeouts adapt
+const minLatencySamples = 10
+
+// latencies is a ring buffer of recent durations, such as request latencies or block times
+type latencies struct {
+	mtx     sync.Mutex
+	samples []time.Duration
+	next    int
+	full    bool
+}
+
+func newLatencies(size int) *latencies {
+	l := new(latencies)
+	l.samples = make([]time.Duration, size)
+	return l
+}
+
+func (l *latencies) add(d time.Duration) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.samples[l.next] = d
+	l.next = (l.next + 1) % len(l.samples)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+func (l *latencies) count() int {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.full {
+		return len(l.samples)
+	}
+	return l.next
+}
+
+// mean returns the average of the samples, zero if there are none
+func (l *latencies) mean() time.Duration {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	n := l.next
+	if l.full {
+		n = len(l.samples)
+	}
+	if n == 0 {
+		return 0
+	}
+	var sum time.Duration
+	for _, d := range l.samples[:n] {
+		sum += d
+	}
+	return sum / time.Duration(n)
+}
+
+// percentile returns the p-th percentile (0-100) of the samples.
+// returns false if there are no samples.
+func (l *latencies) percentile(p float64) (time.Duration, bool) {
+	l.mtx.Lock()
+	n := l.next
+	if l.full {
+		n = len(l.samples)
+	}
+	sorted := append([]time.Duration(nil), l.samples[:n]...)
+	l.mtx.Unlock()
+
+	if len(sorted) == 0 {
+		return 0, false
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	idx := int(p/100*float64(len(sorted)) + 0.5)
+	if idx > 0 {
+		idx-- // nearest rank
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx], true
+}
+
+// maxTimeout returns Config.Timeout, or Timeout if it is not set
+func (m *Monitor) maxTimeout() time.Duration {
+	if m.conf.Timeout > 0 {
+		return m.conf.Timeout
+	}
+	return Timeout
+}
+
+// timeout returns the timeout for the next request.
+// With Config.AdaptiveTimeout, this is the 99th percentile of recent latencies
+// multiplied by Config.TimeoutFactor, bounded by MinTimeout and Timeout.
+func (m *Monitor) timeout() time.Duration {
+	max := m.maxTimeout()
+	if !m.conf.AdaptiveTimeout || m.latencies.count() < minLatencySamples {
+		return max
+	}
+
+	p99, _ := m.latencies.percentile(99)
+	factor := m.conf.TimeoutFactor
+	if factor <= 0 {
+		factor = 3
+	}
+
+	t := time.Duration(float64(p99) * factor)
+	if t < MinTimeout {
+		t = MinTimeout
+	}
+	if t > max {
+		t = max
+	}
+	return t
+}
+
+// recordLatency adds the duration of a request to the samples.
+// Requests that hit their deadline are recorded with the full timeout, so timeouts
+// grow again when a node slows down.
+func (m *Monitor) recordLatency(ctx context.Context, start time.Time, err error) {
+	now := time.Now()
+	if err == nil {
+		m.latencies.add(now.Sub(start))
+		m.histogram.add(now, now.Sub(start))
+		return
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		if deadline, ok := ctx.Deadline(); ok {
+			m.latencies.add(deadline.Sub(start))
+			m.histogram.add(now, deadline.Sub(start))
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencies_percentile(t *testing.T) {
+	l := newLatencies(10)
+	if _, ok := l.percentile(50); ok {
+		t.Errorf("percentile of no samples")
+	}
+
+	// 15 samples, only the last 10 (6..15ms) are kept
+	for i := 1; i <= 15; i++ {
+		l.add(time.Duration(i) * time.Millisecond)
+	}
+
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{0, time.Millisecond * 6},
+		{50, time.Millisecond * 10},
+		{99, time.Millisecond * 15},
+		{100, time.Millisecond * 15},
+	}
+	for _, tt := range tests {
+		if got, _ := l.percentile(tt.p); got != tt.want {
+			t.Errorf("p%v: got = %v, want = %v", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestMonitor_timeout(t *testing.T) {
+	m := new(Monitor)
+	m.latencies = newLatencies(latencySamples)
+
+	for i := 0; i < minLatencySamples; i++ {
+		m.latencies.add(time.Millisecond * 200)
+	}
+	if got := m.timeout(); got != Timeout {
+		t.Errorf("timeout adapted without being enabled. got = %v, want = %v", got, Timeout)
+	}
+
+	m.conf.AdaptiveTimeout = true
+	if got := m.timeout(); got != time.Millisecond*600 {
+		t.Errorf("unexpected adaptive timeout. got = %v, want = 600ms", got)
+	}
+
+	m.conf.TimeoutFactor = 1
+	if got := m.timeout(); got != MinTimeout {
+		t.Errorf("timeout not bounded by MinTimeout. got = %v, want = %v", got, MinTimeout)
+	}
+
+	m.conf.TimeoutFactor = 1000
+	if got := m.timeout(); got != Timeout {
+		t.Errorf("timeout not bounded by Timeout. got = %v, want = %v", got, Timeout)
+	}
+}
@@ -0,0 +1,57 @@
+package monitor
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/AdamSLevy/jsonrpc2/v14"
+)
+
+// ErrDrained is returned when starting a monitor that was drained with StopAndDrain
+var ErrDrained = errors.New("monitor has been drained")
+
+// Done returns a channel that is closed once the monitor has stopped polling, either
+// because Stop was called or because the monitor stopped itself. See Err.
+// After the monitor is started again, Done returns a new channel.
+func (m *Monitor) Done() <-chan struct{} {
+	m.runMtx.Lock()
+	defer m.runMtx.Unlock()
+	return m.done
+}
+
+// Err returns the error that made the monitor stop itself, such as a url that doesn't
+// point to a factomd API or rejected credentials.
+// Returns nil while the monitor is running and if it was stopped via Stop.
+// The error is cleared when the monitor is started again.
+func (m *Monitor) Err() error {
+	m.heightMtx.Lock()
+	defer m.heightMtx.Unlock()
+	return m.err
+}
+
+// fail stops the monitor with a terminal error
+func (m *Monitor) fail(err error) {
+	m.heightMtx.Lock()
+	if m.err == nil {
+		m.err = err
+	}
+	m.heightMtx.Unlock()
+	m.Stop()
+}
+
+// fatal returns true for errors that will not go away by polling again
+func fatal(err error) bool {
+	var rpcErr jsonrpc2.Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Code == jsonrpc2.ErrorCodeMethodNotFound
+	}
+
+	var httpErr jsonrpc2.ErrorUnexpectedHTTPResponse
+	if errors.As(err, &httpErr) && httpErr.Response != nil {
+		switch httpErr.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AdamSLevy/jsonrpc2/v14"
+)
+
+func TestMonitor_Done(t *testing.T) {
+	s := newTestServer("localhost:9852", 10, 0, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitorWithConfig("http://localhost:9852/v2", Config{Interval: time.Millisecond * 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	select {
+	case <-m.Done():
+		t.Fatal("monitor done while running")
+	case <-time.After(time.Millisecond * 200):
+	}
+
+	// the node stops serving the api, which can't be fixed by polling again
+	s.mtx.Lock()
+	delete(s.methods, "current-minute")
+	s.mtx.Unlock()
+
+	select {
+	case <-m.Done():
+	case <-time.After(time.Second * 2):
+		t.Fatal("monitor did not stop itself")
+	}
+
+	var rpcErr jsonrpc2.Error
+	if err := m.Err(); !errors.As(err, &rpcErr) || rpcErr.Code != jsonrpc2.ErrorCodeMethodNotFound {
+		t.Errorf("unexpected terminal error %v", err)
+	}
+}
+
+func TestMonitor_Err_Stop(t *testing.T) {
+	s := newTestServer("localhost:9851", 10, 0, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitor("http://localhost:9851/v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Stop()
+
+	select {
+	case <-m.Done():
+	case <-time.After(time.Second):
+		t.Fatal("done not closed after stop")
+	}
+	if err := m.Err(); err != nil {
+		t.Errorf("unexpected error after stop: %v", err)
+	}
+}
+
+func Test_fatal(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"method not found", jsonrpc2.Error{Code: jsonrpc2.ErrorCodeMethodNotFound}, true},
+		{"internal error", jsonrpc2.Error{Code: jsonrpc2.ErrorCodeInternal}, false},
+		{"unauthorized", jsonrpc2.ErrorUnexpectedHTTPResponse{UnmarshlingErr: errors.New("eof"), Response: &http.Response{StatusCode: http.StatusUnauthorized}}, true},
+		{"bad gateway", jsonrpc2.ErrorUnexpectedHTTPResponse{UnmarshlingErr: errors.New("eof"), Response: &http.Response{StatusCode: http.StatusBadGateway}}, false},
+		{"network", errors.New("connection refused"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fatal(tt.err); got != tt.want {
+				t.Errorf("fatal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonitor_Restart(t *testing.T) {
+	s := newTestServer("localhost:9850", 10, 0, time.Second*10, t)
+	defer s.stop()
+
+	m, err := New("http://localhost:9850/v2", Config{Interval: time.Millisecond * 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener := m.NewMinuteListener()
+	watched := make(chan int64, 10)
+	m.everyMinute(func(e Event) { watched <- e.Minute })
+
+	for run := 0; run < 2; run++ {
+		if err := m.Start(); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.Start(); err != nil { // no effect
+			t.Fatal(err)
+		}
+
+		if run > 0 {
+			// the node moved to minute 2 while stopped
+			select {
+			case e := <-listener:
+				if e.Minute != 2 {
+					t.Errorf("got catch-up minute %d, want 2", e.Minute)
+				}
+				<-watched
+			case <-time.After(time.Second):
+				t.Fatal("catch-up event not received")
+			}
+		}
+
+		s.tick()
+		want := int64(1 + run*2)
+		select {
+		case e := <-listener:
+			if e.Minute != want {
+				t.Errorf("run %d: got minute %d, want %d", run, e.Minute, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("run %d: event not received", run)
+		}
+		select {
+		case <-watched:
+		case <-time.After(time.Second):
+			t.Fatalf("run %d: watcher not running", run)
+		}
+
+		m.Stop()
+		<-m.Done()
+		s.tick()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := m.StopAndDrain(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Start(); err != ErrDrained {
+		t.Errorf("unexpected error starting drained monitor: %v", err)
+	}
+}
@@ -0,0 +1,234 @@
+package monitor
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// TaggedEvent is a minute event of one of the networks of a Manager
+type TaggedEvent struct {
+	Network string `json:"network"`
+	Event
+}
+
+// TaggedHeight is a new height or dbheight of one of the networks of a Manager
+type TaggedHeight struct {
+	Network string `json:"network"`
+	Height  int64  `json:"height"`
+}
+
+// TaggedError is an error of one of the networks of a Manager
+type TaggedError struct {
+	Network string
+	Err     error
+}
+
+func (e *TaggedError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Network, e.Err)
+}
+
+// Unwrap returns the original error
+func (e *TaggedError) Unwrap() error {
+	return e.Err
+}
+
+// Manager owns the monitors of multiple networks, ie mainnet, testnet, and private
+// networks, and combines their events into listeners for all networks.
+type Manager struct {
+	mtx      sync.Mutex
+	networks map[string]*managedNetwork
+
+	listenerMtx       sync.Mutex
+	minuteListeners   []chan TaggedEvent
+	heightListeners   []chan TaggedHeight
+	dbheightListeners []chan TaggedHeight
+	errorListeners    []chan *TaggedError
+}
+
+type managedNetwork struct {
+	source    Source
+	close     chan struct{}
+	minutes   <-chan Event
+	heights   <-chan int64
+	dbheights <-chan int64
+	errs      <-chan error
+}
+
+// NewManager creates a manager without any networks
+func NewManager() *Manager {
+	mg := new(Manager)
+	mg.networks = make(map[string]*managedNetwork)
+	return mg
+}
+
+// Add adds the monitor of a network under the given name. The manager takes ownership
+// of the monitor and stops it when the network is removed or the manager is stopped.
+// Returns an error if the name is already taken.
+func (mg *Manager) Add(network string, source Source) error {
+	mg.mtx.Lock()
+	defer mg.mtx.Unlock()
+	if _, ok := mg.networks[network]; ok {
+		return fmt.Errorf("network %q already exists", network)
+	}
+
+	n := &managedNetwork{source: source, close: make(chan struct{})}
+	n.minutes = source.NewMinuteListener()
+	n.heights = source.NewHeightListener()
+	n.dbheights = source.NewDBHeightListener()
+	n.errs = source.NewErrorListener()
+	mg.networks[network] = n
+	go mg.forward(network, n)
+	return nil
+}
+
+// Remove stops the monitor of the network and removes it from the manager
+func (mg *Manager) Remove(network string) {
+	mg.mtx.Lock()
+	n, ok := mg.networks[network]
+	delete(mg.networks, network)
+	mg.mtx.Unlock()
+
+	if ok {
+		close(n.close)
+		n.source.Stop()
+	}
+}
+
+// Monitor returns the monitor of the network.
+// The second return value is false if the network doesn't exist.
+func (mg *Manager) Monitor(network string) (Source, bool) {
+	mg.mtx.Lock()
+	defer mg.mtx.Unlock()
+	n, ok := mg.networks[network]
+	if !ok {
+		return nil, false
+	}
+	return n.source, true
+}
+
+// Networks returns the names of all networks, sorted alphabetically
+func (mg *Manager) Networks() []string {
+	mg.mtx.Lock()
+	defer mg.mtx.Unlock()
+	names := make([]string, 0, len(mg.networks))
+	for name := range mg.networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Stop stops and removes all networks
+func (mg *Manager) Stop() {
+	for _, name := range mg.Networks() {
+		mg.Remove(name)
+	}
+}
+
+// NewMinuteListener spawns a new listener that receives the minute events of all networks.
+// Each reader must have its own listener.
+func (mg *Manager) NewMinuteListener() <-chan TaggedEvent {
+	mg.listenerMtx.Lock()
+	defer mg.listenerMtx.Unlock()
+	l := make(chan TaggedEvent, 25)
+	mg.minuteListeners = append(mg.minuteListeners, l)
+	return l
+}
+
+// NewHeightListener spawns a new listener that receives the new heights of all networks.
+// Each reader must have its own listener.
+func (mg *Manager) NewHeightListener() <-chan TaggedHeight {
+	mg.listenerMtx.Lock()
+	defer mg.listenerMtx.Unlock()
+	l := make(chan TaggedHeight, 6)
+	mg.heightListeners = append(mg.heightListeners, l)
+	return l
+}
+
+// NewDBHeightListener spawns a new listener that receives the new dbheights of all networks.
+// Each reader must have its own listener.
+func (mg *Manager) NewDBHeightListener() <-chan TaggedHeight {
+	mg.listenerMtx.Lock()
+	defer mg.listenerMtx.Unlock()
+	l := make(chan TaggedHeight, 6)
+	mg.dbheightListeners = append(mg.dbheightListeners, l)
+	return l
+}
+
+// NewErrorListener spawns a new listener that receives the errors of all networks.
+// Each reader must have its own listener.
+func (mg *Manager) NewErrorListener() <-chan *TaggedError {
+	mg.listenerMtx.Lock()
+	defer mg.listenerMtx.Unlock()
+	l := make(chan *TaggedError, 6)
+	mg.errorListeners = append(mg.errorListeners, l)
+	return l
+}
+
+// forward the events of a network to the manager's listeners until it is removed
+func (mg *Manager) forward(network string, n *managedNetwork) {
+	for {
+		select {
+		case <-n.close:
+			return
+		case e, ok := <-n.minutes:
+			if !ok { // drained
+				return
+			}
+			mg.notifyMinute(TaggedEvent{Network: network, Event: e})
+		case h, ok := <-n.heights:
+			if !ok {
+				return
+			}
+			mg.notifyHeight(false, TaggedHeight{Network: network, Height: h})
+		case h, ok := <-n.dbheights:
+			if !ok {
+				return
+			}
+			mg.notifyHeight(true, TaggedHeight{Network: network, Height: h})
+		case err, ok := <-n.errs:
+			if !ok {
+				return
+			}
+			mg.notifyError(&TaggedError{Network: network, Err: err})
+		}
+	}
+}
+
+func (mg *Manager) notifyMinute(e TaggedEvent) {
+	mg.listenerMtx.Lock()
+	defer mg.listenerMtx.Unlock()
+	for _, l := range mg.minuteListeners {
+		select {
+		case l <- e:
+		default:
+		}
+	}
+}
+
+func (mg *Manager) notifyHeight(dbheight bool, h TaggedHeight) {
+	mg.listenerMtx.Lock()
+	defer mg.listenerMtx.Unlock()
+	listeners := mg.heightListeners
+	if dbheight {
+		listeners = mg.dbheightListeners
+	}
+	for _, l := range listeners {
+		select {
+		case l <- h:
+		default:
+		}
+	}
+}
+
+func (mg *Manager) notifyError(err *TaggedError) {
+	mg.listenerMtx.Lock()
+	defer mg.listenerMtx.Unlock()
+	for _, l := range mg.errorListeners {
+		select {
+		case l <- err:
+		default:
+		}
+	}
+}
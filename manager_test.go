@@ -0,0 +1,62 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestManager(t *testing.T) {
+	mainnet := newTestServer("localhost:9849", 10, 0, time.Second*10, t)
+	defer mainnet.stop()
+	testnet := newTestServer("localhost:9848", 500, 5, time.Second*10, t)
+	defer testnet.stop()
+
+	mg := NewManager()
+	defer mg.Stop()
+
+	for name, url := range map[string]string{"mainnet": "http://localhost:9849/v2", "testnet": "http://localhost:9848/v2"} {
+		m, err := NewMonitorWithConfig(url, Config{Interval: time.Millisecond * 50})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := mg.Add(name, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mg.Add("mainnet", nil); err == nil {
+		t.Error("no error for duplicate network")
+	}
+	if n := mg.Networks(); len(n) != 2 || n[0] != "mainnet" || n[1] != "testnet" {
+		t.Errorf("unexpected networks %v", n)
+	}
+
+	listener := mg.NewMinuteListener()
+	mainnet.tick()
+	testnet.tick()
+
+	got := make(map[string]int64)
+	for i := 0; i < 2; i++ {
+		select {
+		case e := <-listener:
+			got[e.Network] = e.Height*10 + e.Minute
+		case <-time.After(time.Second):
+			t.Fatalf("event %d not received", i)
+		}
+	}
+	if got["mainnet"] != 101 || got["testnet"] != 5006 {
+		t.Errorf("unexpected events %v", got)
+	}
+
+	m, ok := mg.Monitor("testnet")
+	if !ok {
+		t.Fatal("testnet not found")
+	}
+	if h, _, _ := m.GetCurrentMinute(); h != 500 {
+		t.Errorf("unexpected testnet height %d", h)
+	}
+
+	mg.Remove("testnet")
+	if _, ok := mg.Monitor("testnet"); ok {
+		t.Error("testnet not removed")
+	}
+}
@@ -1,5 +1,10 @@
 package monitor
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // MinuteResponse is a struct formed after the response from the factomd API.
 // Only contains relevant information.
 // See: https://github.com/FactomProject/factomd/blob/0ff77090ab055d4c069612ca1a6814bde88155ab/wsapi/wsapiStructs.go#L68-L79
@@ -8,4 +13,37 @@ type MinuteResponse struct {
 	LeaderHeight  int64 `json:"leaderheight"`
 	Minute        int64 `json:"minute"`
 	DBlockSeconds int64 `json:"directoryblockinseconds"`
+
+	// Unix timestamps in nanoseconds, as reported by the node
+	BlockStartTime  int64 `json:"currentblockstarttime"`
+	MinuteStartTime int64 `json:"currentminutestarttime"`
+	Time            int64 `json:"currenttime"`
+
+	// Raw is the unmodified result of the API response, which includes
+	// fields that are not modeled by this struct
+	Raw json.RawMessage `json:"-"`
+}
+
+// nanoTime converts a unix nanosecond timestamp to time.Time.
+// A timestamp of 0 means the node did not report a time and results in the zero time.
+func nanoTime(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
+// BlockStart is the time the node started working on the current block
+func (mr *MinuteResponse) BlockStart() time.Time {
+	return nanoTime(mr.BlockStartTime)
+}
+
+// MinuteStart is the time the node started working on the current minute
+func (mr *MinuteResponse) MinuteStart() time.Time {
+	return nanoTime(mr.MinuteStartTime)
+}
+
+// NodeTime is the node's clock at the time of the response
+func (mr *MinuteResponse) NodeTime() time.Time {
+	return nanoTime(mr.Time)
 }
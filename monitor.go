@@ -2,15 +2,29 @@ package monitor
 
 import (
 	"context"
+	"encoding/json"
+	"math/rand"
 	"sync"
 	"time"
 
 	"github.com/AdamSLevy/jsonrpc2/v14"
 )
 
-// Interval specifies the minimum time spent between API requests
+// Interval specifies the time spent between API requests when the monitor can't
+// predict the next minute, or when the minute is running late
 var Interval time.Duration = time.Second
 
+// FastInterval specifies the time spent between API requests close to the predicted
+// start of the next minute
+var FastInterval time.Duration = time.Millisecond * 200
+
+// SlowInterval specifies the maximum time spent between API requests in the middle of a minute
+var SlowInterval time.Duration = time.Second * 10
+
+// BoundaryWindow specifies how long before and after the predicted start of the next
+// minute the monitor polls at FastInterval
+var BoundaryWindow time.Duration = time.Second * 2
+
 // Timeout specifies the maximum time an API request can take
 var Timeout time.Duration = time.Second * 5
 
@@ -18,59 +32,303 @@ var Timeout time.Duration = time.Second * 5
 type Monitor struct {
 	url    string
 	client *jsonrpc2.Client
+	conf   Config
+	pool   *nodePool // nil without Config.CourtesyNodes
+
+	resumed    *Cursor // guarded by heightMtx
+	random     *rand.Rand
+	latencies  *latencies
+	histogram  *windowedHistogram
+	reveals    *windowedHistogram
+	blockTimes *latencies
+	throughput *throughput
+
+	heightMtx   sync.Mutex
+	height      int64
+	dbheight    int64
+	minute      int64
+	startHeight int64
+	lastPoll    time.Time
+	blockStart  time.Time
+	minuteStart time.Time
+	nodeTime    time.Time
+	failures    int
+	lastError   error
+	lastEvent   time.Time
+	sequence    uint64
+	// the height of a large jump that still needs to be confirmed, see validate
+	suspectHeight int64
+	// the reason the heights API disagreed at the last poll, see crossCheck
+	heightsMismatch string
+
+	blockSeconds int64
+	clockOffset  time.Duration
+	version      string
+	apiVersion   string
+	networkID    NetworkID
+	networkKnown bool
+
+	// the previous minute event and when it was observed
+	prevEvent    Event
+	prevObserved time.Time
+
+	// the event of the most recent new height and when it was observed
+	prevBlock         Event
+	prevBlockObserved time.Time
+
+	listenerMtx         sync.Mutex
+	minuteListeners     []chan Event
+	filteredListeners   []filteredListener
+	heightListeners     []chan int64
+	dbheightListeners   []chan int64
+	errorListeners      []chan error
+	rawListeners        []chan *MinuteResponse
+	entryListeners      []chan EntryEvent
+	pendingListeners    []chan PendingEntryEvent
+	fctBalanceListeners []chan BalanceEvent
+	ecBalanceListeners  []chan BalanceEvent
+	pendingTxListeners  []chan PendingTransactionEvent
+	anchorListeners     []chan AnchorEvent
+	dblockListeners     []chan DirectoryBlockEvent
+	authorityListeners  []chan AuthorityEvent
+	txListeners         []chan TransactionEvent
+	ecListeners         []chan ECEvent
+	rateListeners       []chan RateEvent
+	versionListeners    []chan VersionEvent
+	networkListeners    []chan NetworkEvent
+	timingListeners     []chan MinuteTiming
+	listenerStats       []*listenerStats
+	listenerIndex       map[interface{}]*listenerStats
+	history             *history
+
+	// sequenced height and dbheight events
+	heightEventListeners   []chan HeightEvent
+	dbheightEventListeners []chan HeightEvent
+	heightSequence         uint64
+	dbheightSequence       uint64
+
+	sealingListeners []chan EndOfMinuteProcessing
+	sealed           int64 // the most recent block reported as sealing
+
+	alertListeners []chan Alert
+	rules          []*ruleState // guarded by watchMtx
+	polls          pollLog
+	lastBlock      time.Time // when the most recent new height was observed, guarded by heightMtx
+
+	syncListeners []chan SyncProgress
+	syncing       bool // guarded by heightMtx
+
+	restartListeners []chan RestartEvent
+	restartSince     time.Time // the last poll before the node restarted, guarded by heightMtx
+
+	anomalyListeners []chan BlockTimeAnomaly
+
+	finalityListeners []chan FinalityEvent
+	unsaved           map[int64]time.Time // observed leader heights => first observed, guarded by heightMtx
+
+	depthListeners []*depthListener
+
+	coinbaseListeners []chan CoinbaseEvent
+
+	watchMtx      sync.Mutex
+	chains        map[string]string // chain id => known head
+	pending       map[string]*pendingEntry
+	fctBalances   map[string]int64 // address => balance
+	ecBalances    map[string]int64 // address => balance
+	pendingTxs    map[string]PendingTransaction
+	txAddresses   map[string]bool
+	anchors       map[int64]*anchorStatus
+	dblocks       bool
+	ablocks       bool
+	ablockWatcher bool
+	coinbase      bool
+	fblocks       bool
+	ecblocks      bool
+	ecRate        int64
+	ecRateWatched bool
+	network       *NetworkEvent // last diagnostics status
+	sync          *syncState
+	durables      map[string]*DurableListener
+	// the chains and addresses of Config.Watchlist that are not watched yet
+	savedWatchlist Watchlist
+
+	watchlistMtx sync.Mutex // serializes saves of the watchlist
+
+	cadenceMtx sync.Mutex
+	lastRuns   map[string]time.Time // watcher => last run, see Config.Cadences
+
+	recordMtx sync.Mutex
+
+	batchMtx         sync.Mutex
+	batches          map[string]*rpcBatch // url => pending batch, see Config.BatchRequests
+	batchUnsupported bool                 // the node answered a batch with an error
+
+	// held by Restore and around start. Restore watches while holding it, so it is
+	// locked before watchMtx and runMtx.
+	restoreMtx sync.Mutex
+
+	runMtx   sync.Mutex
+	running  bool
+	started  bool // true after the first successful start
+	drained  bool
+	close    chan struct{} // closed by Stop
+	done     chan struct{} // closed when run exits
+	watchers []func(stop <-chan struct{})
+	err      error // the reason the monitor stopped itself, guarded by heightMtx
+}
 
-	heightMtx sync.Mutex
-	height    int64
-	dbheight  int64
-	minute    int64
-
-	listenerMtx       sync.Mutex
-	minuteListeners   []chan Event
-	heightListeners   []chan int64
-	dbheightListeners []chan int64
-	errorListeners    []chan error
-
-	close  chan interface{}
-	closer sync.Once
+// Source is the part of the monitor's API needed to consume events.
+// Code that depends on Source instead of *Monitor can be tested with
+// monitortest.FakeMonitor or any other mock.
+type Source interface {
+	NewMinuteListener() <-chan Event
+	NewHeightListener() <-chan int64
+	NewDBHeightListener() <-chan int64
+	NewErrorListener() <-chan error
+	GetCurrentMinute() (int64, int64, int64)
+	Stop()
 }
 
+var _ Source = (*Monitor)(nil)
+
 // Event contains the data sent to minute listeners.
 type Event struct {
 	// The most recent block saved in the node's database
-	DBHeight int64
+	DBHeight int64 `json:"dbheight"`
 	// The most recently completed block in the network
-	Height int64
+	Height int64 `json:"height"`
 	// The minute the network is currently working on
-	Minute int64
+	Minute int64 `json:"minute"`
+	// The time the node started working on the current block
+	BlockStart time.Time `json:"blockstart"`
+	// The time the node started working on the current minute
+	MinuteStart time.Time `json:"minutestart"`
+	// The node's clock at the time the event was polled
+	NodeTime time.Time `json:"nodetime"`
+	// The network the node belongs to, ie "mainnet" or "testnet". Empty if it could
+	// not be detected. See NetworkID.
+	Network string `json:"network,omitempty"`
+	// Increases by one with every minute event sent by this monitor, starting at 1.
+	// On a minute listener, a gap in the sequence means the listener dropped events.
+	// Filtered and MinuteN listeners skip events by design, and a listener with
+	// history starts at the oldest buffered event rather than 1.
+	Sequence uint64 `json:"sequence"`
+	// True for the event a new listener receives with Config.IncludePartial, which
+	// describes a minute that was already in progress. It has no sequence number.
+	Partial bool `json:"partial,omitempty"`
+	// The API response that triggered the event. It is shared between all
+	// listeners and must not be modified.
+	Raw *MinuteResponse `json:"-"`
 }
 
 // NewMonitor creates a new monitor that begins polling the provided url immediately.
 // If the initial request does not work, an error is returned.
 // Starts a goroutine that can be stopped via monitor.Stop().
 func NewMonitor(url string) (*Monitor, error) {
+	return NewMonitorWithConfig(url, Config{})
+}
+
+// NewMonitorWithConfig creates a new monitor with the specified settings that begins
+// polling the provided url immediately.
+// If the initial request does not work or the store can't be loaded, an error is returned.
+// Starts a goroutine that can be stopped via monitor.Stop().
+func NewMonitorWithConfig(url string, conf Config) (*Monitor, error) {
+	m, err := New(url, conf)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.Start(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// New creates a new monitor with the specified settings without contacting the node.
+// Listeners can be created and chains or addresses watched before the monitor is started
+// via Start.
+// If the store can't be loaded, an error is returned.
+func New(url string, conf Config) (*Monitor, error) {
 	m := new(Monitor)
 	m.url = url
+	m.conf = conf
 
-	m.client = new(jsonrpc2.Client)
-
-	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
-	defer cancel()
-	response, err := m.FactomdRequest(ctx)
+	client, err := newClient(url, conf)
 	if err != nil {
 		return nil, err
 	}
+	m.client = client
+	if len(conf.CourtesyNodes) > 0 {
+		m.pool = newNodePool(append([]string{url}, conf.CourtesyNodes...))
+	}
+	m.history = newHistory(conf.HistorySize)
+	m.random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	m.latencies = newLatencies(latencySamples)
+	m.histogram = newWindowedHistogram(LatencyWindow)
+	m.reveals = newWindowedHistogram(RevealLatencyWindow)
+	window := conf.BlockTimeWindow
+	if window <= 0 {
+		window = defaultBlockTimeWindow
+	}
+	m.blockTimes = newLatencies(window)
+	m.throughput = newThroughput(window)
 
-	m.height = response.LeaderHeight
-	m.minute = response.Minute
-	m.dbheight = response.DBHeight
+	if conf.Store != nil {
+		cursor, err := conf.Store.Load()
+		if err != nil {
+			return nil, err
+		}
+		m.resumed = cursor
+	}
 
-	m.close = make(chan interface{})
+	if conf.Watchlist != nil {
+		w, err := conf.Watchlist.Load()
+		if err != nil {
+			return nil, err
+		}
+		if w != nil {
+			if err := checkWatchlist(w); err != nil {
+				return nil, err
+			}
+			m.savedWatchlist = *w
+		}
+	}
 
-	go m.run(response)
+	m.done = make(chan struct{})
+	close(m.done) // not running
+	m.everyDBHeight(m.cadenced(WatcherVersion, m.checkVersion))
 	return m, nil
 }
 
-// GetCurrentMinute returns the most recent Height and Minute the monitor has received
+// Resumed returns the cursor that was loaded from the store when the monitor was created.
+// The second return value is false if there is no store or nothing was saved yet.
+func (m *Monitor) Resumed() (Cursor, bool) {
+	m.heightMtx.Lock()
+	defer m.heightMtx.Unlock()
+	if m.resumed == nil {
+		return Cursor{}, false
+	}
+	return *m.resumed, true
+}
+
+// Missed returns the number of blocks that were completed by the network between
+// the saved cursor and the start of this monitor.
+// The second return value is false if there is no saved cursor to compare to.
+func (m *Monitor) Missed() (int64, bool) {
+	m.heightMtx.Lock()
+	defer m.heightMtx.Unlock()
+	if m.resumed == nil {
+		return 0, false
+	}
+	missed := m.startHeight - m.resumed.Height
+	if missed < 0 {
+		missed = 0
+	}
+	return missed, true
+}
+
+// GetCurrentMinute returns the most recent Height, DBHeight, and Minute the monitor has received
+//
+// Deprecated: Use State instead.
 func (m *Monitor) GetCurrentMinute() (int64, int64, int64) {
 	m.heightMtx.Lock()
 	defer m.heightMtx.Unlock()
@@ -80,30 +338,53 @@ func (m *Monitor) GetCurrentMinute() (int64, int64, int64) {
 // NewMinuteListener spawns a new listener that receives events for every minute.
 // Each reader must have its own listener.
 func (m *Monitor) NewMinuteListener() <-chan Event {
+	return m.NewMinuteListenerNamed("")
+}
+
+// NewMinuteListenerNamed is like NewMinuteListener but the listener is listed under the name in Listeners().
+func (m *Monitor) NewMinuteListenerNamed(name string) <-chan Event {
 	m.listenerMtx.Lock()
 	defer m.listenerMtx.Unlock()
 	l := make(chan Event, 25)
 	m.minuteListeners = append(m.minuteListeners, l)
+	m.register("minute", l).name = name
+	if m.conf.IncludePartial {
+		if e, ok := m.partial(); ok {
+			l <- e
+		}
+	}
 	return l
 }
 
 // NewHeightListener spawns a new listener that receives events every time a new height is attained.
 // Each reader must have its own listener.
 func (m *Monitor) NewHeightListener() <-chan int64 {
+	return m.NewHeightListenerNamed("")
+}
+
+// NewHeightListenerNamed is like NewHeightListener but the listener is listed under the name in Listeners().
+func (m *Monitor) NewHeightListenerNamed(name string) <-chan int64 {
 	m.listenerMtx.Lock()
 	defer m.listenerMtx.Unlock()
 	l := make(chan int64, 6)
 	m.heightListeners = append(m.heightListeners, l)
+	m.register("height", l).name = name
 	return l
 }
 
 // NewDBHeightListener spawns a new listener that receives events every time a new DBHeight is attained.
 // Each reader must have its own listener.
 func (m *Monitor) NewDBHeightListener() <-chan int64 {
+	return m.NewDBHeightListenerNamed("")
+}
+
+// NewDBHeightListenerNamed is like NewDBHeightListener but the listener is listed under the name in Listeners().
+func (m *Monitor) NewDBHeightListenerNamed(name string) <-chan int64 {
 	m.listenerMtx.Lock()
 	defer m.listenerMtx.Unlock()
 	l := make(chan int64, 6)
 	m.dbheightListeners = append(m.dbheightListeners, l)
+	m.register("dbheight", l).name = name
 	return l
 }
 
@@ -112,71 +393,193 @@ func (m *Monitor) NewDBHeightListener() <-chan int64 {
 // A high frequency of errors means the monitor is unable to reach the node.
 // Each reader must have its own listener.
 func (m *Monitor) NewErrorListener() <-chan error {
+	return m.NewErrorListenerNamed("")
+}
+
+// NewErrorListenerNamed is like NewErrorListener but the listener is listed under the name in Listeners().
+func (m *Monitor) NewErrorListenerNamed(name string) <-chan error {
 	m.listenerMtx.Lock()
 	defer m.listenerMtx.Unlock()
 	l := make(chan error, 6)
 	m.errorListeners = append(m.errorListeners, l)
+	m.register("error", l).name = name
 	return l
 }
 
-func (m *Monitor) run(resp *MinuteResponse) {
-	minute := time.Duration(resp.DBlockSeconds) * time.Second / 10
-	ticker := time.NewTicker(Interval)
-	last := time.Now()
+// NewRawListener spawns a new listener that receives the full API response of every
+// successful poll, regardless of whether the height or minute changed.
+// The responses are shared between all raw listeners and must not be modified.
+// Each reader must have its own listener.
+func (m *Monitor) NewRawListener() <-chan *MinuteResponse {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan *MinuteResponse, 6)
+	m.rawListeners = append(m.rawListeners, l)
+	m.register("raw", l)
+	return l
+}
+
+func (m *Monitor) run(stop <-chan struct{}, done chan struct{}) {
+	defer close(done)
+	timer := m.clock().NewTimer(m.jitter(m.pollDelay()))
+	defer timer.Stop()
 
 	for {
 		select {
-		case <-m.close:
+		case <-stop:
 			return
-		case <-ticker.C:
+		case <-timer.C():
 		}
+		m.poll()
+		timer.Reset(m.jitter(m.pollDelay()))
+	}
+}
 
-		ctx, cancel := context.WithTimeout(context.Background(), Timeout)
-		resp, err := m.FactomdRequest(ctx)
-		if err != nil {
-			m.notifyError(err)
-			cancel()
-			continue
+// pollDelay returns the time to wait until the next poll.
+// The monitor polls sparsely in the middle of a minute and quickly around the
+// predicted start of the next minute.
+func (m *Monitor) pollDelay() time.Duration {
+	interval := orDefault(m.conf.Interval, Interval)
+	next, err := m.EstimateNextMinute()
+	if err != nil {
+		return interval
+	}
+
+	window := orDefault(m.conf.BoundaryWindow, BoundaryWindow)
+	until := next.Sub(m.clock().Now())
+	switch {
+	case until > window: // mid-minute
+		wait := until - window
+		if slow := orDefault(m.conf.SlowInterval, SlowInterval); wait > slow {
+			wait = slow
 		}
-		cancel()
+		return wait
+	case until >= -window: // around the boundary
+		return orDefault(m.conf.FastInterval, FastInterval)
+	default: // the minute is late
+		return interval
+	}
+}
 
-		if m.newHeight(resp) { // sends out event
-			diff := minute - time.Since(last)
-			if diff < 0 { // absolute value
-				diff = -diff
-			}
-			last = time.Now()
-			if diff < Interval {
-				select {
-				case <-m.close:
-					return
-				case <-time.After(minute - Interval): // return a little early
-				}
-			}
+// orDefault returns d, or def if d is not set
+func orDefault(d, def time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return def
+}
+
+// jitter randomizes the delay by up to Config.Jitter in either direction
+func (m *Monitor) jitter(d time.Duration) time.Duration {
+	if m.conf.Jitter <= 0 {
+		return d
+	}
+	factor := 1 + m.conf.Jitter*(2*m.random.Float64()-1)
+	if factor < 0 {
+		factor = 0
+	}
+	return time.Duration(float64(d) * factor)
+}
+
+// poll the node once and send out events
+func (m *Monitor) poll() {
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout())
+	defer cancel()
+
+	var heights *HeightsResponse
+	var heightsErr error
+	crossChecked := make(chan struct{})
+	if m.conf.CrossCheckHeights {
+		go func() {
+			defer close(crossChecked)
+			heights, heightsErr = m.HeightsRequest(ctx)
+		}()
+	}
+
+	node := m.nodeURL()
+	resp, err := m.FactomdRequest(ctx)
+	if err == nil {
+		m.record(resp)
+		err = m.validate(resp, true)
+	}
+	if stale := m.checkNode(node, resp, err); stale != nil {
+		err = stale
+	}
+	if err == nil {
+		m.checkRestart(resp)
+	}
+	m.polled(resp, err)
+	if err != nil {
+		m.notifyError(err)
+		// with courtesy nodes, the monitor moves on to the next node
+		if fatal(err) && m.pool == nil {
+			m.fail(err)
+		}
+		return
+	}
+
+	if m.conf.CrossCheckHeights {
+		<-crossChecked
+		if heightsErr != nil {
+			m.notifyError(heightsErr)
+		} else {
+			m.crossCheck(resp, heights)
 		}
 	}
+
+	m.notifyRaw(resp)
+	m.checkSealing(resp)
+	m.newHeight(resp) // sends out event
 }
 
 // returns true if a new height was reached and sends out event
 func (m *Monitor) newHeight(resp *MinuteResponse) bool {
 	// occasionally the node will return a minute 10 event but that's just an internal state, not a real minute
 	// height n minute 10 will be treated as height n minute 0, ie outdated
-	resp.Minute %= 10
-	if resp.LeaderHeight > m.height || (resp.LeaderHeight == m.height && resp.Minute > m.minute) {
+	minute := resp.Minute % 10
+	if resp.LeaderHeight > m.height || (resp.LeaderHeight == m.height && minute > m.minute) {
 		newHeight := resp.LeaderHeight > m.height
 		newDBHeight := resp.DBHeight > m.dbheight
+		now := m.clock().Now()
 		m.heightMtx.Lock()
 		m.height = resp.LeaderHeight
-		m.minute = resp.Minute
+		m.minute = minute
 		m.dbheight = resp.DBHeight
+		m.lastEvent = now
+		if newHeight {
+			m.lastBlock = now
+		}
+		m.sequence++
+		sequence := m.sequence
+		// heights the node rushes through while syncing are not worth a height event
+		notifyHeights := !m.syncing
+		var network string
+		if m.networkKnown {
+			network = m.networkID.String()
+		}
 		m.heightMtx.Unlock()
 
 		var e Event
 		e.DBHeight = resp.DBHeight
 		e.Height = resp.LeaderHeight
-		e.Minute = resp.Minute
-
-		m.notify(e, newHeight, newDBHeight)
+		e.Minute = minute
+		e.BlockStart = resp.BlockStart()
+		e.MinuteStart = resp.MinuteStart()
+		e.NodeTime = resp.NodeTime()
+		e.Network = network
+		e.Sequence = sequence
+		e.Raw = resp
+
+		// persist first so listeners that query the store or journal see the event
+		m.persist(e)
+		m.notify(e, newHeight && notifyHeights, newDBHeight && notifyHeights)
+		if notifyHeights {
+			m.checkFinality(e, now)
+		}
+		m.timeMinute(e, now)
+		if newHeight {
+			m.timeBlock(e, now)
+		}
 		return true
 	}
 
@@ -192,7 +595,9 @@ func (m *Monitor) notify(e Event, height, dbheight bool) {
 		for _, l := range m.heightListeners {
 			select {
 			case l <- e.Height: // only int64
+				m.delivered(l)
 			default:
+				m.dropped(l)
 			}
 		}
 	}
@@ -201,15 +606,54 @@ func (m *Monitor) notify(e Event, height, dbheight bool) {
 		for _, l := range m.dbheightListeners {
 			select {
 			case l <- e.DBHeight: // only int64
+				m.delivered(l)
 			default:
+				m.dropped(l)
 			}
 		}
 	}
 
+	m.history.add(e)
 	for _, l := range m.minuteListeners {
 		select {
 		case l <- e:
+			m.delivered(l)
 		default:
+			m.dropped(l)
+		}
+	}
+	m.notifyFiltered(e)
+	m.notifySequenced(e, height, dbheight)
+	if dbheight {
+		m.notifyDepth(e.DBHeight)
+	}
+}
+
+// persist the event to the store and journal, if configured
+func (m *Monitor) persist(e Event) {
+	if m.conf.Store != nil {
+		c := Cursor{Height: e.Height, DBHeight: e.DBHeight, Minute: e.Minute, Time: m.clock().Now()}
+		if err := m.conf.Store.Save(c); err != nil {
+			m.notifyError(err)
+		}
+	}
+
+	if m.conf.Journal != nil {
+		if err := m.conf.Journal.Append(e); err != nil {
+			m.notifyError(err)
+		}
+	}
+}
+
+func (m *Monitor) notifyRaw(resp *MinuteResponse) {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	for _, l := range m.rawListeners {
+		select {
+		case l <- resp:
+			m.delivered(l)
+		default:
+			m.dropped(l)
 		}
 	}
 }
@@ -220,24 +664,240 @@ func (m *Monitor) notifyError(err error) {
 	for _, l := range m.errorListeners {
 		select {
 		case l <- err:
+			m.delivered(l)
 		default:
+			m.dropped(l)
+		}
+	}
+}
+
+// request sends an API request to the configured node, honoring the limiters
+func (m *Monitor) request(ctx context.Context, method string, params, result interface{}) error {
+	return m.requestURL(ctx, m.nodeURL(), method, params, result)
+}
+
+// requestURL sends an API request to the url, honoring the limiters.
+// With Config.BatchRequests, the request is sent as part of a batch.
+func (m *Monitor) requestURL(ctx context.Context, url, method string, params, result interface{}) error {
+	if m.conf.BatchRequests {
+		return m.batch(ctx, url, method, params, result)
+	}
+	return m.send(ctx, url, method, params, result)
+}
+
+// send sends a single API request to the url, honoring the limiters
+func (m *Monitor) send(ctx context.Context, url, method string, params, result interface{}) error {
+	if err := m.acquire(ctx); err != nil {
+		return err
+	}
+	defer m.release()
+	start := time.Now()
+	err := m.client.Request(ctx, url, method, params, result)
+	m.recordLatency(ctx, start, err)
+	return err
+}
+
+// acquire waits until the limiters allow a request. Every successful acquire has to be
+// followed by a release.
+func (m *Monitor) acquire(ctx context.Context) error {
+	if m.conf.ConcurrencyLimiter != nil {
+		if err := m.conf.ConcurrencyLimiter.Acquire(ctx); err != nil {
+			return err
 		}
 	}
+	if m.conf.RateLimiter != nil {
+		if err := m.conf.RateLimiter.Wait(ctx); err != nil {
+			m.release()
+			return err
+		}
+	}
+	return nil
+}
+
+// release frees the request's slot of the concurrency limiter
+func (m *Monitor) release() {
+	if m.conf.ConcurrencyLimiter != nil {
+		m.conf.ConcurrencyLimiter.Release()
+	}
 }
 
 // FactomdRequest sends a "current-minute" API request to the configured node.
+// With Config.RaceEndpoints, the request is sent to all endpoints at once and the
+// first successful response is used.
 func (m *Monitor) FactomdRequest(ctx context.Context) (*MinuteResponse, error) {
+	var raw json.RawMessage
+	var err error
+	if len(m.conf.RaceEndpoints) > 0 {
+		raw, err = m.race(ctx, "current-minute")
+	} else {
+		err = m.request(ctx, "current-minute", nil, &raw)
+	}
+	if err != nil {
+		return nil, err
+	}
 	res := new(MinuteResponse)
-	if err := m.client.Request(ctx, m.url, "current-minute", nil, res); err != nil {
+	if err := json.Unmarshal(raw, res); err != nil {
 		return nil, err
 	}
+	res.Raw = raw
 	return res, nil
 }
 
-// Stop will shut down the monitor and halt all polling.
-// A monitor that has been stopped cannot be started again.
+// Start begins polling the node, starting with an initial request. If the initial request
+// does not work, an error is returned and the monitor remains stopped.
+// A stopped monitor can be started again and keeps its listeners and watch lists. When it
+// is restarted, listeners receive an event if the node moved on while it was stopped.
+// Starting a running monitor has no effect. A drained monitor can't be started again.
+// Once the monitor is running, the chains and addresses of Config.Watchlist that aren't
+// watched yet are watched again.
+func (m *Monitor) Start() error {
+	m.restoreMtx.Lock()
+	started, err := m.start()
+	m.restoreMtx.Unlock()
+	if err != nil || !started {
+		return err
+	}
+	// watching requires runMtx to add watchers
+	m.restoreWatchlist()
+	return nil
+}
+
+// start sends the initial request and starts polling. Returns false if the monitor
+// was already running.
+func (m *Monitor) start() (bool, error) {
+	m.runMtx.Lock()
+	if m.running {
+		m.runMtx.Unlock()
+		return false, nil
+	}
+	previous := m.done
+	m.runMtx.Unlock()
+	<-previous // wait for the previous polling goroutine to exit
+
+	m.runMtx.Lock()
+	defer m.runMtx.Unlock()
+	if m.running { // started concurrently
+		return false, nil
+	}
+	if m.drained {
+		return false, ErrDrained
+	}
+
+	response, err := m.startRequest()
+	if err != nil {
+		return false, err
+	}
+	m.record(response)
+	// the node may have moved on arbitrarily far while the monitor was stopped
+	if err := m.validate(response, false); err != nil {
+		return false, err
+	}
+	if err := m.detectNetwork(response.DBHeight); err != nil {
+		return false, err
+	}
+
+	m.heightMtx.Lock()
+	m.err = nil
+	m.heightMtx.Unlock()
+
+	m.polled(response, nil)
+	if m.started {
+		m.notifyRaw(response)
+		m.newHeight(response)
+	} else {
+		m.heightMtx.Lock()
+		m.height = response.LeaderHeight
+		m.minute = response.Minute
+		m.dbheight = response.DBHeight
+		m.startHeight = response.LeaderHeight
+		m.lastBlock = m.clock().Now()
+		if response.DBHeight < response.LeaderHeight {
+			m.unsaved = map[int64]time.Time{response.LeaderHeight: m.lastBlock}
+		}
+		m.heightMtx.Unlock()
+		m.started = true
+	}
+
+	// older nodes without the properties API can still be monitored
+	m.checkVersion(response.DBHeight)
+
+	m.running = true
+	m.close = make(chan struct{})
+	m.done = make(chan struct{})
+	for _, w := range m.watchers {
+		go w(m.close)
+	}
+	go m.run(m.close, m.done)
+	return true, nil
+}
+
+// Stop halts all polling. Listeners stay open and the monitor can be started again.
+// Stopping a stopped monitor has no effect.
 func (m *Monitor) Stop() {
-	m.closer.Do(func() {
+	m.runMtx.Lock()
+	defer m.runMtx.Unlock()
+	if m.running {
 		close(m.close)
-	})
+		m.running = false
+	}
+}
+
+// StopAndDrain stops the monitor, waits for the polling goroutine to exit, and then
+// closes all listeners. Events that were sent before stopping remain in the listeners'
+// buffers, so readers can range over their listeners until they are closed.
+// Coalesced and batch listeners deliver their pending events before they are closed.
+// Listeners created afterwards never receive events.
+// Returns the context's error if the polling goroutine did not exit in time, in which
+// case the listeners are not closed.
+func (m *Monitor) StopAndDrain(ctx context.Context) error {
+	m.Stop()
+	select {
+	case <-m.Done():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	m.runMtx.Lock()
+	m.drained = true
+	m.runMtx.Unlock()
+
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	for _, ls := range m.listenerStats {
+		if !ls.closed {
+			ls.ch.Close()
+			ls.closed = true
+		}
+	}
+	m.minuteListeners = nil
+	m.filteredListeners = nil
+	m.heightListeners = nil
+	m.dbheightListeners = nil
+	m.errorListeners = nil
+	m.rawListeners = nil
+	m.entryListeners = nil
+	m.pendingListeners = nil
+	m.fctBalanceListeners = nil
+	m.ecBalanceListeners = nil
+	m.pendingTxListeners = nil
+	m.anchorListeners = nil
+	m.dblockListeners = nil
+	m.authorityListeners = nil
+	m.txListeners = nil
+	m.ecListeners = nil
+	m.rateListeners = nil
+	m.versionListeners = nil
+	m.networkListeners = nil
+	m.timingListeners = nil
+	m.heightEventListeners = nil
+	m.dbheightEventListeners = nil
+	m.sealingListeners = nil
+	m.alertListeners = nil
+	m.syncListeners = nil
+	m.restartListeners = nil
+	m.anomalyListeners = nil
+	m.finalityListeners = nil
+	m.depthListeners = nil
+	m.coinbaseListeners = nil
+	return nil
 }
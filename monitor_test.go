@@ -1,13 +1,18 @@
 package monitor
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/AdamSLevy/jsonrpc2/v14"
 )
 
 type testServer struct {
@@ -20,6 +25,16 @@ type testServer struct {
 	blockstart  time.Time
 	minutestart time.Time
 	blocktime   time.Duration
+	version     string
+
+	// additional api methods, called with mtx held. Methods can return a jsonrpc2.Error.
+	methods map[string]func(params json.RawMessage) interface{}
+
+	// the number of single and batch requests received and whether batches are rejected,
+	// guarded by mtx
+	requests int
+	batches  int
+	noBatch  bool
 
 	runner chan interface{}
 	once   sync.Once
@@ -33,6 +48,12 @@ func newTestServer(addr string, height, minute int64, blocktime time.Duration, t
 	ts.blockstart = time.Now()
 	ts.minutestart = ts.blockstart
 	ts.blocktime = blocktime
+	ts.version = "6.9.0"
+	ts.methods = make(map[string]func(params json.RawMessage) interface{})
+	ts.methods["current-minute"] = ts.currentMinute
+	ts.methods["heights"] = ts.heights
+	ts.methods["properties"] = ts.properties
+	ts.methods["dblock-by-height"] = ts.dblockByHeight
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/v2", ts.api)
@@ -44,32 +65,70 @@ func newTestServer(addr string, height, minute int64, blocktime time.Duration, t
 
 	ts.runner = make(chan interface{})
 
-	go ts.listen()
-	//time.Sleep(time.Millisecond * 50)
+	// bind before returning: NewMonitor fails if its initial request can't connect,
+	// which raced with ListenAndServe in the goroutine
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go ts.listen(ln)
 	return ts
 }
 
+// handle registers an additional api method
+func (ts *testServer) handle(method string, f func(params json.RawMessage) interface{}) {
+	ts.mtx.Lock()
+	defer ts.mtx.Unlock()
+	ts.methods[method] = f
+}
+
+// rpcRequest is a JSON-RPC request received by the test server
+type rpcRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
 func (ts *testServer) api(rw http.ResponseWriter, r *http.Request) {
 	ts.mtx.Lock()
 	defer ts.mtx.Unlock()
-	resp := new(MinuteResponse)
-	resp.LeaderHeight = ts.height
-	resp.Minute = ts.minute
-	resp.DBHeight = ts.height
-	if resp.Minute == 0 {
-		resp.DBHeight--
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		ts.t.Error(err)
+		return
 	}
-	/*resp.BlockStartTime = ts.blockstart.UnixNano()
-	resp.MinuteStartTime = ts.blockstart.UnixNano()
-	resp.Time = time.Now().UnixNano()*/
-	resp.DBlockSeconds = int64(ts.blocktime.Seconds())
 
-	rpc := make(map[string]interface{})
-	rpc["jsonrpc"] = "2.0"
-	rpc["id"] = 0
-	rpc["result"] = resp
+	var res interface{}
+	if body = bytes.TrimSpace(body); len(body) > 0 && body[0] == '[' {
+		if ts.noBatch {
+			res = map[string]interface{}{"jsonrpc": "2.0", "id": nil, "error": map[string]interface{}{"code": -32600, "message": "Invalid Request"}}
+		} else {
+			var reqs []rpcRequest
+			if err := json.Unmarshal(body, &reqs); err != nil {
+				ts.t.Error(err)
+				return
+			}
+			ts.batches++
+			batch := make([]map[string]interface{}, len(reqs))
+			for i, req := range reqs {
+				batch[i] = ts.respond(req)
+				batch[i]["id"] = req.ID
+			}
+			res = batch
+		}
+	} else {
+		var req rpcRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			ts.t.Error(err)
+			return
+		}
+		ts.requests++
+		res = ts.respond(req)
+	}
 
-	js, err := json.Marshal(rpc)
+	js, err := json.Marshal(res)
 	if err != nil {
 		ts.t.Error(err)
 		return
@@ -82,8 +141,64 @@ func (ts *testServer) api(rw http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (ts *testServer) listen() {
-	if err := ts.server.ListenAndServe(); err != nil {
+// respond calls the method of the request. Must be called with mtx held.
+func (ts *testServer) respond(req rpcRequest) map[string]interface{} {
+	rpc := make(map[string]interface{})
+	rpc["jsonrpc"] = "2.0"
+	rpc["id"] = 0
+
+	if f, ok := ts.methods[req.Method]; ok {
+		res := f(req.Params)
+		if err, ok := res.(jsonrpc2.Error); ok {
+			rpc["error"] = err
+		} else {
+			rpc["result"] = res
+		}
+	} else {
+		rpc["error"] = map[string]interface{}{"code": -32601, "message": "Method not found"}
+	}
+	return rpc
+}
+
+func (ts *testServer) heights(json.RawMessage) interface{} {
+	resp := new(HeightsResponse)
+	resp.LeaderHeight = ts.height
+	resp.DirectoryBlockHeight = ts.height
+	if ts.minute == 0 {
+		resp.DirectoryBlockHeight--
+	}
+	resp.EntryBlockHeight = resp.DirectoryBlockHeight
+	resp.EntryHeight = resp.DirectoryBlockHeight
+	return resp
+}
+
+func (ts *testServer) properties(json.RawMessage) interface{} {
+	return PropertiesResponse{FactomdVersion: ts.version, FactomdAPIVersion: SupportedAPIVersion}
+}
+
+func (ts *testServer) dblockByHeight(json.RawMessage) interface{} {
+	resp := new(DirectoryBlockResponse)
+	resp.DBlock.Header.NetworkID = int64(MainNet)
+	return resp
+}
+
+func (ts *testServer) currentMinute(json.RawMessage) interface{} {
+	resp := new(MinuteResponse)
+	resp.LeaderHeight = ts.height
+	resp.Minute = ts.minute
+	resp.DBHeight = ts.height
+	if resp.Minute == 0 && resp.DBHeight > 0 { // the genesis block is saved right away
+		resp.DBHeight--
+	}
+	resp.BlockStartTime = ts.blockstart.UnixNano()
+	resp.MinuteStartTime = ts.minutestart.UnixNano()
+	resp.Time = time.Now().UnixNano()
+	resp.DBlockSeconds = int64(ts.blocktime.Seconds())
+	return resp
+}
+
+func (ts *testServer) listen(ln net.Listener) {
+	if err := ts.server.Serve(ln); err != nil {
 		if err != http.ErrServerClosed {
 			ts.t.Error(err)
 		}
@@ -138,6 +253,90 @@ func TestMonitor_GetCurrentMinute(t *testing.T) {
 
 }
 
+func TestMonitor_Times(t *testing.T) {
+	s := newTestServer("localhost:9883", 10, 9, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitorWithConfig("http://localhost:9883/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	listener := m.NewMinuteListener()
+	s.tick()
+
+	var e Event
+	select {
+	case e = <-listener:
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+	}
+
+	s.mtx.Lock()
+	blockstart, minutestart := s.blockstart, s.minutestart
+	s.mtx.Unlock()
+
+	if !e.BlockStart.Equal(blockstart) {
+		t.Errorf("unexpected block start. got = %v, want = %v", e.BlockStart, blockstart)
+	}
+	if !e.MinuteStart.Equal(minutestart) {
+		t.Errorf("unexpected minute start. got = %v, want = %v", e.MinuteStart, minutestart)
+	}
+	if e.NodeTime.Before(e.MinuteStart) {
+		t.Errorf("node time %v is before the minute start %v", e.NodeTime, e.MinuteStart)
+	}
+}
+
+func TestMonitor_Raw(t *testing.T) {
+	s := newTestServer("localhost:9882", 10, 5, time.Second*10, t)
+	defer s.stop()
+	s.handle("current-minute", func(params json.RawMessage) interface{} {
+		return map[string]interface{}{
+			"leaderheight":            s.height,
+			"directoryblockheight":    s.height,
+			"minute":                  s.minute,
+			"directoryblockinseconds": 600,
+			"stalldetected":           true,
+		}
+	})
+
+	m, err := NewMonitorWithConfig("http://localhost:9882/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	raw := m.NewRawListener()
+	listener := m.NewMinuteListener()
+
+	// raw listeners receive responses without a new minute
+	select {
+	case resp := <-raw:
+		var extra struct {
+			StallDetected bool `json:"stalldetected"`
+		}
+		if err := json.Unmarshal(resp.Raw, &extra); err != nil {
+			t.Fatal(err)
+		}
+		if !extra.StallDetected || resp.LeaderHeight != 10 {
+			t.Errorf("unexpected raw response: %s", resp.Raw)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no raw response received")
+	}
+
+	s.tick()
+	select {
+	case e := <-listener:
+		if e.Raw == nil || e.Raw.Minute != e.Minute {
+			t.Errorf("event does not carry raw response: %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+	}
+}
+
 func TestMonitor_Listeners(t *testing.T) {
 	minute := time.Second
 	s := newTestServer("localhost:9888", 0, 0, minute*10, t)
@@ -285,3 +484,61 @@ func TestMonitor_Stop(t *testing.T) {
 	default:
 	}
 }
+
+func TestMonitor_StopAndDrain(t *testing.T) {
+	s := newTestServer("localhost:9853", 10, 0, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitorWithConfig("http://localhost:9853/v2", Config{Interval: time.Millisecond * 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	minutes := m.NewMinuteListener()
+	batches := m.NewBatchListener(10, 0)
+	errs := m.NewErrorListener()
+	synced := m.NewMinuteListener()
+
+	s.tick()
+	<-synced
+	s.tick()
+	<-synced
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := m.StopAndDrain(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []int64
+	for e := range minutes {
+		got = append(got, e.Minute)
+	}
+	if len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("unexpected drained minutes %v", got)
+	}
+
+	select {
+	case b := <-batches:
+		if len(b) != 2 {
+			t.Errorf("unexpected flushed batch %+v", b)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("incomplete batch not flushed")
+	}
+	select {
+	case _, ok := <-batches:
+		if ok {
+			t.Error("batch listener not closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("batch listener not closed")
+	}
+
+	for range errs {
+	}
+
+	// draining twice is harmless
+	if err := m.StopAndDrain(ctx); err != nil {
+		t.Error(err)
+	}
+}
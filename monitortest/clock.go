@@ -0,0 +1,128 @@
+package monitortest
+
+import (
+	"sync"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+// FakeClock is a monitor.Clock that only moves when advanced.
+// Timers and tickers fire during Advance once their deadline is reached.
+type FakeClock struct {
+	mtx     sync.Mutex
+	now     time.Time
+	waiters []*fakeTimer
+}
+
+// NewFakeClock creates a fake clock set to the given time
+func NewFakeClock(now time.Time) *FakeClock {
+	c := new(FakeClock)
+	c.now = now
+	return c
+}
+
+// Now returns the fake time
+func (c *FakeClock) Now() time.Time {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward and fires all timers and tickers that are due.
+// A ticker fires at most once per call, like a real ticker with a slow reader.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.now = c.now.Add(d)
+	for _, t := range c.waiters {
+		t.fire(c.now)
+	}
+}
+
+// Timers returns the number of active timers and tickers, which can be used to wait
+// until the code under test is waiting on the clock.
+func (c *FakeClock) Timers() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	n := 0
+	for _, t := range c.waiters {
+		if t.active {
+			n++
+		}
+	}
+	return n
+}
+
+// NewTimer creates a timer that fires once the clock is advanced by d
+func (c *FakeClock) NewTimer(d time.Duration) monitor.Timer {
+	return c.add(d, false)
+}
+
+// NewTicker creates a ticker that fires every time the clock is advanced by d
+func (c *FakeClock) NewTicker(d time.Duration) monitor.Ticker {
+	return fakeTicker{c.add(d, true)}
+}
+
+func (c *FakeClock) add(d time.Duration, repeat bool) *fakeTimer {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	t := &fakeTimer{clock: c, c: make(chan time.Time, 1), repeat: repeat, interval: d}
+	t.reset(c.now, d)
+	c.waiters = append(c.waiters, t)
+	return t
+}
+
+type fakeTimer struct {
+	clock    *FakeClock
+	c        chan time.Time
+	deadline time.Time
+	interval time.Duration
+	repeat   bool
+	active   bool
+}
+
+// fire must be called with the clock's mtx held
+func (t *fakeTimer) fire(now time.Time) {
+	if !t.active || now.Before(t.deadline) {
+		return
+	}
+	select {
+	case t.c <- now:
+	default:
+	}
+	if t.repeat {
+		t.deadline = now.Add(t.interval)
+	} else {
+		t.active = false
+	}
+}
+
+// reset must be called with the clock's mtx held
+func (t *fakeTimer) reset(now time.Time, d time.Duration) bool {
+	was := t.active
+	t.active = true
+	t.deadline = now.Add(d)
+	t.fire(now)
+	return was
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mtx.Lock()
+	defer t.clock.mtx.Unlock()
+	was := t.active
+	t.active = false
+	return was
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mtx.Lock()
+	defer t.clock.mtx.Unlock()
+	return t.reset(t.clock.now, d)
+}
+
+type fakeTicker struct{ *fakeTimer }
+
+func (t fakeTicker) Stop() { t.fakeTimer.Stop() }
@@ -0,0 +1,138 @@
+package monitortest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Unix(1000, 0)
+	c := NewFakeClock(start)
+
+	timer := c.NewTimer(time.Second)
+	ticker := c.NewTicker(time.Second * 2)
+
+	c.Advance(time.Millisecond * 999)
+	select {
+	case <-timer.C():
+		t.Error("timer fired early")
+	default:
+	}
+
+	c.Advance(time.Millisecond)
+	select {
+	case now := <-timer.C():
+		if !now.Equal(start.Add(time.Second)) {
+			t.Errorf("unexpected time %v", now)
+		}
+	default:
+		t.Error("timer did not fire")
+	}
+
+	for i := 0; i < 2; i++ {
+		c.Advance(time.Second)
+		select {
+		case <-ticker.C():
+		default:
+			t.Errorf("ticker did not fire %d", i)
+		}
+		c.Advance(time.Second)
+	}
+
+	if timer.Reset(time.Second) {
+		t.Error("reset of a fired timer reported active")
+	}
+	if !timer.Stop() {
+		t.Error("stop of an active timer reported inactive")
+	}
+	c.Advance(time.Second)
+	select {
+	case <-timer.C():
+		t.Error("stopped timer fired")
+	default:
+	}
+}
+
+// minuteServer answers "current-minute" requests
+type minuteServer struct {
+	mtx    sync.Mutex
+	minute int64
+	polls  int
+}
+
+func (s *minuteServer) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     interface{} `json:"id"`
+		Method string      `json:"method"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	if req.Method == "current-minute" {
+		s.mtx.Lock()
+		s.polls++
+		resp["result"] = monitor.MinuteResponse{LeaderHeight: 10, DBHeight: 10, Minute: s.minute, DBlockSeconds: 600}
+		s.mtx.Unlock()
+	} else {
+		resp["error"] = map[string]interface{}{"code": -32601, "message": "Method not found"}
+	}
+	json.NewEncoder(rw).Encode(resp)
+}
+
+func (s *minuteServer) pollCount() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.polls
+}
+
+func TestFakeClock_Monitor(t *testing.T) {
+	ms := &minuteServer{minute: 3}
+	server := httptest.NewServer(ms)
+	defer server.Close()
+
+	start := time.Unix(1500000000, 0)
+	clock := NewFakeClock(start)
+	m, err := monitor.NewMonitorWithConfig(server.URL, monitor.Config{Clock: clock})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	if s := m.State(); !s.LastPoll.Equal(start) {
+		t.Errorf("poll time not from clock. got = %v, want = %v", s.LastPoll, start)
+	}
+
+	// wait for the polling loop to set its timer
+	for i := 0; clock.Timers() == 0; i++ {
+		if i == 100 {
+			t.Fatal("monitor did not start a timer")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	time.Sleep(time.Millisecond * 50)
+	if n := ms.pollCount(); n != 1 {
+		t.Errorf("monitor polled without the clock advancing. got = %d polls, want = 1", n)
+	}
+
+	listener := m.NewMinuteListener()
+	ms.mtx.Lock()
+	ms.minute = 4
+	ms.mtx.Unlock()
+	clock.Advance(monitor.Interval)
+
+	select {
+	case e := <-listener:
+		if e.Minute != 4 {
+			t.Errorf("unexpected event %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no event after advancing the clock")
+	}
+}
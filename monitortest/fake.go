@@ -0,0 +1,455 @@
+// Package monitortest provides a fake monitor for testing code that consumes monitor events
+// without running a factomd node or an HTTP server.
+package monitortest
+
+import (
+	"sync"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+// FakeMonitor has the same listener API as monitor.Monitor but only sends events when
+// the test advances it. Like the real monitor, events are dropped for listeners whose
+// buffer is full.
+type FakeMonitor struct {
+	mtx         sync.Mutex
+	height      int64
+	minute      int64
+	blockStart  time.Time
+	minuteStart time.Time
+	stopped     bool
+	drained     bool
+	sequence    uint64
+	latency     monitor.LatencyHistogram
+	reveal      monitor.LatencyHistogram
+	throughput  monitor.ThroughputStats
+
+	minuteListeners   []chan monitor.Event
+	heightListeners   []chan int64
+	dbheightListeners []chan int64
+	errorListeners    []chan error
+	alertListeners    []chan monitor.Alert
+	rawListeners      []chan *monitor.MinuteResponse
+	timingListeners   []chan monitor.MinuteTiming
+}
+
+var _ monitor.Source = (*FakeMonitor)(nil)
+
+// NewFakeMonitor creates a fake monitor at the given height and minute
+func NewFakeMonitor(height, minute int64) *FakeMonitor {
+	f := new(FakeMonitor)
+	f.height = height
+	f.minute = minute
+	f.blockStart = time.Now()
+	f.minuteStart = f.blockStart
+	return f
+}
+
+// the node saves the previous block during minute 0
+func (f *FakeMonitor) dbheight() int64 {
+	if f.minute == 0 {
+		return f.height - 1
+	}
+	return f.height
+}
+
+// NewMinuteListener spawns a new listener that receives events for every minute.
+func (f *FakeMonitor) NewMinuteListener() <-chan monitor.Event {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	l := make(chan monitor.Event, 25)
+	if f.drained {
+		close(l)
+		return l
+	}
+	f.minuteListeners = append(f.minuteListeners, l)
+	return l
+}
+
+// NewHeightListener spawns a new listener that receives events every time a new height is attained.
+func (f *FakeMonitor) NewHeightListener() <-chan int64 {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	l := make(chan int64, 6)
+	if f.drained {
+		close(l)
+		return l
+	}
+	f.heightListeners = append(f.heightListeners, l)
+	return l
+}
+
+// NewDBHeightListener spawns a new listener that receives events every time a new DBHeight is attained.
+func (f *FakeMonitor) NewDBHeightListener() <-chan int64 {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	l := make(chan int64, 6)
+	if f.drained {
+		close(l)
+		return l
+	}
+	f.dbheightListeners = append(f.dbheightListeners, l)
+	return l
+}
+
+// NewErrorListener spawns a new listener that receives errors sent via SendError.
+func (f *FakeMonitor) NewErrorListener() <-chan error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	l := make(chan error, 6)
+	if f.drained {
+		close(l)
+		return l
+	}
+	f.errorListeners = append(f.errorListeners, l)
+	return l
+}
+
+// NewAlertListener spawns a new listener that receives alerts sent via SendAlert.
+func (f *FakeMonitor) NewAlertListener() <-chan monitor.Alert {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	l := make(chan monitor.Alert, 25)
+	if f.drained {
+		close(l)
+		return l
+	}
+	f.alertListeners = append(f.alertListeners, l)
+	return l
+}
+
+// NewRawListener spawns a new listener that receives responses sent via SendRaw.
+func (f *FakeMonitor) NewRawListener() <-chan *monitor.MinuteResponse {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	l := make(chan *monitor.MinuteResponse, 6)
+	if f.drained {
+		close(l)
+		return l
+	}
+	f.rawListeners = append(f.rawListeners, l)
+	return l
+}
+
+// NewMinuteTimingListener spawns a new listener that receives timings sent via SendMinuteTiming.
+func (f *FakeMonitor) NewMinuteTimingListener() <-chan monitor.MinuteTiming {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	l := make(chan monitor.MinuteTiming, 6)
+	if f.drained {
+		close(l)
+		return l
+	}
+	f.timingListeners = append(f.timingListeners, l)
+	return l
+}
+
+// NewBatchListener spawns a new listener that receives minute events in batches of size,
+// or once delay has passed since the first event of a batch, like the monitor's. The
+// incomplete batch is sent when the fake is drained.
+func (f *FakeMonitor) NewBatchListener(size int, delay time.Duration) <-chan []monitor.Event {
+	if size < 1 {
+		size = 1
+	}
+	in := f.NewMinuteListener()
+	out := make(chan []monitor.Event, 25)
+
+	go func() {
+		var batch []monitor.Event
+		var flush <-chan time.Time
+		var timer *time.Timer
+
+		send := func() {
+			select {
+			case out <- batch:
+			default:
+			}
+			batch = nil
+			flush = nil
+			if timer != nil {
+				timer.Stop()
+			}
+		}
+
+		for {
+			select {
+			case e, ok := <-in:
+				if !ok {
+					if len(batch) > 0 {
+						send()
+					}
+					close(out)
+					return
+				}
+				batch = append(batch, e)
+				if len(batch) >= size {
+					send()
+				} else if len(batch) == 1 && delay > 0 {
+					timer = time.NewTimer(delay)
+					flush = timer.C
+				}
+			case <-flush:
+				send()
+			}
+		}
+	}()
+
+	return out
+}
+
+// GetCurrentMinute returns the current height, dbheight, and minute.
+func (f *FakeMonitor) GetCurrentMinute() (int64, int64, int64) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.height, f.dbheight(), f.minute
+}
+
+// State returns a snapshot of the fake's state. The fake is always healthy.
+func (f *FakeMonitor) State() monitor.State {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return monitor.State{
+		DBHeight:    f.dbheight(),
+		Height:      f.height,
+		Minute:      f.minute,
+		LastPoll:    time.Now(),
+		BlockStart:  f.blockStart,
+		MinuteStart: f.minuteStart,
+		Healthy:     true,
+	}
+}
+
+// LatencyHistogram returns the histogram set via SetLatencyHistogram.
+func (f *FakeMonitor) LatencyHistogram() monitor.LatencyHistogram {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.latency
+}
+
+// RevealLatency returns the histogram set via SetRevealLatency.
+func (f *FakeMonitor) RevealLatency() monitor.LatencyHistogram {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.reveal
+}
+
+// SetLatencyHistogram sets the histogram returned by LatencyHistogram.
+func (f *FakeMonitor) SetLatencyHistogram(h monitor.LatencyHistogram) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.latency = h
+}
+
+// SetRevealLatency sets the histogram returned by RevealLatency.
+func (f *FakeMonitor) SetRevealLatency(h monitor.LatencyHistogram) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.reveal = h
+}
+
+// ThroughputStats returns the statistics set via SetThroughputStats.
+func (f *FakeMonitor) ThroughputStats() monitor.ThroughputStats {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.throughput
+}
+
+// SetThroughputStats sets the statistics returned by ThroughputStats.
+func (f *FakeMonitor) SetThroughputStats(s monitor.ThroughputStats) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.throughput = s
+}
+
+// Stop stops the fake. Advancing a stopped fake has no effect.
+func (f *FakeMonitor) Stop() {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.stopped = true
+}
+
+// AdvanceMinute moves to the next minute, or to minute 0 of the next height after
+// minute 9, and notifies listeners.
+func (f *FakeMonitor) AdvanceMinute() {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.stopped {
+		return
+	}
+
+	oldDBHeight := f.dbheight()
+	f.minuteStart = time.Now()
+	f.minute++
+	newHeight := f.minute > 9
+	if newHeight {
+		f.height++
+		f.minute = 0
+		f.blockStart = f.minuteStart
+	}
+	f.notify(newHeight, f.dbheight() > oldDBHeight)
+}
+
+// AdvanceHeight moves to minute 0 of the next height and notifies listeners.
+// Skipped minutes are not sent.
+func (f *FakeMonitor) AdvanceHeight() {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.stopped {
+		return
+	}
+
+	oldDBHeight := f.dbheight()
+	f.height++
+	f.minute = 0
+	f.minuteStart = time.Now()
+	f.blockStart = f.minuteStart
+	f.notify(true, f.dbheight() > oldDBHeight)
+}
+
+// Drain stops the fake and closes all listeners, like monitor.StopAndDrain. Events that
+// were sent before remain in the listeners' buffers. Listeners created afterwards are
+// closed right away.
+func (f *FakeMonitor) Drain() {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.drained {
+		return
+	}
+	f.stopped = true
+	f.drained = true
+	for _, l := range f.minuteListeners {
+		close(l)
+	}
+	for _, l := range f.heightListeners {
+		close(l)
+	}
+	for _, l := range f.dbheightListeners {
+		close(l)
+	}
+	for _, l := range f.errorListeners {
+		close(l)
+	}
+	for _, l := range f.alertListeners {
+		close(l)
+	}
+	for _, l := range f.rawListeners {
+		close(l)
+	}
+	for _, l := range f.timingListeners {
+		close(l)
+	}
+}
+
+// SendEvent sends the event to all minute listeners as is, without changing the fake's
+// height and minute, ie to test events the fake doesn't produce on its own.
+func (f *FakeMonitor) SendEvent(e monitor.Event) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.stopped {
+		return
+	}
+	for _, l := range f.minuteListeners {
+		select {
+		case l <- e:
+		default:
+		}
+	}
+}
+
+// SendAlert sends the alert to all alert listeners.
+func (f *FakeMonitor) SendAlert(a monitor.Alert) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.stopped {
+		return
+	}
+	for _, l := range f.alertListeners {
+		select {
+		case l <- a:
+		default:
+		}
+	}
+}
+
+// SendRaw sends the response to all raw listeners.
+func (f *FakeMonitor) SendRaw(resp *monitor.MinuteResponse) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.stopped {
+		return
+	}
+	for _, l := range f.rawListeners {
+		select {
+		case l <- resp:
+		default:
+		}
+	}
+}
+
+// SendMinuteTiming sends the timing to all minute timing listeners.
+func (f *FakeMonitor) SendMinuteTiming(t monitor.MinuteTiming) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.stopped {
+		return
+	}
+	for _, l := range f.timingListeners {
+		select {
+		case l <- t:
+		default:
+		}
+	}
+}
+
+// SendError sends the error to all error listeners.
+func (f *FakeMonitor) SendError(err error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.drained {
+		return
+	}
+	for _, l := range f.errorListeners {
+		select {
+		case l <- err:
+		default:
+		}
+	}
+}
+
+// notify must be called with mtx held
+func (f *FakeMonitor) notify(height, dbheight bool) {
+	f.sequence++
+	e := monitor.Event{
+		DBHeight:    f.dbheight(),
+		Height:      f.height,
+		Minute:      f.minute,
+		BlockStart:  f.blockStart,
+		MinuteStart: f.minuteStart,
+		NodeTime:    f.minuteStart,
+		Sequence:    f.sequence,
+	}
+
+	if height {
+		for _, l := range f.heightListeners {
+			select {
+			case l <- e.Height:
+			default:
+			}
+		}
+	}
+	if dbheight {
+		for _, l := range f.dbheightListeners {
+			select {
+			case l <- e.DBHeight:
+			default:
+			}
+		}
+	}
+	for _, l := range f.minuteListeners {
+		select {
+		case l <- e:
+		default:
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package monitortest
+
+import (
+	"errors"
+	"testing"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+func TestFakeMonitor(t *testing.T) {
+	f := NewFakeMonitor(10, 8)
+	minutes := f.NewMinuteListener()
+	heights := f.NewHeightListener()
+	dbheights := f.NewDBHeightListener()
+	errs := f.NewErrorListener()
+
+	f.AdvanceMinute() // 10/9
+	if e := <-minutes; e.Height != 10 || e.Minute != 9 || e.DBHeight != 10 {
+		t.Errorf("unexpected event %+v", e)
+	}
+
+	f.AdvanceMinute() // 11/0
+	if e := <-minutes; e.Height != 11 || e.Minute != 0 || e.DBHeight != 10 {
+		t.Errorf("unexpected event %+v", e)
+	}
+	if h := <-heights; h != 11 {
+		t.Errorf("unexpected height %d", h)
+	}
+
+	f.AdvanceMinute() // 11/1
+	<-minutes
+	if h := <-dbheights; h != 11 {
+		t.Errorf("unexpected dbheight %d", h)
+	}
+
+	f.AdvanceHeight() // 12/0
+	if e := <-minutes; e.Height != 12 || e.Minute != 0 {
+		t.Errorf("unexpected event %+v", e)
+	}
+	if h := <-heights; h != 12 {
+		t.Errorf("unexpected height %d", h)
+	}
+	if h, d, m := f.GetCurrentMinute(); h != 12 || d != 11 || m != 0 {
+		t.Errorf("unexpected current minute %d %d %d", h, d, m)
+	}
+
+	f.SendError(errors.New("test"))
+	if err := <-errs; err.Error() != "test" {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	f.Stop()
+	f.AdvanceMinute()
+	select {
+	case e := <-minutes:
+		t.Errorf("event after stop %+v", e)
+	default:
+	}
+}
+
+func TestFakeMonitor_Send(t *testing.T) {
+	f := NewFakeMonitor(10, 8)
+	minutes := f.NewMinuteListener()
+	alerts := f.NewAlertListener()
+	raw := f.NewRawListener()
+	timings := f.NewMinuteTimingListener()
+
+	f.SendEvent(monitor.Event{Height: 3, Minute: 4})
+	if e := <-minutes; e.Height != 3 || e.Minute != 4 {
+		t.Errorf("unexpected event %+v", e)
+	}
+	if h, _, m := f.GetCurrentMinute(); h != 10 || m != 8 {
+		t.Errorf("sent event changed the state to %d/%d", h, m)
+	}
+
+	f.SendAlert(monitor.Alert{Rule: "test"})
+	if a := <-alerts; a.Rule != "test" {
+		t.Errorf("unexpected alert %+v", a)
+	}
+	f.SendRaw(&monitor.MinuteResponse{LeaderHeight: 12})
+	if r := <-raw; r.LeaderHeight != 12 {
+		t.Errorf("unexpected response %+v", r)
+	}
+	f.SendMinuteTiming(monitor.MinuteTiming{Height: 10, Minute: 8})
+	if mt := <-timings; mt.Height != 10 || mt.Minute != 8 {
+		t.Errorf("unexpected timing %+v", mt)
+	}
+
+	f.SetLatencyHistogram(monitor.LatencyHistogram{Count: 3})
+	f.SetRevealLatency(monitor.LatencyHistogram{Count: 4})
+	if f.LatencyHistogram().Count != 3 || f.RevealLatency().Count != 4 {
+		t.Errorf("unexpected histograms %+v %+v", f.LatencyHistogram(), f.RevealLatency())
+	}
+}
+
+func TestFakeMonitor_Drain(t *testing.T) {
+	f := NewFakeMonitor(10, 8)
+	minutes := f.NewMinuteListener()
+	batches := f.NewBatchListener(3, 0)
+
+	f.AdvanceMinute()
+	f.AdvanceMinute()
+	f.Drain()
+	f.AdvanceMinute()
+	f.SendError(errors.New("after drain"))
+
+	var got []int64
+	for e := range minutes {
+		got = append(got, e.Minute)
+	}
+	if len(got) != 2 || got[0] != 9 || got[1] != 0 {
+		t.Errorf("unexpected drained minutes %v", got)
+	}
+
+	if b := <-batches; len(b) != 2 {
+		t.Errorf("incomplete batch not sent %+v", b)
+	}
+	if _, ok := <-batches; ok {
+		t.Error("batch listener not closed")
+	}
+	if _, ok := <-f.NewAlertListener(); ok {
+		t.Error("listener created after the drain is open")
+	}
+}
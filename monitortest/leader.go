@@ -0,0 +1,46 @@
+package monitortest
+
+import (
+	"sync"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+// FakeLeader is a monitor.Leader whose leadership is set by the test
+type FakeLeader struct {
+	mtx    sync.Mutex
+	leader bool
+	calls  int
+}
+
+var _ monitor.Leader = (*FakeLeader)(nil)
+
+// NewFakeLeader creates a fake leader that starts out as the leader or as a follower
+func NewFakeLeader(leader bool) *FakeLeader {
+	l := new(FakeLeader)
+	l.leader = leader
+	return l
+}
+
+// IsLeader returns true while the fake is the leader
+func (l *FakeLeader) IsLeader() bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.calls++
+	return l.leader
+}
+
+// SetLeader makes the fake the leader or a follower
+func (l *FakeLeader) SetLeader(leader bool) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.leader = leader
+}
+
+// Calls returns the number of times IsLeader was called, which can be used to wait
+// until the code under test has checked the leadership
+func (l *FakeLeader) Calls() int {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.calls
+}
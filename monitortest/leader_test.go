@@ -0,0 +1,43 @@
+package monitortest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+func TestFakeLeader(t *testing.T) {
+	f := NewFakeMonitor(10, 1)
+	l := NewFakeLeader(false)
+	d := monitor.NewDispatcher(f, monitor.DispatcherConfig{Leader: l})
+	defer d.Stop()
+
+	delivered := make(chan monitor.Event, 6)
+	if err := d.Add("fake", monitor.SinkFunc(func(ctx context.Context, e monitor.Event) error {
+		delivered <- e
+		return nil
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	f.AdvanceMinute() // 10/2, discarded by the follower
+	for i := 0; l.Calls() == 0; i++ {
+		if i == 100 {
+			t.Fatal("leadership not checked")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	l.SetLeader(true)
+	f.AdvanceMinute() // 10/3
+
+	select {
+	case e := <-delivered:
+		if e.Minute != 3 {
+			t.Errorf("follower delivered %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("leader did not deliver")
+	}
+}
@@ -0,0 +1,55 @@
+package monitortest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+// NewReplay creates a simulation that plays back responses recorded via
+// monitor.Config.Recorder. Each response is active for as long as it was during
+// the recording, divided by speed, ie a speed of 60 plays an hour in a minute.
+// A speed of zero or less plays at the original speed.
+//
+// The node's own timestamps in the responses are not adjusted, so estimates made
+// from them are only accurate at the original speed.
+func NewReplay(r io.Reader, clock monitor.Clock, speed float64) (*Simulation, error) {
+	if speed <= 0 {
+		speed = 1
+	}
+
+	var recs []monitor.Recording
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec monitor.Recording
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		recs = append(recs, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	steps := make([]Step, 0, len(recs))
+	for i, rec := range recs {
+		var step Step
+		if err := json.Unmarshal(rec.Response, &step.Response); err != nil {
+			return nil, fmt.Errorf("recording %d: %v", i, err)
+		}
+		step.Response.Raw = rec.Response
+		if i+1 < len(recs) {
+			step.Duration = time.Duration(float64(recs[i+1].Time.Sub(rec.Time)) / speed)
+		}
+		steps = append(steps, step)
+	}
+	return NewSimulation(clock, steps...), nil
+}
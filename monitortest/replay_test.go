@@ -0,0 +1,65 @@
+package monitortest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+func TestReplay(t *testing.T) {
+	// record a simulated node
+	clock := NewFakeClock(time.Unix(1500000000, 0))
+	sim := NewSimulation(clock, Minutes(10, 8, 3, time.Second*30)...)
+
+	var recording bytes.Buffer
+	m, err := monitor.NewMonitorWithConfig("http://simulation/v2", monitor.Config{
+		HTTPClient: sim.Client(),
+		Clock:      clock,
+		Recorder:   &recording,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener := m.NewMinuteListener()
+	for received := 0; received < 2; {
+		clock.Advance(time.Second)
+		time.Sleep(time.Millisecond)
+		select {
+		case <-listener:
+			received++
+		default:
+		}
+	}
+	m.Stop()
+
+	lines := strings.Count(recording.String(), "\n")
+	if lines < 3 {
+		t.Fatalf("too few recordings: %d", lines)
+	}
+
+	// play it back ten times as fast
+	replayClock := NewFakeClock(time.Unix(1600000000, 0))
+	replay, err := NewReplay(&recording, replayClock, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := replay.Current(); r.LeaderHeight != 10 || r.Minute != 8 || r.Raw == nil {
+		t.Errorf("unexpected first response %+v", r)
+	}
+	// the recording has minute changes roughly every 30 seconds
+	replayClock.Advance(time.Millisecond * 3500)
+	if r := replay.Current(); r.Minute != 9 {
+		t.Errorf("unexpected response after 3.5 seconds %+v", r)
+	}
+	replayClock.Advance(time.Second * 3)
+	if r := replay.Current(); r.LeaderHeight != 11 || r.Minute != 0 {
+		t.Errorf("unexpected response after 6.5 seconds %+v", r)
+	}
+
+	if _, err := NewReplay(strings.NewReader("{invalid"), nil, 1); err == nil {
+		t.Error("no error for invalid recording")
+	}
+}
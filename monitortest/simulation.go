@@ -0,0 +1,142 @@
+package monitortest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+// Step is a single scripted node state of a Simulation
+type Step struct {
+	// The "current-minute" response while the step is active.
+	// If Response.Raw is set, it is sent instead of the struct's fields.
+	Response monitor.MinuteResponse
+	// How long the step is active
+	Duration time.Duration
+}
+
+// Simulation is an http.RoundTripper that answers API requests with a scripted sequence
+// of responses instead of a node. Each step is active for its duration, starting with
+// the first step when the simulation is created. The last step stays active forever.
+//
+// Steps can contain anything a node might report, including minute 10, stalls
+// (a long duration), and regressions (a lower height than the previous step).
+type Simulation struct {
+	now   func() time.Time
+	start time.Time
+	steps []Step
+}
+
+// NewSimulation creates a simulation that plays the steps on the clock.
+// If clock is nil, the system clock is used.
+func NewSimulation(clock monitor.Clock, steps ...Step) *Simulation {
+	s := new(Simulation)
+	s.now = time.Now
+	if clock != nil {
+		s.now = clock.Now
+	}
+	s.start = s.now()
+	s.steps = steps
+	return s
+}
+
+// Minutes creates a sequence of n consecutive minutes starting at height and minute,
+// each lasting d. The node's dbheight is one lower than the height during minute 0.
+func Minutes(height, minute int64, n int, d time.Duration) []Step {
+	steps := make([]Step, 0, n)
+	for i := 0; i < n; i++ {
+		dbheight := height
+		if minute == 0 {
+			dbheight--
+		}
+		steps = append(steps, Step{
+			Response: monitor.MinuteResponse{
+				LeaderHeight:  height,
+				DBHeight:      dbheight,
+				Minute:        minute,
+				DBlockSeconds: int64(d.Seconds() * 10),
+			},
+			Duration: d,
+		})
+		minute++
+		if minute > 9 {
+			minute = 0
+			height++
+		}
+	}
+	return steps
+}
+
+// Client returns an http client that sends requests to the simulation,
+// for use as monitor.Config.HTTPClient
+func (s *Simulation) Client() *http.Client {
+	return &http.Client{Transport: s}
+}
+
+// Current returns the response of the active step
+func (s *Simulation) Current() monitor.MinuteResponse {
+	if len(s.steps) == 0 {
+		return monitor.MinuteResponse{}
+	}
+	elapsed := s.now().Sub(s.start)
+	for _, step := range s.steps {
+		if elapsed < step.Duration {
+			return step.Response
+		}
+		elapsed -= step.Duration
+	}
+	return s.steps[len(s.steps)-1].Response
+}
+
+// RoundTrip answers "current-minute" and "heights" requests from the active step.
+// Other methods return a JSON-RPC method not found error.
+func (s *Simulation) RoundTrip(r *http.Request) (*http.Response, error) {
+	var req struct {
+		ID     interface{} `json:"id"`
+		Method string      `json:"method"`
+	}
+	if r.Body != nil {
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, err
+		}
+	}
+
+	current := s.Current()
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	switch req.Method {
+	case "current-minute":
+		if current.Raw != nil {
+			resp["result"] = current.Raw
+		} else {
+			resp["result"] = current
+		}
+	case "heights":
+		resp["result"] = monitor.HeightsResponse{
+			DirectoryBlockHeight: current.DBHeight,
+			LeaderHeight:         current.LeaderHeight,
+		}
+	default:
+		resp["error"] = map[string]interface{}{"code": -32601, "message": "Method not found"}
+	}
+
+	js, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Status:        "200 OK",
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(js)),
+		ContentLength: int64(len(js)),
+		Request:       r,
+	}, nil
+}
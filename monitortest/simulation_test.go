@@ -0,0 +1,57 @@
+package monitortest
+
+import (
+	"testing"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+func TestMinutes(t *testing.T) {
+	steps := Minutes(10, 8, 4, time.Minute)
+	want := [][3]int64{{10, 10, 8}, {10, 10, 9}, {11, 10, 0}, {11, 11, 1}}
+	if len(steps) != len(want) {
+		t.Fatalf("unexpected number of steps %d", len(steps))
+	}
+	for i, s := range steps {
+		r := s.Response
+		if r.LeaderHeight != want[i][0] || r.DBHeight != want[i][1] || r.Minute != want[i][2] || r.DBlockSeconds != 600 {
+			t.Errorf("step %d: unexpected response %+v", i, r)
+		}
+	}
+}
+
+func TestSimulation_Monitor(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1500000000, 0))
+	steps := Minutes(10, 8, 2, time.Minute)
+	steps = append(steps, Step{Response: monitor.MinuteResponse{LeaderHeight: 10, DBHeight: 10, Minute: 10}, Duration: time.Minute}) // minute 10
+	steps = append(steps, Minutes(11, 0, 1, time.Minute)...)
+	sim := NewSimulation(clock, steps...)
+
+	m, err := monitor.NewMonitorWithConfig("http://simulation/v2", monitor.Config{HTTPClient: sim.Client(), Clock: clock})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	if s := m.State(); s.Height != 10 || s.Minute != 8 {
+		t.Errorf("unexpected initial state %+v", s)
+	}
+
+	listener := m.NewMinuteListener()
+	var got []monitor.Event
+	for i := 0; i < 4*60 && len(got) < 2; i++ {
+		clock.Advance(time.Second)
+		time.Sleep(time.Millisecond)
+		select {
+		case e := <-listener:
+			got = append(got, e)
+		default:
+		}
+	}
+
+	// minute 10 is not a real minute and is not reported
+	if len(got) != 2 || got[0].Minute != 9 || got[1].Height != 11 || got[1].Minute != 0 {
+		t.Errorf("unexpected events %+v", got)
+	}
+}
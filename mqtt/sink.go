@@ -0,0 +1,165 @@
+// Package mqtt publishes the current height and minute to MQTT topics as retained messages.
+//
+// The package does not depend on an MQTT client library. Any client can be
+// used by implementing Publisher, for example by wrapping the Publish method
+// of an eclipse/paho.mqtt.golang client and waiting on its token.
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+// Publisher publishes a single message to an MQTT broker
+type Publisher interface {
+	Publish(topic string, qos byte, retained bool, payload []byte) error
+}
+
+// Source is the subset of a monitor used by the sink
+type Source interface {
+	NewMinuteListener() <-chan monitor.Event
+}
+
+// Topics holds the topic names the current state is published to.
+// An empty topic disables publishing of that value.
+type Topics struct {
+	// JSON encoded monitor.Event
+	Event string
+	// Decimal string of the height
+	Height string
+	// Decimal string of the dbheight
+	DBHeight string
+	// Decimal string of the minute
+	Minute string
+}
+
+// DefaultTopics are the topics used if no others are specified
+var DefaultTopics = Topics{
+	Event:    "factom/event",
+	Height:   "factom/height",
+	DBHeight: "factom/dbheight",
+	Minute:   "factom/minute",
+}
+
+// QoS is the quality of service level used for all messages
+var QoS byte = 1
+
+// Sink publishes retained messages for every minute event, so devices that
+// subscribe at any time immediately receive the current state.
+// Height and dbheight are only republished when they change.
+type Sink struct {
+	pub    Publisher
+	topics Topics
+
+	mtx      sync.Mutex
+	height   int64 // the last published height, -1 before the first event
+	dbheight int64
+
+	errors chan error
+
+	close  chan interface{}
+	done   chan interface{}
+	closer sync.Once
+}
+
+var _ monitor.Sink = (*Sink)(nil)
+
+// NewSink creates a new sink that begins publishing events from the source immediately.
+// Starts a goroutine that can be stopped via sink.Stop().
+// If src is nil, the sink only publishes the events passed to HandleEvent, ie by a
+// monitor.Dispatcher.
+func NewSink(pub Publisher, src Source, topics Topics) *Sink {
+	s := new(Sink)
+	s.pub = pub
+	s.topics = topics
+	s.height, s.dbheight = -1, -1
+	s.errors = make(chan error, 6)
+	s.close = make(chan interface{})
+	s.done = make(chan interface{})
+	if src == nil {
+		close(s.done)
+		return s
+	}
+	go s.run(src.NewMinuteListener())
+	return s
+}
+
+// Errors returns a channel that receives errors from failed publish attempts.
+// Errors are dropped if the channel is not read.
+func (s *Sink) Errors() <-chan error {
+	return s.errors
+}
+
+func (s *Sink) run(l <-chan monitor.Event) {
+	defer close(s.done)
+	for {
+		select {
+		case <-s.close:
+			return
+		case e, ok := <-l:
+			if !ok { // drained
+				return
+			}
+			if err := s.HandleEvent(context.Background(), e); err != nil {
+				s.notifyError(err)
+			}
+		}
+	}
+}
+
+// HandleEvent publishes the event and the values that changed. It implements
+// monitor.Sink, so a dispatcher can retry events that failed to publish.
+// Returns the first error, the other topics are published regardless.
+func (s *Sink) HandleEvent(ctx context.Context, e monitor.Event) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var first error
+	publish := func(topic string, payload []byte) error {
+		if topic == "" {
+			return nil
+		}
+		err := s.pub.Publish(topic, QoS, true, payload)
+		if err != nil && first == nil {
+			first = err
+		}
+		return err
+	}
+	publishInt := func(topic string, v int64) error {
+		return publish(topic, []byte(strconv.FormatInt(v, 10)))
+	}
+
+	if s.topics.Event != "" {
+		if js, err := json.Marshal(e); err == nil {
+			publish(s.topics.Event, js)
+		}
+	}
+	// a retried event publishes the heights again if they failed
+	if e.Height != s.height && publishInt(s.topics.Height, e.Height) == nil {
+		s.height = e.Height
+	}
+	if e.DBHeight != s.dbheight && publishInt(s.topics.DBHeight, e.DBHeight) == nil {
+		s.dbheight = e.DBHeight
+	}
+	publishInt(s.topics.Minute, e.Minute)
+	return first
+}
+
+func (s *Sink) notifyError(err error) {
+	select {
+	case s.errors <- err:
+	default:
+	}
+}
+
+// Stop halts publishing. It does not disconnect the client.
+func (s *Sink) Stop() {
+	s.closer.Do(func() {
+		close(s.close)
+		<-s.done
+	})
+}
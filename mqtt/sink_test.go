@@ -0,0 +1,116 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+	"github.com/WhoSoup/factom-monitor/monitortest"
+)
+
+type message struct {
+	topic    string
+	retained bool
+	payload  string
+}
+
+type fakePublisher struct {
+	mtx      sync.Mutex
+	messages []message
+}
+
+func (p *fakePublisher) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.messages = append(p.messages, message{topic, retained, string(payload)})
+	return nil
+}
+
+func (p *fakePublisher) wait(n int) []message {
+	for i := 0; i < 50; i++ {
+		p.mtx.Lock()
+		m := p.messages
+		p.mtx.Unlock()
+		if len(m) >= n {
+			return m
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	return nil
+}
+
+func marshal(t *testing.T, e monitor.Event) string {
+	js, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(js)
+}
+
+func TestSink(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(10, 0)
+	events := fake.NewMinuteListener()
+	pub := new(fakePublisher)
+	s := NewSink(pub, fake, DefaultTopics)
+	defer s.Stop()
+
+	fake.AdvanceMinute()
+	fake.AdvanceMinute()
+	e1, e2 := <-events, <-events
+
+	want := []message{
+		{"factom/event", true, marshal(t, e1)},
+		{"factom/height", true, "10"},
+		{"factom/dbheight", true, "10"},
+		{"factom/minute", true, "1"},
+		{"factom/event", true, marshal(t, e2)},
+		{"factom/minute", true, "2"},
+	}
+
+	got := pub.wait(len(want))
+	if len(got) != len(want) {
+		t.Fatalf("unexpected message count. got = %v, want = %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("message %d: got = %+v, want = %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSink_Drained(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(10, 1)
+	pub := new(fakePublisher)
+	s := NewSink(pub, fake, DefaultTopics)
+	defer s.Stop()
+
+	fake.Drain()
+
+	select {
+	case <-s.done:
+	case <-time.After(time.Second):
+		t.Fatal("sink did not exit after the source was drained")
+	}
+	pub.mtx.Lock()
+	defer pub.mtx.Unlock()
+	if len(pub.messages) > 0 {
+		t.Errorf("sink published after the source was drained: %v", pub.messages)
+	}
+}
+
+func TestSink_Dispatcher(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(10, 1)
+	d := monitor.NewDispatcher(fake, monitor.DispatcherConfig{})
+	defer d.Stop()
+	pub := new(fakePublisher)
+	if err := d.Add("mqtt", NewSink(pub, nil, Topics{Minute: "factom/minute"})); err != nil {
+		t.Fatal(err)
+	}
+
+	fake.AdvanceMinute()
+	if got := pub.wait(1); len(got) != 1 || got[0].payload != "2" {
+		t.Errorf("unexpected messages %v", got)
+	}
+}
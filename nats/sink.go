@@ -0,0 +1,192 @@
+// Package nats publishes monitor events to NATS subjects.
+//
+// The package does not depend on a NATS client library. Any client that
+// implements Publisher, such as *nats.Conn from github.com/nats-io/nats.go,
+// can be used.
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+// Publisher is the subset of a NATS connection used by the sink
+type Publisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// Source is the subset of a monitor used by the sink
+type Source interface {
+	NewMinuteListener() <-chan monitor.Event
+	NewHeightListener() <-chan int64
+	NewDBHeightListener() <-chan int64
+	NewErrorListener() <-chan error
+}
+
+// Subjects holds the subject names events are published to.
+// An empty subject disables publishing of that event type.
+type Subjects struct {
+	Minute   string
+	Height   string
+	DBHeight string
+	Error    string
+}
+
+// DefaultSubjects are the subjects used if no others are specified
+var DefaultSubjects = Subjects{
+	Minute:   "factom.minute",
+	Height:   "factom.height",
+	DBHeight: "factom.dbheight",
+	Error:    "factom.error",
+}
+
+// Sink reads events from a monitor and publishes them to NATS.
+// Minute events are published as JSON encoded monitor.Event, heights as decimal
+// strings, and errors as a JSON object with an "error" field.
+type Sink struct {
+	conn     Publisher
+	subjects Subjects
+
+	errors chan error
+
+	close  chan interface{}
+	wg     sync.WaitGroup // the publishing goroutines
+	done   chan interface{}
+	closer sync.Once
+}
+
+var _ monitor.Sink = (*Sink)(nil)
+
+// NewSink creates a new sink that begins publishing events from the source immediately.
+// Starts goroutines that can be stopped via sink.Stop().
+// If src is nil, the sink only publishes the minute events passed to HandleEvent, ie
+// by a monitor.Dispatcher.
+func NewSink(conn Publisher, src Source, subjects Subjects) *Sink {
+	s := new(Sink)
+	s.conn = conn
+	s.subjects = subjects
+	s.errors = make(chan error, 6)
+	s.close = make(chan interface{})
+	s.done = make(chan interface{})
+	if src == nil {
+		close(s.done)
+		return s
+	}
+
+	if subjects.Minute != "" {
+		s.wg.Add(1)
+		go s.minutes(src.NewMinuteListener())
+	}
+	if subjects.Height != "" {
+		s.wg.Add(1)
+		go s.heights(subjects.Height, src.NewHeightListener())
+	}
+	if subjects.DBHeight != "" {
+		s.wg.Add(1)
+		go s.heights(subjects.DBHeight, src.NewDBHeightListener())
+	}
+	if subjects.Error != "" {
+		s.wg.Add(1)
+		go s.monitorErrors(src.NewErrorListener())
+	}
+	go func() {
+		s.wg.Wait()
+		close(s.done)
+	}()
+	return s
+}
+
+// Errors returns a channel that receives errors from failed publish attempts.
+// Errors are dropped if the channel is not read.
+func (s *Sink) Errors() <-chan error {
+	return s.errors
+}
+
+func (s *Sink) publish(subject string, data []byte) {
+	if err := s.conn.Publish(subject, data); err != nil {
+		s.notifyError(err)
+	}
+}
+
+func (s *Sink) notifyError(err error) {
+	select {
+	case s.errors <- err:
+	default:
+	}
+}
+
+// HandleEvent publishes a minute event to the minute subject. It implements
+// monitor.Sink, so a dispatcher can retry events that failed to publish.
+func (s *Sink) HandleEvent(ctx context.Context, e monitor.Event) error {
+	if s.subjects.Minute == "" {
+		return nil
+	}
+	js, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return s.conn.Publish(s.subjects.Minute, js)
+}
+
+func (s *Sink) minutes(l <-chan monitor.Event) {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.close:
+			return
+		case e, ok := <-l:
+			if !ok { // drained
+				return
+			}
+			if err := s.HandleEvent(context.Background(), e); err != nil {
+				s.notifyError(err)
+			}
+		}
+	}
+}
+
+func (s *Sink) heights(subject string, l <-chan int64) {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.close:
+			return
+		case h, ok := <-l:
+			if !ok {
+				return
+			}
+			s.publish(subject, []byte(strconv.FormatInt(h, 10)))
+		}
+	}
+}
+
+func (s *Sink) monitorErrors(l <-chan error) {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.close:
+			return
+		case e, ok := <-l:
+			if !ok {
+				return
+			}
+			js, err := json.Marshal(map[string]string{"error": e.Error()})
+			if err != nil {
+				continue
+			}
+			s.publish(s.subjects.Error, js)
+		}
+	}
+}
+
+// Stop halts publishing. It does not close the NATS connection.
+func (s *Sink) Stop() {
+	s.closer.Do(func() {
+		close(s.close)
+		<-s.done
+	})
+}
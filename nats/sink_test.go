@@ -0,0 +1,138 @@
+package nats
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+	"github.com/WhoSoup/factom-monitor/monitortest"
+)
+
+type fakePublisher struct {
+	mtx      sync.Mutex
+	messages map[string][]string
+	fail     error
+}
+
+func (p *fakePublisher) Publish(subject string, data []byte) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.fail != nil {
+		return p.fail
+	}
+	if p.messages == nil {
+		p.messages = make(map[string][]string)
+	}
+	p.messages[subject] = append(p.messages[subject], string(data))
+	return nil
+}
+
+// get waits until there are at least n messages on the subject
+func (p *fakePublisher) get(subject string, n int) []string {
+	for i := 0; i < 50; i++ {
+		p.mtx.Lock()
+		m := p.messages[subject]
+		p.mtx.Unlock()
+		if len(m) >= n {
+			return m
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	return nil
+}
+
+func TestSink(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(4, 9)
+	events := fake.NewMinuteListener()
+	pub := new(fakePublisher)
+	s := NewSink(pub, fake, DefaultSubjects)
+	defer s.Stop()
+
+	fake.AdvanceMinute() // 5/0, new height
+	fake.AdvanceMinute() // 5/1, new dbheight
+	fake.SendError(errors.New("foo"))
+
+	var minutes []string
+	for i := 0; i < 2; i++ {
+		js, err := json.Marshal(<-events)
+		if err != nil {
+			t.Fatal(err)
+		}
+		minutes = append(minutes, string(js))
+	}
+
+	tests := []struct {
+		subject string
+		want    []string
+	}{
+		{"factom.minute", minutes},
+		{"factom.height", []string{"5"}},
+		{"factom.dbheight", []string{"5"}},
+		{"factom.error", []string{`{"error":"foo"}`}},
+	}
+
+	for _, tt := range tests {
+		got := pub.get(tt.subject, len(tt.want))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("subject %s: got = %v, want = %v", tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestSink_Errors(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(1, 9)
+	pub := &fakePublisher{fail: errors.New("no connection")}
+	s := NewSink(pub, fake, Subjects{Height: "height"})
+	defer s.Stop()
+
+	fake.AdvanceMinute()
+
+	select {
+	case err := <-s.Errors():
+		if err != pub.fail {
+			t.Errorf("unexpected error. got = %v, want = %v", err, pub.fail)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("publish error was not reported")
+	}
+}
+
+func TestSink_Drained(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(10, 1)
+	pub := new(fakePublisher)
+	s := NewSink(pub, fake, DefaultSubjects)
+	defer s.Stop()
+
+	fake.Drain()
+
+	select {
+	case <-s.done:
+	case <-time.After(time.Second):
+		t.Fatal("sink did not exit after the source was drained")
+	}
+	pub.mtx.Lock()
+	defer pub.mtx.Unlock()
+	if len(pub.messages) > 0 {
+		t.Errorf("sink published after the source was drained: %v", pub.messages)
+	}
+}
+
+func TestSink_Dispatcher(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(10, 1)
+	d := monitor.NewDispatcher(fake, monitor.DispatcherConfig{})
+	defer d.Stop()
+	pub := new(fakePublisher)
+	if err := d.Add("nats", NewSink(pub, nil, DefaultSubjects)); err != nil {
+		t.Fatal(err)
+	}
+
+	fake.AdvanceMinute()
+	if got := pub.get("factom.minute", 1); len(got) != 1 || !strings.Contains(got[0], `"minute":2`) {
+		t.Errorf("unexpected minute messages %v", got)
+	}
+}
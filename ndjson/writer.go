@@ -0,0 +1,164 @@
+// Package ndjson writes monitor events as newline delimited JSON.
+package ndjson
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+// Source is the subset of a monitor used by the writer
+type Source interface {
+	NewMinuteListener() <-chan monitor.Event
+}
+
+// The fields that can be written. Fields are written in the order they are specified.
+const (
+	// RFC3339 timestamp of when the event was written
+	FieldTime = "time"
+	// Event.Height
+	FieldHeight = "height"
+	// Event.DBHeight
+	FieldDBHeight = "dbheight"
+	// Event.Minute
+	FieldMinute = "minute"
+	// RFC3339 timestamp of Event.BlockStart
+	FieldBlockStart = "blockstart"
+	// RFC3339 timestamp of Event.MinuteStart
+	FieldMinuteStart = "minutestart"
+)
+
+// DefaultFields is the field set used if no fields are specified
+var DefaultFields = []string{FieldTime, FieldHeight, FieldDBHeight, FieldMinute}
+
+type field func(buf *bytes.Buffer, e monitor.Event)
+
+func writeTime(buf *bytes.Buffer, t time.Time) {
+	buf.WriteByte('"')
+	buf.WriteString(t.Format(time.RFC3339Nano))
+	buf.WriteByte('"')
+}
+
+var fields = map[string]field{
+	FieldTime:        func(buf *bytes.Buffer, e monitor.Event) { writeTime(buf, time.Now()) },
+	FieldBlockStart:  func(buf *bytes.Buffer, e monitor.Event) { writeTime(buf, e.BlockStart) },
+	FieldMinuteStart: func(buf *bytes.Buffer, e monitor.Event) { writeTime(buf, e.MinuteStart) },
+	FieldHeight:      func(buf *bytes.Buffer, e monitor.Event) { buf.WriteString(strconv.FormatInt(e.Height, 10)) },
+	FieldDBHeight:    func(buf *bytes.Buffer, e monitor.Event) { buf.WriteString(strconv.FormatInt(e.DBHeight, 10)) },
+	FieldMinute:      func(buf *bytes.Buffer, e monitor.Event) { buf.WriteString(strconv.FormatInt(e.Minute, 10)) },
+}
+
+// Writer writes every minute event as a single line of JSON to an io.Writer
+type Writer struct {
+	mtx    sync.Mutex // serializes writes
+	w      io.Writer
+	names  []string
+	fields []field
+
+	errors chan error
+
+	close  chan interface{}
+	done   chan interface{}
+	closer sync.Once
+}
+
+var _ monitor.Sink = (*Writer)(nil)
+
+// NewWriter creates a new writer that begins writing events from the source immediately.
+// If no fields are specified, DefaultFields are written.
+// Returns an error if an unknown field is specified.
+// Starts a goroutine that can be stopped via writer.Stop().
+// If src is nil, the writer only writes the events passed to HandleEvent, ie by a
+// monitor.Dispatcher.
+func NewWriter(w io.Writer, src Source, fieldNames ...string) (*Writer, error) {
+	if len(fieldNames) == 0 {
+		fieldNames = DefaultFields
+	}
+
+	nw := new(Writer)
+	nw.w = w
+	for _, name := range fieldNames {
+		f, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+		nw.names = append(nw.names, name)
+		nw.fields = append(nw.fields, f)
+	}
+	nw.errors = make(chan error, 6)
+	nw.close = make(chan interface{})
+	nw.done = make(chan interface{})
+
+	if src == nil {
+		close(nw.done)
+		return nw, nil
+	}
+	go nw.run(src.NewMinuteListener())
+	return nw, nil
+}
+
+// Errors returns a channel that receives errors from failed writes.
+// Errors are dropped if the channel is not read.
+func (nw *Writer) Errors() <-chan error {
+	return nw.errors
+}
+
+// Encode returns the line for a single event, including the trailing newline
+func (nw *Writer) Encode(e monitor.Event) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range nw.fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('"')
+		buf.WriteString(nw.names[i])
+		buf.WriteString(`":`)
+		f(&buf, e)
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// HandleEvent writes the line of a single event. It implements monitor.Sink.
+func (nw *Writer) HandleEvent(ctx context.Context, e monitor.Event) error {
+	line := nw.Encode(e)
+	nw.mtx.Lock()
+	defer nw.mtx.Unlock()
+	_, err := nw.w.Write(line)
+	return err
+}
+
+func (nw *Writer) run(l <-chan monitor.Event) {
+	defer close(nw.done)
+	for {
+		select {
+		case <-nw.close:
+			return
+		case e, ok := <-l:
+			if !ok { // drained
+				return
+			}
+			if err := nw.HandleEvent(context.Background(), e); err != nil {
+				select {
+				case nw.errors <- err:
+				default:
+				}
+			}
+		}
+	}
+}
+
+// Stop halts writing. It does not close the underlying writer.
+func (nw *Writer) Stop() {
+	nw.closer.Do(func() {
+		close(nw.close)
+		<-nw.done
+	})
+}
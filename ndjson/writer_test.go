@@ -0,0 +1,128 @@
+package ndjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+	"github.com/WhoSoup/factom-monitor/monitortest"
+)
+
+type syncBuffer struct {
+	mtx sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.buf.String()
+}
+
+func TestWriter_Encode(t *testing.T) {
+	nw, err := NewWriter(new(bytes.Buffer), monitortest.NewFakeMonitor(2, 3), FieldMinute, FieldHeight)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Stop()
+
+	got := string(nw.Encode(monitor.Event{DBHeight: 1, Height: 2, Minute: 3}))
+	want := "{\"minute\":3,\"height\":2}\n"
+	if got != want {
+		t.Errorf("unexpected line. got = %q, want = %q", got, want)
+	}
+
+	if _, err := NewWriter(new(bytes.Buffer), monitortest.NewFakeMonitor(2, 3), "foo"); err == nil {
+		t.Errorf("no error for unknown field")
+	}
+}
+
+func TestWriter(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(2, 2)
+	buf := new(syncBuffer)
+	nw, err := NewWriter(buf, fake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Stop()
+
+	fake.AdvanceMinute()
+	fake.AdvanceMinute()
+
+	var lines [][]byte
+	for i := 0; i < 50; i++ {
+		lines = bytes.Split(bytes.TrimSpace([]byte(buf.String())), []byte("\n"))
+		if len(lines) == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+
+	var line struct {
+		Time     time.Time `json:"time"`
+		Height   int64     `json:"height"`
+		DBHeight int64     `json:"dbheight"`
+		Minute   int64     `json:"minute"`
+	}
+	if err := json.Unmarshal(lines[1], &line); err != nil {
+		t.Fatal(err)
+	}
+	if line.Time.IsZero() || line.Height != 2 || line.DBHeight != 2 || line.Minute != 4 {
+		t.Errorf("unexpected line: %s", lines[1])
+	}
+}
+
+func TestWriter_Drained(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(2, 2)
+	buf := new(syncBuffer)
+	nw, err := NewWriter(buf, fake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Stop()
+
+	fake.Drain()
+
+	select {
+	case <-nw.done:
+	case <-time.After(time.Second):
+		t.Fatal("writer did not exit after the source was drained")
+	}
+	if got := buf.String(); got != "" {
+		t.Errorf("writer wrote after the source was drained: %q", got)
+	}
+}
+
+func TestWriter_Dispatcher(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(10, 1)
+	d := monitor.NewDispatcher(fake, monitor.DispatcherConfig{})
+	defer d.Stop()
+	buf := new(syncBuffer)
+	nw, err := NewWriter(buf, nil, FieldHeight, FieldMinute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Add("ndjson", nw); err != nil {
+		t.Fatal(err)
+	}
+
+	fake.AdvanceMinute()
+	for i := 0; i < 50 && buf.String() == ""; i++ {
+		time.Sleep(time.Millisecond * 10)
+	}
+	if got, want := buf.String(), "{\"height\":10,\"minute\":2}\n"; got != want {
+		t.Errorf("unexpected output. got = %q, want = %q", got, want)
+	}
+}
@@ -0,0 +1,62 @@
+package monitor
+
+import "fmt"
+
+// NetworkID identifies the network a node belongs to. It is part of the header of every
+// directory block. Custom networks use the first four bytes of the sha256 hash of their name.
+type NetworkID uint32
+
+// The network ids of the standard networks
+const (
+	MainNet  NetworkID = 0xFA92E5A2
+	TestNet  NetworkID = 0xFA92E5A3
+	LocalNet NetworkID = 0xFA92E5A4
+)
+
+// String returns "mainnet", "testnet", "localnet", or the hex encoded id of a custom network
+func (id NetworkID) String() string {
+	switch id {
+	case MainNet:
+		return "mainnet"
+	case TestNet:
+		return "testnet"
+	case LocalNet:
+		return "localnet"
+	}
+	return fmt.Sprintf("%08x", uint32(id))
+}
+
+// Network returns the id of the network the node belongs to.
+// The second return value is false if it could not be detected yet.
+func (m *Monitor) Network() (NetworkID, bool) {
+	m.heightMtx.Lock()
+	defer m.heightMtx.Unlock()
+	return m.networkID, m.networkKnown
+}
+
+// detectNetwork reads the network id from the directory block at the dbheight.
+// If Config.Network is set and the node belongs to a different network, an error is returned.
+func (m *Monitor) detectNetwork(dbheight int64) error {
+	dblock, err := m.DirectoryBlockRequest(dbheight)
+	if err == nil && dblock.DBlock.Header.NetworkID == 0 {
+		err = fmt.Errorf("directory block %d has no network id", dbheight)
+	}
+	if err != nil {
+		if m.conf.Network != "" {
+			return fmt.Errorf("unable to detect network: %v", err)
+		}
+		m.notifyError(err)
+		return nil
+	}
+
+	id := NetworkID(dblock.DBlock.Header.NetworkID)
+	if m.conf.Network != "" && m.conf.Network != id.String() {
+		return fmt.Errorf("node belongs to %s, expected %s", id, m.conf.Network)
+	}
+
+	m.heightMtx.Lock()
+	m.networkID = id
+	m.networkKnown = true
+	m.heightMtx.Unlock()
+	return nil
+}
@@ -0,0 +1,59 @@
+package monitor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestNetworkID_String(t *testing.T) {
+	tests := []struct {
+		id   NetworkID
+		want string
+	}{
+		{MainNet, "mainnet"},
+		{TestNet, "testnet"},
+		{LocalNet, "localnet"},
+		{0x883e093b, "883e093b"},
+	}
+	for _, tt := range tests {
+		if got := tt.id.String(); got != tt.want {
+			t.Errorf("NetworkID(%x).String() = %s, want %s", uint32(tt.id), got, tt.want)
+		}
+	}
+}
+
+func TestMonitor_Network(t *testing.T) {
+	s := newTestServer("localhost:9847", 10, 0, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitorWithConfig("http://localhost:9847/v2", Config{Interval: time.Millisecond * 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	if id, ok := m.Network(); !ok || id != MainNet {
+		t.Errorf("unexpected network %s, %v", id, ok)
+	}
+
+	listener := m.NewMinuteListener()
+	s.tick()
+	select {
+	case e := <-listener:
+		if e.Network != "mainnet" {
+			t.Errorf("unexpected event network %q", e.Network)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+
+	if _, err := NewMonitorWithConfig("http://localhost:9847/v2", Config{Interval: time.Millisecond * 50, Network: "testnet"}); err == nil {
+		t.Error("no error for monitor on the wrong network")
+	}
+
+	s.handle("dblock-by-height", func(json.RawMessage) interface{} { return nil })
+	if _, err := NewMonitorWithConfig("http://localhost:9847/v2", Config{Interval: time.Millisecond * 50, Network: "mainnet"}); err == nil {
+		t.Error("no error for monitor with undetectable network")
+	}
+}
@@ -0,0 +1,129 @@
+// Package notify renders notification messages from text/template templates. It is
+// shared by the notifier packages, so messages can be customized the same way for
+// every destination.
+//
+// Templates are executed with a Data value and can use these functions in addition
+// to the text/template builtins:
+//
+//	duration  rounds a time.Duration to seconds, ie {{duration .Alert.Duration}}
+//	time      formats a time.Time as RFC 1123 in UTC, ie {{time .Time}}
+//	upper     converts a string to upper case
+//	lower     converts a string to lower case
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+// The kinds of notifications
+const (
+	KindFiring     = "firing"
+	KindResolved   = "resolved"
+	KindRegression = "regression"
+	KindBlock      = "block"
+	KindTiming     = "timing"
+)
+
+// Data is passed to templates
+type Data struct {
+	// The kind of notification, ie KindFiring. Notifiers may use their own kinds.
+	Kind string
+	// The alert of firing and resolved notifications
+	Alert monitor.Alert
+	// The network, empty if unknown
+	Network string
+	// The state of the node
+	Height   int64
+	DBHeight int64
+	Minute   int64
+	// The height the node reported before a regression
+	Previous int64
+	// The minute of timing notifications
+	Timing monitor.MinuteTiming
+	// The time of the notification
+	Time time.Time
+}
+
+// AlertData creates the data of an alert notification
+func AlertData(a monitor.Alert) Data {
+	kind := KindFiring
+	if a.Status == monitor.AlertResolved {
+		kind = KindResolved
+	}
+	return Data{
+		Kind:     kind,
+		Alert:    a,
+		Network:  a.Network,
+		Height:   a.Height,
+		DBHeight: a.DBHeight,
+		Minute:   a.Minute,
+		Time:     a.Time,
+	}
+}
+
+// Funcs are the functions available to templates
+var Funcs = template.FuncMap{
+	"duration": func(d time.Duration) time.Duration { return d.Round(time.Second) },
+	"time":     func(t time.Time) string { return t.UTC().Format(time.RFC1123) },
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+}
+
+// Parse parses a template with the Funcs
+func Parse(name, text string) (*template.Template, error) {
+	t, err := template.New(name).Funcs(Funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("template %s: %v", name, err)
+	}
+	return t, nil
+}
+
+// Templates holds a template for each kind of notification
+type Templates map[string]*template.Template
+
+// ParseTemplates parses the texts of each kind, using the defaults for kinds that are
+// not in custom
+func ParseTemplates(defaults, custom map[string]string) (Templates, error) {
+	ts := make(Templates)
+	for kind, text := range defaults {
+		if c, ok := custom[kind]; ok {
+			text = c
+		}
+		t, err := Parse(kind, text)
+		if err != nil {
+			return nil, err
+		}
+		ts[kind] = t
+	}
+	for kind, text := range custom {
+		if _, ok := ts[kind]; ok {
+			continue
+		}
+		t, err := Parse(kind, text)
+		if err != nil {
+			return nil, err
+		}
+		ts[kind] = t
+	}
+	return ts, nil
+}
+
+// Render executes the template of the data's kind. Returns an error if there is no
+// template for the kind.
+func (ts Templates) Render(d Data) (string, error) {
+	t, ok := ts[d.Kind]
+	if !ok {
+		return "", fmt.Errorf("no template for %q", d.Kind)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, d); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,46 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+func TestTemplates(t *testing.T) {
+	ts, err := ParseTemplates(
+		map[string]string{KindFiring: "{{.Alert.Rule}} fired", KindResolved: "{{.Alert.Rule}} resolved after {{duration .Alert.Duration}}"},
+		map[string]string{KindFiring: "{{upper .Alert.Rule}} on {{.Network}} at {{time .Time}}", "custom": "{{lower .Kind}}"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2020, 9, 13, 12, 26, 40, 0, time.UTC)
+	a := monitor.Alert{Rule: "no-new-block", Status: monitor.AlertFiring, Network: "mainnet", Since: now.Add(-time.Millisecond * 61500), Time: now}
+	tests := []struct {
+		data Data
+		want string
+	}{
+		{AlertData(a), "NO-NEW-BLOCK on mainnet at Sun, 13 Sep 2020 12:26:40 UTC"},
+		{Data{Kind: KindResolved, Alert: a}, "no-new-block resolved after 1m2s"},
+		{Data{Kind: "custom"}, "custom"},
+	}
+	for _, tt := range tests {
+		if got, err := ts.Render(tt.data); err != nil || got != tt.want {
+			t.Errorf("unexpected result. got = (%q, %v), want = %q", got, err, tt.want)
+		}
+	}
+
+	if _, err := ts.Render(Data{Kind: KindBlock}); err == nil {
+		t.Error("no error for missing template")
+	}
+	if _, err := ParseTemplates(nil, map[string]string{KindBlock: "{{"}); err == nil {
+		t.Error("no error for invalid template")
+	}
+
+	a.Status = monitor.AlertResolved
+	if d := AlertData(a); d.Kind != KindResolved || d.Network != "mainnet" {
+		t.Errorf("unexpected data %+v", d)
+	}
+}
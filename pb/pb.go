@@ -0,0 +1,191 @@
+// Package pb encodes monitor events as protocol buffers, following the schema in
+// monitor.proto, so binary consumers such as gRPC servers and Kafka sinks share one
+// wire format.
+//
+// The encoding is implemented by hand and the package does not depend on a protobuf
+// library. Code generated from monitor.proto can decode the messages.
+package pb
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+// ErrorEvent is the decoded form of an Error message
+type ErrorEvent struct {
+	Message string
+	// The time the error was reported
+	Time time.Time
+}
+
+func (e *ErrorEvent) Error() string {
+	return e.Message
+}
+
+// fields of the Envelope message
+const (
+	envelopeEvent  = 1
+	envelopeError  = 2
+	envelopeHealth = 3
+)
+
+// MarshalEvent encodes an Event message
+func MarshalEvent(e monitor.Event) []byte {
+	enc := new(encoder)
+	enc.int64(1, e.DBHeight)
+	enc.int64(2, e.Height)
+	enc.int64(3, e.Minute)
+	enc.time(4, e.BlockStart)
+	enc.time(5, e.MinuteStart)
+	enc.time(6, e.NodeTime)
+	enc.string(7, e.Network)
+	enc.uint64(8, e.Sequence)
+	enc.bool(9, e.Partial)
+	return enc.buf
+}
+
+// UnmarshalEvent decodes an Event message. Raw is always nil.
+func UnmarshalEvent(b []byte) (monitor.Event, error) {
+	var e monitor.Event
+	err := decode(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			e.DBHeight = f.int64()
+		case 2:
+			e.Height = f.int64()
+		case 3:
+			e.Minute = f.int64()
+		case 4:
+			e.BlockStart, err = f.time()
+		case 5:
+			e.MinuteStart, err = f.time()
+		case 6:
+			e.NodeTime, err = f.time()
+		case 7:
+			e.Network = f.string()
+		case 8:
+			e.Sequence = f.v
+		case 9:
+			e.Partial = f.bool()
+		}
+		return err
+	})
+	return e, err
+}
+
+// MarshalError encodes an Error message with the time the error was reported
+func MarshalError(err error, t time.Time) []byte {
+	enc := new(encoder)
+	enc.string(1, err.Error())
+	enc.time(2, t)
+	return enc.buf
+}
+
+// UnmarshalError decodes an Error message
+func UnmarshalError(b []byte) (*ErrorEvent, error) {
+	e := new(ErrorEvent)
+	err := decode(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			e.Message = f.string()
+		case 2:
+			e.Time, err = f.time()
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// MarshalHealth encodes a Health message
+func MarshalHealth(s monitor.State) []byte {
+	enc := new(encoder)
+	enc.int64(1, s.DBHeight)
+	enc.int64(2, s.Height)
+	enc.int64(3, s.Minute)
+	enc.time(4, s.LastPoll)
+	enc.time(5, s.BlockStart)
+	enc.time(6, s.MinuteStart)
+	enc.bool(7, s.Healthy)
+	enc.int64(8, int64(s.Failures))
+	if s.LastError != nil {
+		enc.string(9, s.LastError.Error())
+	}
+	return enc.buf
+}
+
+// UnmarshalHealth decodes a Health message. LastError only retains the error's message.
+func UnmarshalHealth(b []byte) (monitor.State, error) {
+	var s monitor.State
+	err := decode(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			s.DBHeight = f.int64()
+		case 2:
+			s.Height = f.int64()
+		case 3:
+			s.Minute = f.int64()
+		case 4:
+			s.LastPoll, err = f.time()
+		case 5:
+			s.BlockStart, err = f.time()
+		case 6:
+			s.MinuteStart, err = f.time()
+		case 7:
+			s.Healthy = f.bool()
+		case 8:
+			s.Failures = int(f.int64())
+		case 9:
+			s.LastError = errors.New(f.string())
+		}
+		return err
+	})
+	return s, err
+}
+
+// Marshal wraps a monitor.Event, error, or monitor.State in an Envelope message.
+// Errors are stamped with the current time, an *ErrorEvent keeps its own.
+func Marshal(v interface{}) ([]byte, error) {
+	enc := new(encoder)
+	switch v := v.(type) {
+	case monitor.Event:
+		enc.bytes(envelopeEvent, MarshalEvent(v))
+	case monitor.State:
+		enc.bytes(envelopeHealth, MarshalHealth(v))
+	case *ErrorEvent:
+		enc.bytes(envelopeError, MarshalError(v, v.Time))
+	case error:
+		enc.bytes(envelopeError, MarshalError(v, time.Now()))
+	default:
+		return nil, fmt.Errorf("pb: can't marshal %T", v)
+	}
+	return enc.buf, nil
+}
+
+// Unmarshal decodes an Envelope message into a monitor.Event, *ErrorEvent, or monitor.State.
+func Unmarshal(b []byte) (interface{}, error) {
+	var v interface{}
+	err := decode(b, func(f field) (err error) {
+		switch f.num {
+		case envelopeEvent:
+			v, err = UnmarshalEvent(f.data)
+		case envelopeError:
+			v, err = UnmarshalError(f.data)
+		case envelopeHealth:
+			v, err = UnmarshalHealth(f.data)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, errors.New("pb: empty envelope")
+	}
+	return v, nil
+}
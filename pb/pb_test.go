@@ -0,0 +1,75 @@
+package pb
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+func TestEvent(t *testing.T) {
+	now := time.Unix(1600000000, 123456789)
+	tests := []monitor.Event{
+		{},
+		{DBHeight: 1000, Height: 1001, Minute: 3, BlockStart: now, MinuteStart: now.Add(time.Minute), NodeTime: now, Network: "mainnet", Sequence: 42},
+		{Height: 5, Minute: 0, Partial: true},
+	}
+
+	for _, e := range tests {
+		got, err := UnmarshalEvent(MarshalEvent(e))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, e) {
+			t.Errorf("round trip mismatch. got = %+v, want = %+v", got, e)
+		}
+	}
+
+	// dbheight 1, height 2, minute 3, sequence 150
+	want := []byte{0x08, 0x01, 0x10, 0x02, 0x18, 0x03, 0x40, 0x96, 0x01}
+	if b := MarshalEvent(monitor.Event{DBHeight: 1, Height: 2, Minute: 3, Sequence: 150}); !reflect.DeepEqual(b, want) {
+		t.Errorf("unexpected encoding. got = %x, want = %x", b, want)
+	}
+
+	if _, err := UnmarshalEvent([]byte{0x08}); err == nil {
+		t.Error("no error for truncated message")
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+
+	b, err := Marshal(monitor.Event{Height: 7, Minute: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := Unmarshal(b); err != nil || !reflect.DeepEqual(v, monitor.Event{Height: 7, Minute: 2}) {
+		t.Errorf("unexpected event. got = (%+v, %v)", v, err)
+	}
+
+	b, err = Marshal(&ErrorEvent{Message: "boom", Time: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := Unmarshal(b); err != nil || !reflect.DeepEqual(v, &ErrorEvent{Message: "boom", Time: now}) {
+		t.Errorf("unexpected error event. got = (%+v, %v)", v, err)
+	}
+
+	state := monitor.State{Height: 7, LastPoll: now, Failures: 2, LastError: errors.New("timeout")}
+	b, err = Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := Unmarshal(b); err != nil || !reflect.DeepEqual(v, state) {
+		t.Errorf("unexpected health. got = (%+v, %v)", v, err)
+	}
+
+	if _, err := Marshal(42); err == nil {
+		t.Error("no error for unsupported type")
+	}
+	if _, err := Unmarshal(nil); err == nil {
+		t.Error("no error for empty envelope")
+	}
+}
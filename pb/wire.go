@@ -0,0 +1,149 @@
+package pb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// wire types of the protobuf encoding
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("pb: truncated message")
+
+// encoder appends fields to a message. Fields with zero values are omitted, as in proto3.
+type encoder struct {
+	buf []byte
+}
+
+func (enc *encoder) uvarint(v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	enc.buf = append(enc.buf, tmp[:n]...)
+}
+
+func (enc *encoder) tag(field, wire int) {
+	enc.uvarint(uint64(field)<<3 | uint64(wire))
+}
+
+func (enc *encoder) uint64(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	enc.tag(field, wireVarint)
+	enc.uvarint(v)
+}
+
+func (enc *encoder) int64(field int, v int64) {
+	enc.uint64(field, uint64(v))
+}
+
+func (enc *encoder) bool(field int, v bool) {
+	if v {
+		enc.uint64(field, 1)
+	}
+}
+
+func (enc *encoder) bytes(field int, b []byte) {
+	enc.tag(field, wireBytes)
+	enc.uvarint(uint64(len(b)))
+	enc.buf = append(enc.buf, b...)
+}
+
+func (enc *encoder) string(field int, s string) {
+	if s != "" {
+		enc.bytes(field, []byte(s))
+	}
+}
+
+// time encodes a google.protobuf.Timestamp, omitting the zero time
+func (enc *encoder) time(field int, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	ts := new(encoder)
+	ts.int64(1, t.Unix())
+	ts.int64(2, int64(t.Nanosecond()))
+	enc.bytes(field, ts.buf)
+}
+
+// field is a single decoded field. v holds varints, data holds length-delimited values.
+type field struct {
+	num  int
+	wire int
+	v    uint64
+	data []byte
+}
+
+func (f field) int64() int64 { return int64(f.v) }
+
+func (f field) bool() bool { return f.v != 0 }
+
+func (f field) string() string { return string(f.data) }
+
+func (f field) time() (time.Time, error) {
+	var sec, nsec int64
+	err := decode(f.data, func(f field) error {
+		switch f.num {
+		case 1:
+			sec = f.int64()
+		case 2:
+			nsec = f.int64()
+		}
+		return nil
+	})
+	return time.Unix(sec, nsec), err
+}
+
+// decode calls fn for every field of the message. Unknown fields can be ignored by fn.
+func decode(b []byte, fn func(f field) error) error {
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errTruncated
+		}
+		b = b[n:]
+
+		f := field{num: int(tag >> 3), wire: int(tag & 7)}
+		switch f.wire {
+		case wireVarint:
+			f.v, n = binary.Uvarint(b)
+			if n <= 0 {
+				return errTruncated
+			}
+			b = b[n:]
+		case wireBytes:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < l {
+				return errTruncated
+			}
+			f.data = b[n : n+int(l)]
+			b = b[n+int(l):]
+		case wireFixed64:
+			if len(b) < 8 {
+				return errTruncated
+			}
+			f.v = binary.LittleEndian.Uint64(b)
+			b = b[8:]
+		case wireFixed32:
+			if len(b) < 4 {
+				return errTruncated
+			}
+			f.v = uint64(binary.LittleEndian.Uint32(b))
+			b = b[4:]
+		default:
+			return fmt.Errorf("pb: unsupported wire type %d", f.wire)
+		}
+
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package monitor
+
+import "time"
+
+// unrevealedChain is the chain id factomd reports for entries that have been committed
+// but not yet revealed
+const unrevealedChain = "XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX"
+
+// PendingEntry is a single item of the factomd "pending-entries" API.
+type PendingEntry struct {
+	EntryHash string `json:"entryhash"`
+	ChainID   string `json:"chainid"`
+	Status    string `json:"status"`
+}
+
+// PendingEntryEvent types
+const (
+	// An entry commit was found. The chain is not known until the entry is revealed.
+	PendingCommit = "commit"
+	// The entry was revealed on a watched chain
+	PendingReveal = "reveal"
+)
+
+// PendingEntryEvent is sent to pending entry listeners when a commit appears in the
+// node's process list and when the matching reveal lands.
+type PendingEntryEvent struct {
+	Type      string `json:"type"`
+	EntryHash string `json:"entryhash"`
+	// Empty for commits
+	ChainID string `json:"chainid,omitempty"`
+	Status  string `json:"status"`
+	// The time the commit was first seen. Zero if the commit and reveal were
+	// seen at the same time.
+	Committed time.Time `json:"committed"`
+	// The time the reveal was first seen. Zero for commits.
+	Revealed time.Time `json:"revealed"`
+}
+
+type pendingEntry struct {
+	committed time.Time
+	revealed  bool
+}
+
+// PendingEntriesRequest sends a "pending-entries" API request to the configured node.
+func (m *Monitor) PendingEntriesRequest() ([]PendingEntry, error) {
+	var res []PendingEntry
+	if err := m.call("pending-entries", nil, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// WatchPendingEntries starts checking the node's pending entries every minute.
+// Pending entry listeners receive an event for every new commit and for every reveal
+// on a chain added via WatchChain.
+// Calling it more than once has no effect.
+func (m *Monitor) WatchPendingEntries() {
+	m.watchMtx.Lock()
+	defer m.watchMtx.Unlock()
+	if m.pending == nil {
+		m.pending = make(map[string]*pendingEntry)
+		m.everyMinute(func(Event) {
+			if m.due(WatcherPendingEntries) {
+				m.checkPending()
+			}
+		})
+	}
+}
+
+// NewPendingEntryListener spawns a new listener that receives commit and reveal events.
+// Each reader must have its own listener.
+func (m *Monitor) NewPendingEntryListener() <-chan PendingEntryEvent {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan PendingEntryEvent, 100)
+	m.pendingListeners = append(m.pendingListeners, l)
+	m.register("pending entry", l)
+	return l
+}
+
+func (m *Monitor) checkPending() {
+	list, err := m.PendingEntriesRequest()
+	if err != nil {
+		m.notifyError(err)
+		return
+	}
+
+	now := m.clock().Now()
+	var events []PendingEntryEvent
+	seen := make(map[string]bool, len(list))
+
+	m.watchMtx.Lock()
+	for _, pe := range list {
+		seen[pe.EntryHash] = true
+		p, ok := m.pending[pe.EntryHash]
+		if !ok {
+			p = new(pendingEntry)
+			m.pending[pe.EntryHash] = p
+		}
+
+		if pe.ChainID == unrevealedChain || pe.ChainID == "" {
+			if !ok {
+				p.committed = now
+				events = append(events, PendingEntryEvent{
+					Type:      PendingCommit,
+					EntryHash: pe.EntryHash,
+					Status:    pe.Status,
+					Committed: now,
+				})
+			}
+			continue
+		}
+
+		if p.revealed {
+			continue
+		}
+		p.revealed = true
+		if _, watched := m.chains[pe.ChainID]; watched {
+			events = append(events, PendingEntryEvent{
+				Type:      PendingReveal,
+				EntryHash: pe.EntryHash,
+				ChainID:   pe.ChainID,
+				Status:    pe.Status,
+				Committed: p.committed,
+				Revealed:  now,
+			})
+		}
+	}
+
+	// entries that left the process list were included in a block or expired
+	for hash := range m.pending {
+		if !seen[hash] {
+			delete(m.pending, hash)
+		}
+	}
+	m.watchMtx.Unlock()
+
+	m.notifyPending(events)
+}
+
+func (m *Monitor) notifyPending(events []PendingEntryEvent) {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	for _, e := range events {
+		for _, l := range m.pendingListeners {
+			select {
+			case l <- e:
+				m.delivered(l)
+			default:
+				m.dropped(l)
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package monitor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMonitor_WatchPendingEntries(t *testing.T) {
+	s := newTestServer("localhost:9874", 10, 0, time.Second*10, t)
+	defer s.stop()
+	newFakeChain(s, hash("c"), hash("a"))
+	var pending []PendingEntry
+	s.handle("pending-entries", func(json.RawMessage) interface{} {
+		return pending
+	})
+
+	m, err := NewMonitorWithConfig("http://localhost:9874/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	if err := m.WatchChain(hash("c")); err != nil {
+		t.Fatal(err)
+	}
+	m.WatchPendingEntries()
+	listener := m.NewPendingEntryListener()
+
+	expect := func(typ, entry, chain string) PendingEntryEvent {
+		select {
+		case e := <-listener:
+			if e.Type != typ || e.EntryHash != entry || e.ChainID != chain {
+				t.Errorf("unexpected event. got = %+v, want %s %s %s", e, typ, entry, chain)
+			}
+			return e
+		case <-time.After(time.Second * 2):
+			t.Fatalf("%s of %s not received", typ, entry)
+		}
+		return PendingEntryEvent{}
+	}
+
+	s.mtx.Lock()
+	pending = []PendingEntry{
+		{EntryHash: hash("1"), ChainID: unrevealedChain, Status: "AckStatusACK"},
+		{EntryHash: hash("2"), ChainID: unrevealedChain, Status: "AckStatusACK"},
+	}
+	s.mtx.Unlock()
+	s.tick()
+	expect(PendingCommit, hash("1"), "")
+	expect(PendingCommit, hash("2"), "")
+
+	s.mtx.Lock()
+	pending = []PendingEntry{
+		{EntryHash: hash("1"), ChainID: hash("c"), Status: "AckStatusACK"},
+		{EntryHash: hash("2"), ChainID: hash("d"), Status: "AckStatusACK"}, // not watched
+		{EntryHash: hash("3"), ChainID: hash("c"), Status: "AckStatusACK"},
+	}
+	s.mtx.Unlock()
+	s.tick()
+	e := expect(PendingReveal, hash("1"), hash("c"))
+	if e.Committed.IsZero() || e.Revealed.Before(e.Committed) {
+		t.Errorf("unexpected times: %+v", e)
+	}
+	e = expect(PendingReveal, hash("3"), hash("c"))
+	if !e.Committed.IsZero() {
+		t.Errorf("commit time set for entry that was never seen unrevealed: %+v", e)
+	}
+
+	s.tick() // no changes
+	select {
+	case e := <-listener:
+		t.Errorf("unexpected event %+v", e)
+	case <-time.After(time.Millisecond * 300):
+	}
+}
@@ -0,0 +1,283 @@
+// Package postgres archives monitor events in a PostgreSQL table, for historical
+// analysis of block and minute timings.
+//
+// The package only uses database/sql and does not register a driver. Open the
+// database with the driver of your choice, for example github.com/lib/pq or
+// github.com/jackc/pgx/v4/stdlib, and pass the *sql.DB to the sink.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+// Execer is the subset of a database connection used by the package.
+// It is implemented by *sql.DB, *sql.Conn, and *sql.Tx.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Source is the subset of a monitor used by the sink
+type Source interface {
+	NewBatchListener(size int, delay time.Duration) <-chan []monitor.Event
+}
+
+// Config contains the settings of a sink
+type Config struct {
+	// The table events are written to
+	Table string
+	// The maximum amount of events written in one statement
+	BatchSize int
+	// The maximum time an event is held before the batch is written
+	BatchTimeout time.Duration
+	// The time to wait before retrying a failed batch
+	RetryDelay time.Duration
+	// Retry decides when a failed batch is retried. If it gives up, the batch is
+	// dropped. Defaults to retrying every RetryDelay until the sink is stopped.
+	Retry monitor.RetryPolicy
+}
+
+// DefaultConfig returns the default table name and batch settings
+func DefaultConfig() Config {
+	return Config{
+		Table:        "factom_events",
+		BatchSize:    100,
+		BatchTimeout: time.Second * 5,
+		RetryDelay:   time.Second,
+	}
+}
+
+// Timeout specifies the maximum time a single statement can take
+var Timeout = time.Second * 10
+
+// columns of the events table, in the order they are inserted
+var columns = []string{
+	"network", "height", "dbheight", "minute",
+	"block_start", "minute_start", "node_time", "received_at", "detection_ms",
+}
+
+// Schema returns the statements that create the events table and its indexes.
+// The statements can be run repeatedly.
+//
+// Every minute is stored once per network. detection_ms is the time between the start
+// of the minute and the poll that detected it, according to the node's clock.
+// received_at is the time the row was written, so all events of a batch share it and
+// it lags behind the poll by up to the batch timeout.
+func Schema(table string) []string {
+	t := quote(table)
+	return []string{
+		`CREATE TABLE IF NOT EXISTS ` + t + ` (
+	network      TEXT        NOT NULL DEFAULT '',
+	height       BIGINT      NOT NULL,
+	dbheight     BIGINT      NOT NULL,
+	minute       SMALLINT    NOT NULL,
+	block_start  TIMESTAMPTZ,
+	minute_start TIMESTAMPTZ,
+	node_time    TIMESTAMPTZ,
+	received_at  TIMESTAMPTZ NOT NULL,
+	detection_ms BIGINT,
+	PRIMARY KEY (network, height, minute)
+)`,
+		`CREATE INDEX IF NOT EXISTS ` + quote(table+"_received_at") + ` ON ` + t + ` (received_at)`,
+	}
+}
+
+// Migrate creates the events table and its indexes if they don't exist yet
+func Migrate(ctx context.Context, db Execer, table string) error {
+	for _, stmt := range Schema(table) {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("postgres: migrating %s: %v", table, err)
+		}
+	}
+	return nil
+}
+
+// quote escapes an identifier, so table names can't inject sql
+func quote(ident string) string {
+	return `"` + strings.Replace(ident, `"`, `""`, -1) + `"`
+}
+
+// Writer inserts events into the table. Events that are already archived are ignored,
+// so writes can be retried. It implements monitor.Sink for use with a monitor.Dispatcher.
+type Writer struct {
+	db    Execer
+	table string
+	now   func() time.Time
+}
+
+var _ monitor.Sink = (*Writer)(nil)
+
+// NewWriter creates a writer for the table. See Migrate to create the table.
+func NewWriter(db Execer, table string) *Writer {
+	w := new(Writer)
+	w.db = db
+	w.table = table
+	w.now = time.Now
+	return w
+}
+
+// maxParameters is the limit of parameters in a single statement of the PostgreSQL
+// wire protocol
+const maxParameters = 65535
+
+// Write inserts the events, using as few statements as the parameter limit allows
+func (w *Writer) Write(ctx context.Context, events ...monitor.Event) error {
+	rows := maxParameters / len(columns)
+	for len(events) > rows {
+		if err := w.write(ctx, events[:rows]); err != nil {
+			return err
+		}
+		events = events[rows:]
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	return w.write(ctx, events)
+}
+
+func (w *Writer) write(ctx context.Context, events []monitor.Event) error {
+	received := w.now()
+	var sb strings.Builder
+	sb.WriteString("INSERT INTO " + quote(w.table) + " (" + strings.Join(columns, ", ") + ") VALUES ")
+	args := make([]interface{}, 0, len(events)*len(columns))
+	for i, e := range events {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteByte('(')
+		for j := range columns {
+			if j > 0 {
+				sb.WriteString(", ")
+			}
+			fmt.Fprintf(&sb, "$%d", i*len(columns)+j+1)
+		}
+		sb.WriteByte(')')
+
+		args = append(args, e.Network, e.Height, e.DBHeight, e.Minute,
+			nullTime(e.BlockStart), nullTime(e.MinuteStart), nullTime(e.NodeTime), received, detection(e))
+	}
+	sb.WriteString(" ON CONFLICT DO NOTHING")
+
+	_, err := w.db.ExecContext(ctx, sb.String(), args...)
+	return err
+}
+
+// HandleEvent inserts a single event
+func (w *Writer) HandleEvent(ctx context.Context, e monitor.Event) error {
+	return w.Write(ctx, e)
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// detection returns the milliseconds between the start of the minute and the poll
+// that detected it
+func detection(e monitor.Event) sql.NullInt64 {
+	if e.NodeTime.IsZero() || e.MinuteStart.IsZero() {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: e.NodeTime.Sub(e.MinuteStart).Milliseconds(), Valid: true}
+}
+
+// Sink archives minute events in batches.
+// Delivery is at-least-once: a failed batch is retried until it succeeds or the
+// sink is stopped.
+type Sink struct {
+	writer *Writer
+	conf   Config
+
+	errors chan error
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan interface{}
+	closer sync.Once
+}
+
+// NewSink creates a new sink that begins archiving events from the source immediately.
+// The table must exist, see Migrate.
+// Starts a goroutine that can be stopped via sink.Stop().
+func NewSink(db Execer, src Source, conf Config) *Sink {
+	if conf.Table == "" {
+		conf.Table = DefaultConfig().Table
+	}
+	if conf.Retry == nil {
+		conf.Retry = monitor.ConstantRetry(conf.RetryDelay, 0)
+	}
+	s := new(Sink)
+	s.writer = NewWriter(db, conf.Table)
+	s.conf = conf
+	s.errors = make(chan error, 6)
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.done = make(chan interface{})
+	go s.run(src.NewBatchListener(conf.BatchSize, conf.BatchTimeout))
+	return s
+}
+
+// Errors returns a channel that receives errors from failed batches.
+// Errors are dropped if the channel is not read.
+func (s *Sink) Errors() <-chan error {
+	return s.errors
+}
+
+func (s *Sink) run(l <-chan []monitor.Event) {
+	defer close(s.done)
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case batch, ok := <-l:
+			if !ok {
+				return
+			}
+			s.flush(batch)
+		}
+	}
+}
+
+// flush retries the batch until it is written, the retry policy gives up, or the
+// sink is stopped
+func (s *Sink) flush(batch []monitor.Event) {
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(s.ctx, Timeout)
+		err := s.writer.Write(ctx, batch...)
+		cancel()
+		if err == nil {
+			return
+		}
+		s.notifyError(err)
+		delay, retry := s.conf.Retry.NextDelay(attempt, err)
+		if !retry {
+			return
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (s *Sink) notifyError(err error) {
+	select {
+	case s.errors <- err:
+	default:
+	}
+}
+
+// Stop halts the sink and waits for the pending batch to be written or dropped.
+// It does not close the database.
+func (s *Sink) Stop() {
+	s.closer.Do(func() {
+		s.cancel()
+		<-s.done
+	})
+}
@@ -0,0 +1,43 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// raceResult is the outcome of a request to one of the raced endpoints
+type raceResult struct {
+	primary bool
+	raw     json.RawMessage
+	err     error
+}
+
+// race sends the request to the monitor's active node and all of Config.RaceEndpoints at once
+// and returns the first successful response. The other requests are cancelled.
+// If all requests fail, the error of the active node is returned.
+func (m *Monitor) race(ctx context.Context, method string) (json.RawMessage, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	urls := append([]string{m.nodeURL()}, m.conf.RaceEndpoints...)
+	results := make(chan raceResult, len(urls))
+	for i, url := range urls {
+		go func(url string, primary bool) {
+			r := raceResult{primary: primary}
+			r.err = m.requestURL(ctx, url, method, nil, &r.raw)
+			results <- r
+		}(url, i == 0)
+	}
+
+	var err error
+	for range urls {
+		r := <-results
+		if r.err == nil {
+			return r.raw, nil
+		}
+		if r.primary {
+			err = r.err
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,54 @@
+package monitor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMonitor_RaceEndpoints(t *testing.T) {
+	s := newTestServer("localhost:9845", 10, 5, time.Second*10, t)
+	defer s.stop()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Millisecond * 500):
+			s.api(rw, r)
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+
+	broken := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		http.Error(rw, "down", http.StatusBadGateway)
+	}))
+	defer broken.Close()
+
+	// the primary url is slow, the fast endpoint wins the race
+	m, err := NewMonitorWithConfig(slow.URL, Config{RaceEndpoints: []string{broken.URL, "http://localhost:9845/v2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	start := time.Now()
+	resp, err := m.FactomdRequest(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.LeaderHeight != 10 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if d := time.Since(start); d > time.Millisecond*400 {
+		t.Errorf("race waited for the slow endpoint: %s", d)
+	}
+
+	// all endpoints fail
+	m2, err := NewMonitorWithConfig(broken.URL, Config{RaceEndpoints: []string{broken.URL}})
+	if err == nil {
+		m2.Stop()
+		t.Error("no error when all endpoints fail")
+	}
+}
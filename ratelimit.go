@@ -0,0 +1,70 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket that limits the rate of API requests.
+// A single limiter can be shared by multiple monitors to keep their
+// combined request rate to a node below its limits.
+type RateLimiter struct {
+	mtx    sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a limiter that allows rate requests per second on average
+// and bursts of up to burst requests. The bucket starts full.
+// A rate of zero or less does not limit requests.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	rl := new(RateLimiter)
+	rl.rate = rate
+	rl.burst = float64(burst)
+	rl.tokens = rl.burst
+	rl.last = time.Now()
+	return rl
+}
+
+// Wait blocks until a request is allowed or the context is done.
+func (rl *RateLimiter) Wait(ctx context.Context) error {
+	if rl.rate <= 0 {
+		return nil
+	}
+
+	rl.mtx.Lock()
+	now := time.Now()
+	rl.tokens += now.Sub(rl.last).Seconds() * rl.rate
+	if rl.tokens > rl.burst {
+		rl.tokens = rl.burst
+	}
+	rl.last = now
+
+	// reserve a token, even if it takes the bucket into debt
+	rl.tokens--
+	if rl.tokens >= 0 {
+		rl.mtx.Unlock()
+		return nil
+	}
+	wait := time.Duration(-rl.tokens / rl.rate * float64(time.Second))
+	rl.mtx.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// return the reservation
+		rl.mtx.Lock()
+		rl.tokens++
+		rl.mtx.Unlock()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,51 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	rl := NewRateLimiter(20, 2)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if err := rl.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// burst of 2, then 4 more at 50ms each
+	if elapsed := time.Since(start); elapsed < time.Millisecond*190 || elapsed > time.Millisecond*400 {
+		t.Errorf("unexpected time for 6 requests: %v, want ~200ms", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+	defer cancel()
+	if err := rl.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("unexpected error for cancelled wait. got = %v, want = %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestRateLimiter_Shared(t *testing.T) {
+	s := newTestServer("localhost:9876", 10, 5, time.Second*10, t)
+	defer s.stop()
+
+	rl := NewRateLimiter(10, 1)
+	conf := Config{RateLimiter: rl}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		m, err := NewMonitorWithConfig("http://localhost:9876/v2", conf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer m.Stop()
+	}
+
+	if elapsed := time.Since(start); elapsed < time.Millisecond*190 {
+		t.Errorf("monitors were not rate limited. 3 requests took %v, want >= 200ms", elapsed)
+	}
+}
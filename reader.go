@@ -0,0 +1,53 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// eventReader is the io.ReadCloser returned by EventReader
+type eventReader struct {
+	sub    *Subscription
+	ctx    context.Context
+	cancel context.CancelFunc
+	buf    []byte // the rest of the current line
+}
+
+// EventReader returns a stream of minute events as newline delimited JSON, which can be
+// piped into anything that consumes bytes, such as an HTTP response, the stdin of a
+// process, or a log file. Read blocks until the next event is available and returns
+// io.EOF once the reader is closed or the monitor is drained.
+// Events are buffered like those of any other listener, so a reader that isn't read
+// drops events. The reader is listed under the name "reader" in Listeners().
+// Close must be called to stop the delivery of events.
+func (m *Monitor) EventReader() io.ReadCloser {
+	r := new(eventReader)
+	r.sub = m.Subscribe("reader")
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	return r
+}
+
+func (r *eventReader) Read(p []byte) (int, error) {
+	if len(r.buf) == 0 {
+		e, err := r.sub.Next(r.ctx)
+		if err != nil {
+			return 0, io.EOF
+		}
+		js, err := json.Marshal(e)
+		if err != nil {
+			return 0, err
+		}
+		r.buf = append(js, '\n')
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// Close stops the delivery of events and unblocks a pending Read
+func (r *eventReader) Close() error {
+	r.cancel()
+	r.sub.Close()
+	return nil
+}
@@ -0,0 +1,59 @@
+package monitor
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestMonitor_EventReader(t *testing.T) {
+	s := newTestServer("localhost:9835", 10, 0, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitorWithConfig("http://localhost:9835/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	r := m.EventReader()
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	for _, want := range []int64{1, 2} {
+		s.tick()
+		select {
+		case line := <-lines:
+			var e Event
+			if err := json.Unmarshal([]byte(line), &e); err != nil {
+				t.Fatal(err)
+			}
+			if e.Height != 10 || e.Minute != want {
+				t.Errorf("unexpected event %s, want minute %d", line, want)
+			}
+		case <-time.After(time.Second * 2):
+			t.Fatalf("minute %d not read", want)
+		}
+	}
+
+	r.Close()
+	select {
+	case line, ok := <-lines:
+		if ok {
+			t.Errorf("unexpected line after close %s", line)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("read not unblocked by close")
+	}
+	if _, err := r.Read(make([]byte, 10)); err != io.EOF {
+		t.Errorf("unexpected error after close: %v", err)
+	}
+}
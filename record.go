@@ -0,0 +1,34 @@
+package monitor
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Recording is a single "current-minute" response written to Config.Recorder.
+// Recordings can be played back with monitortest.NewReplay.
+type Recording struct {
+	// The local time the response was received
+	Time time.Time `json:"time"`
+	// The unmodified result of the API response
+	Response json.RawMessage `json:"response"`
+}
+
+// record writes the response to the recorder, if configured
+func (m *Monitor) record(resp *MinuteResponse) {
+	if m.conf.Recorder == nil {
+		return
+	}
+
+	js, err := json.Marshal(Recording{Time: m.clock().Now(), Response: resp.Raw})
+	if err != nil {
+		m.notifyError(err)
+		return
+	}
+
+	m.recordMtx.Lock()
+	defer m.recordMtx.Unlock()
+	if _, err := m.conf.Recorder.Write(append(js, '\n')); err != nil {
+		m.notifyError(err)
+	}
+}
@@ -0,0 +1,69 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+// Scripter runs Lua scripts via EVAL
+type Scripter interface {
+	// Eval runs the script with the keys and arguments and returns its result,
+	// ie an int64 for an integer reply
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// acquireScript sets the key to the holder with a ttl in milliseconds if it is free or
+// already held by the holder
+const acquireScript = `local v = redis.call("GET", KEYS[1])
+if v == false or v == ARGV[1] then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return 1
+end
+return 0`
+
+// releaseScript deletes the key if it is held by the holder
+const releaseScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`
+
+// Lock is a monitor.Lock kept in a Redis key that expires with the lease.
+// Acquiring and releasing are atomic Lua scripts, so the key is never changed by an
+// instance that doesn't hold it.
+type Lock struct {
+	client Scripter
+	key    string
+}
+
+var _ monitor.Lock = (*Lock)(nil)
+
+// NewLock creates a lock stored in the key, ie "factom:leader"
+func NewLock(client Scripter, key string) *Lock {
+	l := new(Lock)
+	l.client = client
+	l.key = key
+	return l
+}
+
+// Acquire takes or renews the lease if the key is free or already held by the holder
+func (l *Lock) Acquire(ctx context.Context, holder string, ttl time.Duration) (bool, error) {
+	res, err := l.client.Eval(ctx, acquireScript, []string{l.key}, holder, strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		return false, err
+	}
+	n, ok := res.(int64)
+	if !ok {
+		return false, fmt.Errorf("unexpected reply %v to lock script", res)
+	}
+	return n == 1, nil
+}
+
+// Release deletes the key if it is held by the holder
+func (l *Lock) Release(ctx context.Context, holder string) error {
+	_, err := l.client.Eval(ctx, releaseScript, []string{l.key}, holder)
+	return err
+}
@@ -0,0 +1,65 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeScripter emulates the lock scripts on the fake client's keys
+type fakeScripter struct {
+	*fakeClient
+}
+
+func (s fakeScripter) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	v, ok := s.keys[keys[0]]
+	switch script {
+	case acquireScript:
+		if ok && v != args[0] {
+			return int64(0), nil
+		}
+		s.keys[keys[0]] = args[0].(string)
+		return int64(1), nil
+	case releaseScript:
+		if ok && v == args[0] {
+			delete(s.keys, keys[0])
+			return int64(1), nil
+		}
+		return int64(0), nil
+	}
+	return nil, nil
+}
+
+func TestLock(t *testing.T) {
+	ctx := context.Background()
+	c := fakeScripter{newFakeClient()}
+	l := NewLock(c, "factom:leader")
+
+	for _, tt := range []struct {
+		holder string
+		want   bool
+	}{{"a", true}, {"a", true}, {"b", false}} {
+		ok, err := l.Acquire(ctx, tt.holder, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != tt.want {
+			t.Errorf("acquire by %s: got = %v, want = %v", tt.holder, ok, tt.want)
+		}
+	}
+
+	if err := l.Release(ctx, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if c.keys["factom:leader"] != "a" {
+		t.Errorf("lock released by another holder")
+	}
+	if err := l.Release(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := l.Acquire(ctx, "b", time.Second); !ok {
+		t.Errorf("lock not free after release")
+	}
+}
@@ -0,0 +1,196 @@
+// Package redis publishes monitor events on Redis channels and mirrors the
+// current state into Redis keys. Lock elects a leader among monitor instances.
+//
+// The package does not depend on a Redis client library. Any client can be
+// used by implementing Client and Scripter, for example by wrapping go-redis.
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+// Client is the subset of Redis commands used by the sink
+type Client interface {
+	// Publish sends the message to the channel via PUBLISH
+	Publish(ctx context.Context, channel string, message []byte) error
+	// Set stores the value in the key via SET
+	Set(ctx context.Context, key string, value []byte) error
+}
+
+// Source is the subset of a monitor used by the sink
+type Source interface {
+	NewMinuteListener() <-chan monitor.Event
+}
+
+// Names holds the channel and key names.
+// An empty name disables that channel or key.
+type Names struct {
+	// Channel for JSON encoded minute events
+	MinuteChannel string
+	// Channel for new heights
+	HeightChannel string
+	// Channel for new dbheights
+	DBHeightChannel string
+
+	// Key holding the most recent JSON encoded event
+	EventKey string
+	// Key holding the most recent height
+	HeightKey string
+	// Key holding the most recent dbheight
+	DBHeightKey string
+	// Key holding the most recent minute
+	MinuteKey string
+}
+
+// DefaultNames are the names used if no others are specified
+var DefaultNames = Names{
+	MinuteChannel:   "factom:minute",
+	HeightChannel:   "factom:height",
+	DBHeightChannel: "factom:dbheight",
+
+	EventKey:    "factom:event",
+	HeightKey:   "factom:height",
+	DBHeightKey: "factom:dbheight",
+	MinuteKey:   "factom:minute",
+}
+
+// Timeout specifies the maximum time the commands of a single event can take
+var Timeout = time.Second * 5
+
+// Sink reads minute events from a monitor, publishes them, and updates the state keys.
+// Keys are updated before the channels are published to, so subscribers that
+// read the keys after receiving a message see the new state.
+type Sink struct {
+	client Client
+	names  Names
+
+	mtx      sync.Mutex
+	height   int64 // the last published height, -1 before the first event
+	dbheight int64
+
+	errors chan error
+
+	close  chan interface{}
+	done   chan interface{}
+	closer sync.Once
+}
+
+var _ monitor.Sink = (*Sink)(nil)
+
+// NewSink creates a new sink that begins processing events from the source immediately.
+// Starts a goroutine that can be stopped via sink.Stop().
+// If src is nil, the sink only processes the events passed to HandleEvent, ie by a
+// monitor.Dispatcher.
+func NewSink(client Client, src Source, names Names) *Sink {
+	s := new(Sink)
+	s.client = client
+	s.names = names
+	s.height, s.dbheight = -1, -1
+	s.errors = make(chan error, 6)
+	s.close = make(chan interface{})
+	s.done = make(chan interface{})
+	if src == nil {
+		close(s.done)
+		return s
+	}
+	go s.run(src.NewMinuteListener())
+	return s
+}
+
+// Errors returns a channel that receives errors from failed commands.
+// Errors are dropped if the channel is not read.
+func (s *Sink) Errors() <-chan error {
+	return s.errors
+}
+
+func (s *Sink) run(l <-chan monitor.Event) {
+	defer close(s.done)
+	for {
+		select {
+		case <-s.close:
+			return
+		case e, ok := <-l:
+			if !ok { // drained
+				return
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+			s.notifyError(s.HandleEvent(ctx, e))
+			cancel()
+		}
+	}
+}
+
+// HandleEvent updates the state keys and publishes the event and the heights that
+// changed. It implements monitor.Sink, so a dispatcher can retry events that failed.
+// Returns the first error, the other commands are sent regardless.
+func (s *Sink) HandleEvent(ctx context.Context, e monitor.Event) error {
+	js, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var first error
+	record := func(err error) error {
+		if err != nil && first == nil {
+			first = err
+		}
+		return err
+	}
+	set := func(key string, value []byte) {
+		if key != "" {
+			record(s.client.Set(ctx, key, value))
+		}
+	}
+	publish := func(channel string, message []byte) error {
+		if channel == "" {
+			return nil
+		}
+		return record(s.client.Publish(ctx, channel, message))
+	}
+
+	set(s.names.EventKey, js)
+	set(s.names.HeightKey, itoa(e.Height))
+	set(s.names.DBHeightKey, itoa(e.DBHeight))
+	set(s.names.MinuteKey, itoa(e.Minute))
+
+	publish(s.names.MinuteChannel, js)
+	// a retried event publishes the heights again if they failed
+	if e.Height != s.height && publish(s.names.HeightChannel, itoa(e.Height)) == nil {
+		s.height = e.Height
+	}
+	if e.DBHeight != s.dbheight && publish(s.names.DBHeightChannel, itoa(e.DBHeight)) == nil {
+		s.dbheight = e.DBHeight
+	}
+	return first
+}
+
+func itoa(i int64) []byte {
+	return []byte(strconv.FormatInt(i, 10))
+}
+
+func (s *Sink) notifyError(err error) {
+	if err == nil {
+		return
+	}
+	select {
+	case s.errors <- err:
+	default:
+	}
+}
+
+// Stop halts the sink. It does not close the client.
+func (s *Sink) Stop() {
+	s.closer.Do(func() {
+		close(s.close)
+		<-s.done
+	})
+}
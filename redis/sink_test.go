@@ -0,0 +1,134 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+	"github.com/WhoSoup/factom-monitor/monitortest"
+)
+
+type fakeClient struct {
+	mtx       sync.Mutex
+	keys      map[string]string
+	published map[string][]string
+}
+
+func newFakeClient() *fakeClient {
+	c := new(fakeClient)
+	c.keys = make(map[string]string)
+	c.published = make(map[string][]string)
+	return c
+}
+
+func (c *fakeClient) Publish(ctx context.Context, channel string, message []byte) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.published[channel] = append(c.published[channel], string(message))
+	return nil
+}
+
+func (c *fakeClient) Set(ctx context.Context, key string, value []byte) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.keys[key] = string(value)
+	return nil
+}
+
+func (c *fakeClient) count(channel string) int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return len(c.published[channel])
+}
+
+func TestSink(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(10, 8)
+	events := fake.NewMinuteListener()
+	c := newFakeClient()
+	s := NewSink(c, fake, DefaultNames)
+	defer s.Stop()
+
+	fake.AdvanceMinute() // 10/9, dbheight 10
+	fake.AdvanceMinute() // 11/0, dbheight 10
+	fake.AdvanceMinute() // 11/1, dbheight 11
+	<-events
+	<-events
+	last := <-events
+
+	for i := 0; i < 50 && c.count("factom:dbheight") < 2; i++ {
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	js, err := json.Marshal(last)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	wantKeys := map[string]string{
+		"factom:event":    string(js),
+		"factom:height":   "11",
+		"factom:dbheight": "11",
+		"factom:minute":   "1",
+	}
+	for k, v := range wantKeys {
+		if c.keys[k] != v {
+			t.Errorf("key %s: got = %s, want = %s", k, c.keys[k], v)
+		}
+	}
+
+	if n := len(c.published["factom:minute"]); n != 3 {
+		t.Errorf("minute channel: got = %d messages, want = 3", n)
+	}
+	if n := len(c.published["factom:height"]); n != 2 {
+		t.Errorf("height channel: got = %d messages, want = 2", n)
+	}
+	if n := len(c.published["factom:dbheight"]); n != 2 {
+		t.Errorf("dbheight channel: got = %d messages, want = 2", n)
+	}
+}
+
+func TestSink_Drained(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(10, 1)
+	c := newFakeClient()
+	s := NewSink(c, fake, DefaultNames)
+	defer s.Stop()
+
+	fake.Drain()
+
+	select {
+	case <-s.done:
+	case <-time.After(time.Second):
+		t.Fatal("sink did not exit after the source was drained")
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if len(c.keys) > 0 || len(c.published) > 0 {
+		t.Errorf("sink wrote after the source was drained: %v %v", c.keys, c.published)
+	}
+}
+
+func TestSink_Dispatcher(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(10, 1)
+	d := monitor.NewDispatcher(fake, monitor.DispatcherConfig{})
+	defer d.Stop()
+	c := newFakeClient()
+	if err := d.Add("redis", NewSink(c, nil, DefaultNames)); err != nil {
+		t.Fatal(err)
+	}
+
+	fake.AdvanceMinute()
+	for i := 0; i < 50 && c.count("factom:minute") < 1; i++ {
+		time.Sleep(time.Millisecond * 10)
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.keys["factom:minute"] != "2" || len(c.published["factom:height"]) != 1 {
+		t.Errorf("unexpected state %v %v", c.keys, c.published)
+	}
+}
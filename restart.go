@@ -0,0 +1,76 @@
+package monitor
+
+import "time"
+
+// RestartEvent is sent to restart listeners when the node comes back after a restart.
+// While factomd boots, it reports a leader height of 0 until it has loaded its database.
+// Neither the "properties" nor the "diagnostics" API report the node's uptime, so this
+// is the only sign of a restart that was quick enough to not fail any polls.
+type RestartEvent struct {
+	// The height before the restart
+	Height int64 `json:"height"`
+	// The first height reported after the restart
+	ResumedHeight int64 `json:"resumedheight"`
+	// The time of the last successful poll before the restart
+	Since time.Time `json:"since"`
+	// The time the node was first seen up again
+	Time time.Time `json:"time"`
+}
+
+// Downtime is the time between the last poll before the restart and the first poll after it
+func (e RestartEvent) Downtime() time.Duration {
+	return e.Time.Sub(e.Since)
+}
+
+// Restarting returns true while the node is booting after a restart
+func (m *Monitor) Restarting() bool {
+	m.heightMtx.Lock()
+	defer m.heightMtx.Unlock()
+	return !m.restartSince.IsZero()
+}
+
+// NewRestartListener spawns a new listener that receives an event every time the node
+// comes back from a restart.
+// Each reader must have its own listener.
+func (m *Monitor) NewRestartListener() <-chan RestartEvent {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan RestartEvent, 6)
+	m.restartListeners = append(m.restartListeners, l)
+	m.register("restart", l)
+	return l
+}
+
+// checkRestart detects a node that reports a leader height of 0 after it reported a real
+// height, and notifies restart listeners once the node reports a real height again.
+// Must be called before polled, which updates the time of the last poll.
+func (m *Monitor) checkRestart(resp *MinuteResponse) {
+	now := m.clock().Now()
+	m.heightMtx.Lock()
+	if resp.LeaderHeight == 0 && m.height > 0 {
+		if m.restartSince.IsZero() {
+			m.restartSince = m.lastPoll
+		}
+		m.heightMtx.Unlock()
+		return
+	}
+	since := m.restartSince
+	m.restartSince = time.Time{}
+	height := m.height
+	m.heightMtx.Unlock()
+	if since.IsZero() {
+		return
+	}
+
+	e := RestartEvent{Height: height, ResumedHeight: resp.LeaderHeight, Since: since, Time: now}
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	for _, l := range m.restartListeners {
+		select {
+		case l <- e:
+			m.delivered(l)
+		default:
+			m.dropped(l)
+		}
+	}
+}
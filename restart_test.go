@@ -0,0 +1,52 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonitor_NodeRestart(t *testing.T) {
+	s := newTestServer("localhost:9836", 10, 5, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitorWithConfig("http://localhost:9836/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	listener := m.NewRestartListener()
+
+	s.mtx.Lock()
+	s.height = 0
+	s.minute = 0
+	s.mtx.Unlock()
+
+	deadline := time.Now().Add(time.Second * 2)
+	for !m.Restarting() {
+		if time.Now().After(deadline) {
+			t.Fatal("restart not detected")
+		}
+		time.Sleep(time.Millisecond * 20)
+	}
+	if h, _, _ := m.GetCurrentMinute(); h != 10 {
+		t.Errorf("height changed during restart to %d", h)
+	}
+
+	s.mtx.Lock()
+	s.height = 10
+	s.minute = 6
+	s.mtx.Unlock()
+
+	select {
+	case e := <-listener:
+		if e.Height != 10 || e.ResumedHeight != 10 || e.Downtime() <= 0 {
+			t.Errorf("unexpected restart event %+v", e)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("restart event not received")
+	}
+	if m.Restarting() {
+		t.Error("monitor still restarting")
+	}
+}
@@ -0,0 +1,73 @@
+package monitor
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy decides whether and when a failed operation is tried again
+type RetryPolicy interface {
+	// NextDelay is called after a failed attempt with the number of attempts made so
+	// far, starting at 1, and the error of the last one. It returns the time to wait
+	// before the next attempt, or false to give up.
+	NextDelay(attempt int, err error) (time.Duration, bool)
+}
+
+// RetryPolicyFunc adapts a function to the RetryPolicy interface
+type RetryPolicyFunc func(attempt int, err error) (time.Duration, bool)
+
+// NextDelay calls f(attempt, err)
+func (f RetryPolicyFunc) NextDelay(attempt int, err error) (time.Duration, bool) {
+	return f(attempt, err)
+}
+
+// ConstantRetry waits the same delay between all attempts and gives up after maxAttempts.
+// A maxAttempts of zero or less retries forever.
+func ConstantRetry(delay time.Duration, maxAttempts int) RetryPolicy {
+	return RetryPolicyFunc(func(attempt int, err error) (time.Duration, bool) {
+		if maxAttempts > 0 && attempt >= maxAttempts {
+			return 0, false
+		}
+		return delay, true
+	})
+}
+
+// ExponentialRetry waits initial before the first retry and doubles the delay with every
+// further retry, up to max. It gives up after maxAttempts.
+// A max of zero or less doesn't limit the delay, a maxAttempts of zero or less retries forever.
+func ExponentialRetry(initial, max time.Duration, maxAttempts int) RetryPolicy {
+	return RetryPolicyFunc(func(attempt int, err error) (time.Duration, bool) {
+		if maxAttempts > 0 && attempt >= maxAttempts {
+			return 0, false
+		}
+		delay := initial
+		for i := 1; i < attempt; i++ {
+			if (max > 0 && delay >= max) || delay > math.MaxInt64/2 {
+				break
+			}
+			delay *= 2
+		}
+		if max > 0 && delay > max {
+			delay = max
+		}
+		return delay, true
+	})
+}
+
+// JitteredRetry randomizes the delays of another policy by up to the given fraction
+// in either direction, ie 0.2 changes every delay by up to ±20%. This keeps many
+// clients that failed at the same time from retrying at the same time.
+func JitteredRetry(p RetryPolicy, fraction float64) RetryPolicy {
+	return RetryPolicyFunc(func(attempt int, err error) (time.Duration, bool) {
+		delay, ok := p.NextDelay(attempt, err)
+		if !ok || fraction <= 0 {
+			return delay, ok
+		}
+		factor := 1 + fraction*(2*rand.Float64()-1)
+		if factor < 0 {
+			factor = 0
+		}
+		return time.Duration(float64(delay) * factor), true
+	})
+}
@@ -0,0 +1,46 @@
+package monitor
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicies(t *testing.T) {
+	errFail := errors.New("fail")
+	type step struct {
+		delay time.Duration
+		retry bool
+	}
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		want   []step
+	}{
+		{"constant", ConstantRetry(time.Second, 3), []step{{time.Second, true}, {time.Second, true}, {0, false}}},
+		{"forever", ConstantRetry(time.Second, 0), []step{{time.Second, true}, {time.Second, true}, {time.Second, true}}},
+		{"exponential", ExponentialRetry(time.Second, time.Second*5, 5), []step{
+			{time.Second, true}, {time.Second * 2, true}, {time.Second * 4, true}, {time.Second * 5, true}, {0, false},
+		}},
+		{"jitter without fraction", JitteredRetry(ConstantRetry(time.Second, 2), 0), []step{{time.Second, true}, {0, false}}},
+	}
+	for _, tt := range tests {
+		for i, want := range tt.want {
+			delay, retry := tt.policy.NextDelay(i+1, errFail)
+			if delay != want.delay || retry != want.retry {
+				t.Errorf("%s attempt %d: got = %v %v, want = %v %v", tt.name, i+1, delay, retry, want.delay, want.retry)
+			}
+		}
+	}
+
+	if d, _ := ExponentialRetry(time.Second, 0, 0).NextDelay(100, errFail); d <= 0 {
+		t.Errorf("exponential delay overflowed: %v", d)
+	}
+
+	jittered := JitteredRetry(ConstantRetry(time.Second, 0), 0.2)
+	for i := 1; i <= 100; i++ {
+		if d, ok := jittered.NextDelay(i, errFail); !ok || d < time.Millisecond*800 || d > time.Millisecond*1200 {
+			t.Fatalf("jittered delay %v out of range", d)
+		}
+	}
+}
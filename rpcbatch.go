@@ -0,0 +1,205 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/AdamSLevy/jsonrpc2/v14"
+)
+
+// BatchWindow is how long the first request of a batch waits for other requests to join
+// it when Config.BatchRequests is enabled
+var BatchWindow = time.Millisecond * 10
+
+// rpcCall is a single request of a batch
+type rpcCall struct {
+	method string
+	params interface{}
+	result interface{}
+	err    error
+}
+
+// rpcBatch collects the requests to one url until it is sent
+type rpcBatch struct {
+	calls []*rpcCall
+	done  chan struct{} // closed once every call has its result or error
+}
+
+// batch adds the request to the pending batch of the url, starting a new batch if
+// there is none, and waits for the response. All requests that are made within
+// BatchWindow of each other, such as those of the watchers of one dbheight, are sent
+// to the node as a single JSON-RPC batch request.
+func (m *Monitor) batch(ctx context.Context, url, method string, params, result interface{}) error {
+	call := &rpcCall{method: method, params: params, result: result}
+
+	m.batchMtx.Lock()
+	if m.batches == nil {
+		m.batches = make(map[string]*rpcBatch)
+	}
+	b, ok := m.batches[url]
+	if !ok {
+		b = &rpcBatch{done: make(chan struct{})}
+		m.batches[url] = b
+		time.AfterFunc(BatchWindow, func() {
+			m.batchMtx.Lock()
+			delete(m.batches, url)
+			m.batchMtx.Unlock()
+			m.sendBatch(url, b)
+		})
+	}
+	b.calls = append(b.calls, call)
+	m.batchMtx.Unlock()
+
+	select {
+	case <-b.done:
+		return call.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sendBatch sends the calls of the batch, honoring the limiters. A batch of one call
+// is sent as a regular request. If the node doesn't understand batch requests, the
+// calls are sent one by one and batching is turned off.
+func (m *Monitor) sendBatch(url string, b *rpcBatch) {
+	defer close(b.done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout())
+	defer cancel()
+
+	m.batchMtx.Lock()
+	unsupported := m.batchUnsupported
+	m.batchMtx.Unlock()
+
+	if len(b.calls) == 1 || unsupported {
+		for _, c := range b.calls {
+			c.err = m.send(ctx, url, c.method, c.params, c.result)
+		}
+		return
+	}
+
+	if err := m.acquire(ctx); err != nil {
+		b.fail(err)
+		return
+	}
+	start := time.Now()
+	err := m.postBatch(ctx, url, b.calls)
+	m.recordLatency(ctx, start, err)
+	m.release()
+
+	if _, ok := err.(errBatchUnsupported); ok {
+		m.batchMtx.Lock()
+		m.batchUnsupported = true
+		m.batchMtx.Unlock()
+		for _, c := range b.calls {
+			c.err = m.send(ctx, url, c.method, c.params, c.result)
+		}
+		return
+	}
+	if err != nil {
+		b.fail(err)
+	}
+}
+
+func (b *rpcBatch) fail(err error) {
+	for _, c := range b.calls {
+		c.err = err
+	}
+}
+
+// errBatchUnsupported is returned by postBatch if the response is not a batch response
+type errBatchUnsupported struct{}
+
+func (errBatchUnsupported) Error() string { return "node does not support batch requests" }
+
+// postBatch sends the calls as one JSON-RPC batch request and sets the result or error
+// of every call. The http client of the monitor's JSON-RPC client is used, along with its
+// headers and credentials.
+func (m *Monitor) postBatch(ctx context.Context, url string, calls []*rpcCall) error {
+	req := make(jsonrpc2.BatchRequest, len(calls))
+	for i, c := range calls {
+		req[i] = jsonrpc2.Request{ID: i + 1, Method: c.method, Params: c.params}
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	httpReq = httpReq.WithContext(ctx)
+	httpReq.Header.Set("Content-Type", "application/json")
+	for k, v := range m.client.Header {
+		httpReq.Header[http.CanonicalHeaderKey(k)] = v
+	}
+	if m.client.BasicAuth {
+		httpReq.SetBasicAuth(m.client.User, m.client.Password)
+	}
+
+	httpRes, err := m.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpRes.Body.Close()
+	body, err := ioutil.ReadAll(httpRes.Body)
+	if err != nil {
+		return err
+	}
+
+	var res []struct {
+		ID     int             `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *jsonrpc2.Error `json:"error"`
+	}
+	if err := json.Unmarshal(bytes.TrimSpace(body), &res); err != nil {
+		// a single error object in response to the whole batch
+		return errBatchUnsupported{}
+	}
+
+	answered := make([]bool, len(calls))
+	for _, r := range res {
+		if r.ID < 1 || r.ID > len(calls) {
+			continue
+		}
+		c := calls[r.ID-1]
+		answered[r.ID-1] = true
+		if r.Error != nil {
+			c.err = *r.Error
+			continue
+		}
+		c.err = json.Unmarshal(r.Result, c.result)
+	}
+	for i, ok := range answered {
+		if !ok {
+			calls[i].err = jsonrpc2.Error{Code: jsonrpc2.ErrorCodeInternal, Message: "no response in batch"}
+		}
+	}
+	return nil
+}
+
+// parallel calls f for every i in [0, n). With Config.BatchRequests, the calls run at the
+// same time so their requests are batched, otherwise they run one after the other.
+func (m *Monitor) parallel(n int, f func(i int)) {
+	if !m.conf.BatchRequests {
+		for i := 0; i < n; i++ {
+			f(i)
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			f(i)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,73 @@
+package monitor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMonitor_BatchRequests(t *testing.T) {
+	fa := "FA2jK2HcLnRdS94dEcU27rF3meoJfpUcZPSinpb7AwQvPRY6RL1Q"
+	ec := "EC2BURNFCT2PEGNETooo1oooo1oooo1oooo1oooo1oooo19wthin"
+
+	for addr, supported := range map[string]bool{"localhost:9827": true, "localhost:9826": false} {
+		s := newTestServer(addr, 10, 0, time.Second*10, t)
+		defer s.stop()
+		balances := map[string]int64{fa: 100, ec: 5}
+		balance := func(params json.RawMessage) interface{} {
+			var p struct {
+				Address string `json:"address"`
+			}
+			json.Unmarshal(params, &p)
+			return BalanceResponse{Balance: balances[p.Address]}
+		}
+		s.handle("factoid-balance", balance)
+		s.handle("entry-credit-balance", balance)
+		s.mtx.Lock()
+		s.noBatch = !supported
+		s.mtx.Unlock()
+
+		m, err := NewMonitorWithConfig("http://"+addr+"/v2", Config{Interval: time.Millisecond * 100, BatchRequests: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := m.WatchFactoidAddress(fa); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.WatchECAddress(ec); err != nil {
+			t.Fatal(err)
+		}
+		fct := m.NewFactoidBalanceListener()
+		credits := m.NewECBalanceListener()
+
+		s.mtx.Lock()
+		balances[fa], balances[ec] = 250, 10
+		s.mtx.Unlock()
+		s.tick() // new dbheight
+
+		for _, l := range []<-chan BalanceEvent{fct, credits} {
+			select {
+			case e := <-l:
+				if e.New != 250 && e.New != 10 {
+					t.Errorf("unexpected event. got = %+v", e)
+				}
+			case <-time.After(time.Second * 2):
+				t.Fatalf("balance change not received with batch support = %v", supported)
+			}
+		}
+		m.Stop()
+
+		s.mtx.Lock()
+		batches := s.batches
+		s.mtx.Unlock()
+		m.batchMtx.Lock()
+		unsupported := m.batchUnsupported
+		m.batchMtx.Unlock()
+		if supported && (batches == 0 || unsupported) {
+			t.Errorf("balances were not requested in a batch")
+		}
+		if !supported && !unsupported {
+			t.Errorf("batching not turned off for a node without batch support")
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package monitor
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Schedule specifies when a scheduled job runs
+type Schedule struct {
+	// The minute of the block the job runs at, 0-9
+	Minute int64
+	// Run only at heights that are a multiple of Every, ie 144 for roughly once a day.
+	// Zero or one runs the job every block.
+	Every int64
+}
+
+func (s Schedule) matches(e Event) bool {
+	if e.Minute != s.Minute {
+		return false
+	}
+	return s.Every <= 1 || e.Height%s.Every == 0
+}
+
+// Schedule runs the job every time the network reaches the scheduled minute and height.
+// Each run gets its own goroutine. If the previous run has not finished yet, the run is
+// skipped and an error is sent to error listeners. Panics in the job are recovered and
+// sent to error listeners as well.
+// The job runs until cancel is called or the monitor is drained, including while the
+// monitor is stopped and started again.
+func (m *Monitor) Schedule(s Schedule, job func(Event)) (cancel func()) {
+	l := m.NewFilteredListener(s.matches)
+	done := make(chan interface{})
+	var once sync.Once
+	var running int32
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case e, ok := <-l:
+				if !ok { // drained
+					return
+				}
+				if !atomic.CompareAndSwapInt32(&running, 0, 1) {
+					m.notifyError(fmt.Errorf("scheduled job for height %d minute %d skipped: previous run still running", e.Height, e.Minute))
+					continue
+				}
+				go func() {
+					defer atomic.StoreInt32(&running, 0)
+					defer func() {
+						if r := recover(); r != nil {
+							m.notifyError(fmt.Errorf("scheduled job for height %d minute %d panicked: %v", e.Height, e.Minute, r))
+						}
+					}()
+					job(e)
+				}()
+			}
+		}
+	}()
+
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
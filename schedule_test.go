@@ -0,0 +1,80 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSchedule_matches(t *testing.T) {
+	s := Schedule{Minute: 0, Every: 144}
+	if !s.matches(Event{Height: 288, Minute: 0}) || s.matches(Event{Height: 289, Minute: 0}) || s.matches(Event{Height: 288, Minute: 1}) {
+		t.Error("every 144 blocks matched incorrectly")
+	}
+	s = Schedule{Minute: 8}
+	if !s.matches(Event{Height: 7, Minute: 8}) || s.matches(Event{Height: 7, Minute: 9}) {
+		t.Error("every block matched incorrectly")
+	}
+}
+
+func TestMonitor_Schedule(t *testing.T) {
+	s := newTestServer("localhost:9859", 10, 0, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitorWithConfig("http://localhost:9859/v2", Config{Interval: time.Millisecond * 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	errs := m.NewErrorListener()
+	all := m.NewMinuteListener()
+
+	m.Schedule(Schedule{Minute: 1}, func(e Event) { panic("boom") })
+
+	ran := make(chan Event, 5)
+	release := make(chan bool)
+	cancel := m.Schedule(Schedule{Minute: 2}, func(e Event) {
+		ran <- e
+		<-release
+	})
+
+	for i := 0; i < 12; i++ { // 11/2
+		s.tick()
+		<-all
+	}
+
+	var panicked, skipped int
+	timeout := time.After(time.Second)
+	for panicked < 2 || skipped < 1 {
+		select {
+		case err := <-errs:
+			switch {
+			case strings.Contains(err.Error(), "panicked: boom"):
+				panicked++
+			case strings.Contains(err.Error(), "height 11 minute 2 skipped"):
+				skipped++
+			default:
+				t.Errorf("unexpected error %v", err)
+			}
+		case <-timeout:
+			t.Fatalf("missing errors. got = %d panics, %d skipped, want = 2, 1", panicked, skipped)
+		}
+	}
+
+	if e := <-ran; e.Height != 10 || e.Minute != 2 {
+		t.Errorf("unexpected run %+v", e)
+	}
+	close(release)
+
+	cancel()
+	for i := 0; i < 10; i++ { // 12/2
+		s.tick()
+		<-all
+	}
+	select {
+	case e := <-ran:
+		t.Errorf("job ran after cancel %+v", e)
+	case <-time.After(time.Millisecond * 100):
+	}
+}
@@ -0,0 +1,65 @@
+package monitor
+
+// HeightEvent is sent to height event listeners and dbheight event listeners
+type HeightEvent struct {
+	// The new height or dbheight
+	Height int64 `json:"height"`
+	// Increases by one with every height (or dbheight) event sent by this monitor, starting at 1.
+	// These listeners receive every event, so a gap in the sequence means the listener
+	// dropped events, while a gap in the height with consecutive sequence numbers means
+	// the monitor missed blocks. Unlike Event.Sequence, there are no filtered variants.
+	Sequence uint64 `json:"sequence"`
+}
+
+// NewHeightEventListener spawns a new listener that receives a sequenced event every
+// time a new height is attained.
+// Each reader must have its own listener.
+func (m *Monitor) NewHeightEventListener() <-chan HeightEvent {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan HeightEvent, 6)
+	m.heightEventListeners = append(m.heightEventListeners, l)
+	m.register("height event", l)
+	return l
+}
+
+// NewDBHeightEventListener spawns a new listener that receives a sequenced event every
+// time a new DBHeight is attained.
+// Each reader must have its own listener.
+func (m *Monitor) NewDBHeightEventListener() <-chan HeightEvent {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan HeightEvent, 6)
+	m.dbheightEventListeners = append(m.dbheightEventListeners, l)
+	m.register("dbheight event", l)
+	return l
+}
+
+// notifySequenced must be called with listenerMtx held
+func (m *Monitor) notifySequenced(e Event, height, dbheight bool) {
+	if height {
+		m.heightSequence++
+		he := HeightEvent{Height: e.Height, Sequence: m.heightSequence}
+		for _, l := range m.heightEventListeners {
+			select {
+			case l <- he:
+				m.delivered(l)
+			default:
+				m.dropped(l)
+			}
+		}
+	}
+
+	if dbheight {
+		m.dbheightSequence++
+		he := HeightEvent{Height: e.DBHeight, Sequence: m.dbheightSequence}
+		for _, l := range m.dbheightEventListeners {
+			select {
+			case l <- he:
+				m.delivered(l)
+			default:
+				m.dropped(l)
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonitor_Sequence(t *testing.T) {
+	s := newTestServer("localhost:9854", 10, 8, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitorWithConfig("http://localhost:9854/v2", Config{Interval: time.Millisecond * 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	minutes := m.NewMinuteListener()
+	heights := m.NewHeightEventListener()
+	dbheights := m.NewDBHeightEventListener()
+
+	for i := uint64(1); i <= 3; i++ {
+		s.tick() // minute 9, 0, 1
+		select {
+		case e := <-minutes:
+			if e.Sequence != i {
+				t.Errorf("minute %d: got sequence %d, want %d", e.Minute, e.Sequence, i)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("minute event %d not received", i)
+		}
+	}
+
+	select {
+	case e := <-heights:
+		if e.Height != 11 || e.Sequence != 1 {
+			t.Errorf("unexpected height event %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("height event not received")
+	}
+
+	select {
+	case e := <-dbheights:
+		if e.Height != 11 || e.Sequence != 1 {
+			t.Errorf("unexpected dbheight event %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("dbheight event not received")
+	}
+}
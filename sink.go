@@ -0,0 +1,279 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrQueueFull is reported by a dispatcher when an event is dropped because a sink's
+// queue is full
+var ErrQueueFull = errors.New("sink queue is full")
+
+// Sink is the destination of minute events delivered by a Dispatcher, such as a
+// database, a message queue, or a webhook.
+type Sink interface {
+	// HandleEvent processes a single event. Returning an error makes the dispatcher
+	// retry the event. The context is canceled when the attempt times out or the
+	// dispatcher is stopped.
+	HandleEvent(ctx context.Context, e Event) error
+}
+
+// SinkFunc adapts a function to the Sink interface
+type SinkFunc func(ctx context.Context, e Event) error
+
+// HandleEvent calls f(ctx, e)
+func (f SinkFunc) HandleEvent(ctx context.Context, e Event) error {
+	return f(ctx, e)
+}
+
+// SinkError is reported by a dispatcher when a sink could not handle an event
+type SinkError struct {
+	// The name the sink was added under
+	Sink  string
+	Event Event
+	// The number of attempts made, zero if the event was dropped before the first
+	Attempts int
+	Err      error
+}
+
+func (e *SinkError) Error() string {
+	return fmt.Sprintf("sink %q: height %d minute %d: %v", e.Sink, e.Event.Height, e.Event.Minute, e.Err)
+}
+
+// Unwrap returns the error of the last attempt
+func (e *SinkError) Unwrap() error {
+	return e.Err
+}
+
+// DispatcherConfig contains the settings of a dispatcher
+type DispatcherConfig struct {
+	// The number of events each sink can fall behind before events are dropped.
+	// Defaults to 100.
+	QueueSize int
+	// The maximum time of a single attempt to handle an event. Defaults to 10 seconds.
+	Timeout time.Duration
+	// The number of times an event is attempted before it is given up. Defaults to 3.
+	MaxAttempts int
+	// The time to wait before the first retry, doubling with every further retry.
+	// Defaults to one second.
+	RetryDelay time.Duration
+	// Retry decides when failed events are retried and when they are given up.
+	// Defaults to ExponentialRetry(RetryDelay, 0, MaxAttempts).
+	Retry RetryPolicy
+	// Leader, if set, limits delivery to the times it is the leader. Events that arrive
+	// while it isn't are discarded. See Elector.
+	Leader Leader
+}
+
+// Dispatcher delivers the minute events of a monitor to any number of sinks.
+// Every sink has its own queue and goroutine, so a slow or failing sink doesn't hold
+// up the others. Failed events are retried, and events that are given up are reported
+// on Errors().
+type Dispatcher struct {
+	conf DispatcherConfig
+
+	mtx     sync.Mutex
+	sinks   map[string]*dispatchedSink
+	drained bool // the source was drained, guarded by mtx
+
+	errors chan error
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	wg     sync.WaitGroup
+	closer sync.Once
+}
+
+type dispatchedSink struct {
+	name  string
+	sink  Sink
+	queue chan Event
+	close chan struct{}
+}
+
+// NewDispatcher creates a dispatcher without sinks that begins reading events from
+// the source immediately.
+// Starts a goroutine that can be stopped via dispatcher.Stop().
+func NewDispatcher(src Source, conf DispatcherConfig) *Dispatcher {
+	if conf.QueueSize < 1 {
+		conf.QueueSize = 100
+	}
+	if conf.Timeout <= 0 {
+		conf.Timeout = time.Second * 10
+	}
+	if conf.MaxAttempts < 1 {
+		conf.MaxAttempts = 3
+	}
+	if conf.RetryDelay <= 0 {
+		conf.RetryDelay = time.Second
+	}
+	if conf.Retry == nil {
+		conf.Retry = ExponentialRetry(conf.RetryDelay, 0, conf.MaxAttempts)
+	}
+
+	d := new(Dispatcher)
+	d.conf = conf
+	d.sinks = make(map[string]*dispatchedSink)
+	d.errors = make(chan error, 25)
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+	d.done = make(chan struct{})
+	go d.run(src.NewMinuteListener())
+	return d
+}
+
+// Add starts delivering events to the sink. Returns an error if a sink with the
+// same name already exists, or if the dispatcher was stopped or its source drained.
+func (d *Dispatcher) Add(name string, sink Sink) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if _, ok := d.sinks[name]; ok {
+		return fmt.Errorf("sink %q already exists", name)
+	}
+	if d.ctx.Err() != nil {
+		return errors.New("dispatcher has been stopped")
+	}
+	if d.drained {
+		return errors.New("dispatcher has been drained")
+	}
+
+	s := &dispatchedSink{
+		name:  name,
+		sink:  sink,
+		queue: make(chan Event, d.conf.QueueSize),
+		close: make(chan struct{}),
+	}
+	d.sinks[name] = s
+	d.wg.Add(1)
+	go d.deliver(s)
+	return nil
+}
+
+// Remove stops delivering events to the sink. Queued events are discarded.
+func (d *Dispatcher) Remove(name string) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if s, ok := d.sinks[name]; ok {
+		close(s.close)
+		delete(d.sinks, name)
+	}
+}
+
+// Sinks returns the names of all sinks
+func (d *Dispatcher) Sinks() []string {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	names := make([]string, 0, len(d.sinks))
+	for name := range d.sinks {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Errors returns a channel that receives a *SinkError for every event that was
+// given up. Errors are dropped if the channel is not read.
+func (d *Dispatcher) Errors() <-chan error {
+	return d.errors
+}
+
+func (d *Dispatcher) run(l <-chan Event) {
+	defer close(d.done)
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case e, ok := <-l:
+			if !ok { // drained, let the sinks finish their queues
+				d.mtx.Lock()
+				for _, s := range d.sinks {
+					close(s.queue)
+				}
+				d.sinks = make(map[string]*dispatchedSink)
+				d.drained = true
+				d.mtx.Unlock()
+				return
+			}
+			if d.conf.Leader != nil && !d.conf.Leader.IsLeader() {
+				continue
+			}
+			d.mtx.Lock()
+			for _, s := range d.sinks {
+				select {
+				case s.queue <- e:
+				default:
+					d.notifyError(&SinkError{Sink: s.name, Event: e, Err: ErrQueueFull})
+				}
+			}
+			d.mtx.Unlock()
+		}
+	}
+}
+
+// deliver hands the sink's queued events to the sink one by one
+func (d *Dispatcher) deliver(s *dispatchedSink) {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-s.close:
+			return
+		case e, ok := <-s.queue:
+			if !ok {
+				return
+			}
+			d.handle(s, e)
+		}
+	}
+}
+
+// handle attempts the event until it succeeds, the retry policy gives up, or the
+// sink is removed
+func (d *Dispatcher) handle(s *dispatchedSink, e Event) {
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(d.ctx, d.conf.Timeout)
+		err := s.sink.HandleEvent(ctx, e)
+		cancel()
+		if err == nil {
+			return
+		}
+		delay, retry := d.conf.Retry.NextDelay(attempt, err)
+		if !retry {
+			d.notifyError(&SinkError{Sink: s.name, Event: e, Attempts: attempt, Err: err})
+			return
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-d.ctx.Done():
+			timer.Stop()
+			return
+		case <-s.close:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func (d *Dispatcher) notifyError(err error) {
+	select {
+	case d.errors <- err:
+	default:
+	}
+}
+
+// Stop halts the dispatcher and waits for all sinks to return from HandleEvent.
+// Queued events are discarded.
+func (d *Dispatcher) Stop() {
+	d.closer.Do(func() {
+		d.mtx.Lock()
+		d.cancel()
+		d.mtx.Unlock()
+		<-d.done
+		d.wg.Wait()
+	})
+}
@@ -0,0 +1,112 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDispatcher(t *testing.T) {
+	s := newTestServer("localhost:9840", 10, 1, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitorWithConfig("http://localhost:9840/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	d := NewDispatcher(m, DispatcherConfig{MaxAttempts: 2, RetryDelay: time.Millisecond * 10})
+	defer d.Stop()
+
+	var mtx sync.Mutex
+	var received []Event
+	var attempts int
+	if err := d.Add("flaky", SinkFunc(func(ctx context.Context, e Event) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		attempts++
+		if attempts == 1 {
+			return errors.New("unavailable")
+		}
+		received = append(received, e)
+		return nil
+	})); err != nil {
+		t.Fatal(err)
+	}
+	broken := errors.New("broken")
+	if err := d.Add("broken", SinkFunc(func(ctx context.Context, e Event) error { return broken })); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Add("broken", SinkFunc(nil)); err == nil {
+		t.Error("no error for duplicate sink")
+	}
+
+	s.tick()
+
+	select {
+	case err := <-d.Errors():
+		var se *SinkError
+		if !errors.As(err, &se) || se.Sink != "broken" || se.Attempts != 2 || se.Event.Minute != 2 || !errors.Is(err, broken) {
+			t.Errorf("unexpected error %v", err)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("no error for broken sink")
+	}
+
+	for i := 0; i < 100; i++ {
+		mtx.Lock()
+		n := len(received)
+		mtx.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	mtx.Lock()
+	if len(received) != 1 || received[0].Minute != 2 || attempts != 2 {
+		t.Errorf("unexpected deliveries. got = %v after %d attempts", received, attempts)
+	}
+	mtx.Unlock()
+
+	d.Remove("broken")
+	if sinks := d.Sinks(); len(sinks) != 1 || sinks[0] != "flaky" {
+		t.Errorf("unexpected sinks: %v", sinks)
+	}
+}
+
+func TestDispatcher_Drained(t *testing.T) {
+	s := newTestServer("localhost:9812", 10, 1, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitorWithConfig("http://localhost:9812/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewDispatcher(m, DispatcherConfig{})
+	defer d.Stop()
+	if err := d.Add("before", SinkFunc(func(ctx context.Context, e Event) error { return nil })); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := m.StopAndDrain(ctx); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-d.done:
+	case <-time.After(time.Second):
+		t.Fatal("dispatcher did not exit after the monitor was drained")
+	}
+
+	if err := d.Add("after", SinkFunc(func(ctx context.Context, e Event) error { return nil })); err == nil {
+		t.Error("no error for a sink added after the drain")
+	}
+	if sinks := d.Sinks(); len(sinks) != 0 {
+		t.Errorf("unexpected sinks after the drain: %v", sinks)
+	}
+}
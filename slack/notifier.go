@@ -0,0 +1,276 @@
+// Package slack posts monitor alerts and block notifications to a Slack channel via an
+// incoming webhook.
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+	"github.com/WhoSoup/factom-monitor/notify"
+)
+
+// Class is a kind of notification
+type Class string
+
+// The classes of notifications
+const (
+	// Stall is sent when the monitor's "no-new-block" rule fires, see monitor.NoNewBlock
+	Stall Class = "stall"
+	// Alert is sent when any other rule fires
+	Alert Class = "alert"
+	// Recovery is sent when a rule resolves
+	Recovery Class = "recovery"
+	// Regression is sent when the node reports a lower height than it did before
+	Regression Class = "regression"
+	// Block is sent for every Nth block, see Config.EveryNBlocks
+	Block Class = "block"
+)
+
+// DefaultTemplates are the messages of each class. They are executed with a notify.Data
+// whose Kind is the class, see package notify for the available fields and functions.
+var DefaultTemplates = map[Class]string{
+	Stall:      `:rotating_light: *Stall*: {{.Alert.Message}}`,
+	Alert:      `:warning: *{{.Alert.Rule}}* ({{.Alert.Severity}}): {{.Alert.Message}}`,
+	Recovery:   `:white_check_mark: *{{.Alert.Rule}}* resolved after {{duration .Alert.Duration}}`,
+	Regression: `:warning: *Regression*: node went back from height {{.Previous}} to {{.Height}}`,
+	Block:      `:package: Block {{.Height}}`,
+}
+
+// Source is the subset of a monitor used by the notifier
+type Source interface {
+	NewAlertListener() <-chan monitor.Alert
+	NewHeightListener() <-chan int64
+	NewRawListener() <-chan *monitor.MinuteResponse
+}
+
+// Config contains the settings of a notifier
+type Config struct {
+	// The incoming webhook url
+	WebhookURL string
+	// The classes of notifications to send, defaults to stalls, alerts, recoveries,
+	// and regressions
+	Classes []Class
+	// Send a Block notification for every height divisible by EveryNBlocks.
+	// Defaults to every block if the Block class is enabled.
+	EveryNBlocks int64
+	// Templates replace the DefaultTemplates of the classes
+	Templates map[Class]string
+	// The minimum time between two messages. Notifications in between are dropped and
+	// their number is added to the next message. Stalls and recoveries change the state
+	// of the network and are always sent.
+	MinInterval time.Duration
+	// Optional overrides of the webhook's channel, user name, and icon
+	Channel   string
+	Username  string
+	IconEmoji string
+	// Leader, if set, limits notifications to the times it is the leader, so only one of
+	// several monitor instances sends them. See monitor.Elector.
+	Leader monitor.Leader
+	// The client used for requests, defaults to http.DefaultClient
+	Client *http.Client
+}
+
+// DefaultClasses are the classes sent if Config.Classes is empty
+var DefaultClasses = []Class{Stall, Alert, Recovery, Regression}
+
+// Timeout specifies the maximum time a single webhook request can take
+var Timeout = time.Second * 10
+
+// Notifier sends notifications to Slack
+type Notifier struct {
+	conf      Config
+	classes   map[Class]bool
+	templates notify.Templates
+
+	mtx        sync.Mutex
+	last       time.Time // the time of the last message
+	suppressed int
+
+	errors chan error
+
+	close  chan interface{}
+	done   chan interface{}
+	closer sync.Once
+}
+
+// NewNotifier creates a new notifier that begins sending notifications from the source immediately.
+// Returns an error if a template can't be parsed.
+// Starts a goroutine that can be stopped via notifier.Stop().
+func NewNotifier(src Source, conf Config) (*Notifier, error) {
+	if len(conf.Classes) == 0 {
+		conf.Classes = DefaultClasses
+	}
+	if conf.EveryNBlocks < 1 {
+		conf.EveryNBlocks = 1
+	}
+	if conf.Client == nil {
+		conf.Client = http.DefaultClient
+	}
+
+	n := new(Notifier)
+	n.conf = conf
+	n.classes = make(map[Class]bool)
+	for _, c := range conf.Classes {
+		n.classes[c] = true
+	}
+	defaults := make(map[string]string)
+	for c, text := range DefaultTemplates {
+		defaults[string(c)] = text
+	}
+	custom := make(map[string]string)
+	for c, text := range conf.Templates {
+		custom[string(c)] = text
+	}
+	var err error
+	if n.templates, err = notify.ParseTemplates(defaults, custom); err != nil {
+		return nil, fmt.Errorf("slack: %v", err)
+	}
+	n.errors = make(chan error, 6)
+	n.close = make(chan interface{})
+	n.done = make(chan interface{})
+	go n.run(src.NewAlertListener(), src.NewHeightListener(), src.NewRawListener())
+	return n, nil
+}
+
+// Errors returns a channel that receives errors from failed messages.
+// Errors are dropped if the channel is not read.
+func (n *Notifier) Errors() <-chan error {
+	return n.errors
+}
+
+func (n *Notifier) run(alerts <-chan monitor.Alert, heights <-chan int64, raw <-chan *monitor.MinuteResponse) {
+	defer close(n.done)
+
+	var height int64
+	for {
+		select {
+		case <-n.close:
+			return
+		case a, ok := <-alerts:
+			if !ok { // drained
+				alerts = nil
+				continue
+			}
+			c := Alert
+			if a.Status == monitor.AlertResolved {
+				c = Recovery
+			} else if a.Rule == "no-new-block" {
+				c = Stall
+			}
+			d := notify.AlertData(a)
+			d.Kind = string(c)
+			n.notify(d)
+		case h, ok := <-heights:
+			if !ok {
+				heights = nil
+				continue
+			}
+			if h%n.conf.EveryNBlocks == 0 {
+				n.notify(notify.Data{Kind: string(Block), Height: h, Time: time.Now()})
+			}
+		case resp, ok := <-raw:
+			if !ok {
+				raw = nil
+				continue
+			}
+			if resp.LeaderHeight < height {
+				n.notify(notify.Data{Kind: string(Regression), Height: resp.LeaderHeight, DBHeight: resp.DBHeight, Minute: resp.Minute, Previous: height, Time: time.Now()})
+			}
+			height = resp.LeaderHeight
+		}
+	}
+}
+
+// notify sends the message of the class if it is enabled and the rate limit allows it.
+// Stalls and recoveries are exempt from the rate limit.
+func (n *Notifier) notify(d notify.Data) {
+	if !n.classes[Class(d.Kind)] {
+		return
+	}
+	if n.conf.Leader != nil && !n.conf.Leader.IsLeader() {
+		return
+	}
+
+	n.mtx.Lock()
+	now := time.Now()
+	limited := d.Kind != string(Stall) && d.Kind != string(Recovery)
+	if limited && !n.last.IsZero() && now.Sub(n.last) < n.conf.MinInterval {
+		n.suppressed++
+		n.mtx.Unlock()
+		return
+	}
+	n.last = now
+	suppressed := n.suppressed
+	n.suppressed = 0
+	n.mtx.Unlock()
+
+	text, err := n.templates.Render(d)
+	if err != nil {
+		n.notifyError(err)
+		return
+	}
+	if suppressed > 0 {
+		text += fmt.Sprintf("\n_%d more notifications were suppressed_", suppressed)
+	}
+	n.notifyError(n.Post(text))
+}
+
+// Post sends a message with the configured channel, user name, and icon
+func (n *Notifier) Post(text string) error {
+	msg := struct {
+		Text      string `json:"text"`
+		Channel   string `json:"channel,omitempty"`
+		Username  string `json:"username,omitempty"`
+		IconEmoji string `json:"icon_emoji,omitempty"`
+	}{text, n.conf.Channel, n.conf.Username, n.conf.IconEmoji}
+	js, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodPost, n.conf.WebhookURL, bytes.NewReader(js))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.conf.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("slack: %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+func (n *Notifier) notifyError(err error) {
+	if err == nil {
+		return
+	}
+	select {
+	case n.errors <- err:
+	default:
+	}
+}
+
+// Stop halts the notifier
+func (n *Notifier) Stop() {
+	n.closer.Do(func() {
+		close(n.close)
+		<-n.done
+	})
+}
@@ -0,0 +1,149 @@
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+	"github.com/WhoSoup/factom-monitor/monitortest"
+)
+
+type fakeWebhook struct {
+	*httptest.Server
+	mtx      sync.Mutex
+	messages []map[string]string
+}
+
+func newFakeWebhook() *fakeWebhook {
+	w := new(fakeWebhook)
+	w.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var msg map[string]string
+		json.NewDecoder(r.Body).Decode(&msg)
+		w.mtx.Lock()
+		w.messages = append(w.messages, msg)
+		w.mtx.Unlock()
+	}))
+	return w
+}
+
+func (w *fakeWebhook) wait(n int) []map[string]string {
+	for i := 0; i < 100; i++ {
+		w.mtx.Lock()
+		m := w.messages
+		w.mtx.Unlock()
+		if len(m) >= n {
+			return m
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	return nil
+}
+
+func TestNotifier(t *testing.T) {
+	hook := newFakeWebhook()
+	defer hook.Close()
+
+	fake := monitortest.NewFakeMonitor(18, 0)
+	n, err := NewNotifier(fake, Config{
+		WebhookURL:   hook.URL,
+		Classes:      []Class{Stall, Recovery, Regression, Block},
+		EveryNBlocks: 10,
+		Templates:    map[Class]string{Block: "height {{.Height}} reached"},
+		Channel:      "#factom",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer n.Stop()
+
+	now := time.Now()
+	fake.SendAlert(monitor.Alert{Rule: "no-new-block", Status: monitor.AlertFiring, Message: "no new block for 12m0s since height 10"})
+	fake.SendAlert(monitor.Alert{Rule: "dbheight-lag", Status: monitor.AlertFiring, Message: "not sent"})
+	fake.SendAlert(monitor.Alert{Rule: "no-new-block", Status: monitor.AlertResolved, Since: now, Time: now.Add(time.Minute * 3)})
+	// the listeners are independent, wait to keep the messages in order
+	hook.wait(2)
+	fake.AdvanceHeight() // 19
+	fake.AdvanceHeight() // 20
+	hook.wait(3)
+	fake.SendRaw(&monitor.MinuteResponse{LeaderHeight: 20})
+	fake.SendRaw(&monitor.MinuteResponse{LeaderHeight: 18})
+
+	want := []string{
+		":rotating_light: *Stall*: no new block for 12m0s since height 10",
+		":white_check_mark: *no-new-block* resolved after 3m0s",
+		"height 20 reached",
+		":warning: *Regression*: node went back from height 20 to 18",
+	}
+	msgs := hook.wait(len(want))
+	if len(msgs) != len(want) {
+		t.Fatalf("unexpected messages: %v", msgs)
+	}
+	for i, w := range want {
+		if msgs[i]["text"] != w || msgs[i]["channel"] != "#factom" {
+			t.Errorf("unexpected message. got = %v, want = %s", msgs[i], w)
+		}
+	}
+
+	if _, err := NewNotifier(fake, Config{Templates: map[Class]string{Stall: "{{"}}); err == nil {
+		t.Error("no error for invalid template")
+	}
+}
+
+func TestNotifier_MinInterval(t *testing.T) {
+	hook := newFakeWebhook()
+	defer hook.Close()
+
+	fake := monitortest.NewFakeMonitor(0, 0)
+	n, err := NewNotifier(fake, Config{WebhookURL: hook.URL, Classes: []Class{Block}, MinInterval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer n.Stop()
+
+	fake.AdvanceHeight() // 1
+	fake.AdvanceHeight() // 2
+	fake.AdvanceHeight() // 3
+	time.Sleep(time.Millisecond * 150)
+	fake.AdvanceHeight() // 4
+
+	msgs := hook.wait(2)
+	if len(msgs) != 2 || msgs[1]["text"] != ":package: Block 4\n_2 more notifications were suppressed_" {
+		t.Errorf("unexpected messages: %v", msgs)
+	}
+}
+
+func TestNotifier_MinIntervalStateChanges(t *testing.T) {
+	hook := newFakeWebhook()
+	defer hook.Close()
+
+	fake := monitortest.NewFakeMonitor(0, 0)
+	n, err := NewNotifier(fake, Config{WebhookURL: hook.URL, Classes: []Class{Block, Stall, Recovery}, MinInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer n.Stop()
+
+	fake.AdvanceHeight() // 1
+	hook.wait(1)
+	fake.AdvanceHeight() // 2, suppressed
+	time.Sleep(time.Millisecond * 50)
+	fake.SendAlert(monitor.Alert{Rule: "no-new-block", Status: monitor.AlertFiring, Message: "no block for 15m0s"})
+	hook.wait(2)
+	since := time.Unix(1600000000, 0)
+	fake.SendAlert(monitor.Alert{Rule: "no-new-block", Status: monitor.AlertResolved, Since: since, Time: since.Add(time.Minute * 20)})
+
+	msgs := hook.wait(3)
+	if len(msgs) != 3 {
+		t.Fatalf("unexpected messages: %v", msgs)
+	}
+	if msgs[1]["text"] != ":rotating_light: *Stall*: no block for 15m0s\n_1 more notifications were suppressed_" {
+		t.Errorf("unexpected stall message: %q", msgs[1]["text"])
+	}
+	if msgs[2]["text"] != ":white_check_mark: *no-new-block* resolved after 20m0s" {
+		t.Errorf("unexpected recovery message: %q", msgs[2]["text"])
+	}
+}
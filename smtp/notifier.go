@@ -0,0 +1,235 @@
+// Package smtp emails high-severity monitor alerts. Alerts that arrive shortly after
+// another email was sent are collected into a digest, so a flapping node doesn't flood
+// the inbox.
+package smtp
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+	"github.com/WhoSoup/factom-monitor/notify"
+)
+
+// Source is the subset of a monitor used by the notifier
+type Source interface {
+	NewAlertListener() <-chan monitor.Alert
+}
+
+// Config contains the settings of a notifier
+type Config struct {
+	// The address of the mail server, ie "smtp.example.com:587"
+	Addr string
+	// Credentials for PLAIN authentication, leave empty to send without authentication
+	Username string
+	Password string
+	// The sender and recipients
+	From string
+	To   []string
+	// Alerts below the severity are ignored, defaults to critical.
+	// Resolved alerts are sent if they resolve an alert of the severity.
+	MinSeverity monitor.Severity
+	// After an email was sent, further alerts are collected for Window and then sent as
+	// a single digest. Defaults to 15 minutes.
+	Window time.Duration
+	// Prepended to every subject, defaults to "[factom-monitor]"
+	SubjectPrefix string
+	// Templates replace the DefaultTemplates by kind. The "subject" template is the
+	// subject of emails with a single alert.
+	Templates map[string]string
+	// Leader, if set, limits notifications to the times it is the leader, so only one of
+	// several monitor instances sends them. See monitor.Elector.
+	Leader monitor.Leader
+}
+
+// DefaultTemplates are the subject of single alert emails and the text of each firing
+// and resolved alert. See package notify for the available fields and functions.
+var DefaultTemplates = map[string]string{
+	"subject": `{{.Alert.Status}}: {{.Alert.Rule}}`,
+	notify.KindFiring: `FIRING: {{.Alert.Rule}} ({{.Alert.Severity}})
+{{.Alert.Message}}
+Time: {{time .Time}}
+Height: {{.Height}}, minute {{.Minute}}, dbheight {{.DBHeight}}
+{{if .Network}}Network: {{.Network}}
+{{end}}`,
+	notify.KindResolved: `RESOLVED: {{.Alert.Rule}} ({{.Alert.Severity}})
+{{.Alert.Message}}
+Time: {{time .Time}}
+Duration: {{duration .Alert.Duration}}
+Height: {{.Height}}, minute {{.Minute}}, dbheight {{.DBHeight}}
+{{if .Network}}Network: {{.Network}}
+{{end}}`,
+}
+
+// sendMail is replaced in tests
+var sendMail = smtp.SendMail
+
+// Notifier emails alerts
+type Notifier struct {
+	conf      Config
+	auth      smtp.Auth
+	templates notify.Templates
+
+	errors chan error
+
+	close  chan interface{}
+	done   chan interface{}
+	closer sync.Once
+}
+
+// NewNotifier creates a new notifier that begins emailing alerts from the source immediately.
+// Returns an error if a template can't be parsed.
+// Starts a goroutine that can be stopped via notifier.Stop().
+func NewNotifier(src Source, conf Config) (*Notifier, error) {
+	if conf.MinSeverity == monitor.SeverityInfo {
+		conf.MinSeverity = monitor.SeverityCritical
+	}
+	if conf.Window <= 0 {
+		conf.Window = time.Minute * 15
+	}
+	if conf.SubjectPrefix == "" {
+		conf.SubjectPrefix = "[factom-monitor]"
+	}
+	n := new(Notifier)
+	n.conf = conf
+	var err error
+	if n.templates, err = notify.ParseTemplates(DefaultTemplates, conf.Templates); err != nil {
+		return nil, fmt.Errorf("smtp: %v", err)
+	}
+	if conf.Username != "" {
+		host, _, _ := net.SplitHostPort(conf.Addr)
+		n.auth = smtp.PlainAuth("", conf.Username, conf.Password, host)
+	}
+	n.errors = make(chan error, 6)
+	n.close = make(chan interface{})
+	n.done = make(chan interface{})
+	go n.run(src.NewAlertListener())
+	return n, nil
+}
+
+// Errors returns a channel that receives errors from failed emails.
+// Errors are dropped if the channel is not read.
+func (n *Notifier) Errors() <-chan error {
+	return n.errors
+}
+
+func (n *Notifier) run(alerts <-chan monitor.Alert) {
+	defer close(n.done)
+
+	var digest []monitor.Alert
+	var window <-chan time.Time // nil outside of a window
+	for {
+		select {
+		case <-n.close:
+			if len(digest) > 0 {
+				n.notifyError(n.Send(digest...))
+			}
+			return
+		case a, ok := <-alerts:
+			if !ok { // drained, send the pending digest
+				if len(digest) > 0 {
+					n.notifyError(n.Send(digest...))
+				}
+				return
+			}
+			if a.Severity < n.conf.MinSeverity {
+				continue
+			}
+			if n.conf.Leader != nil && !n.conf.Leader.IsLeader() {
+				continue
+			}
+			if window != nil {
+				digest = append(digest, a)
+				continue
+			}
+			n.notifyError(n.Send(a))
+			window = time.After(n.conf.Window)
+		case <-window:
+			if len(digest) == 0 {
+				window = nil
+				continue
+			}
+			n.notifyError(n.Send(digest...))
+			digest = nil
+			window = time.After(n.conf.Window)
+		}
+	}
+}
+
+// Send emails the alerts, as a digest if there is more than one
+func (n *Notifier) Send(alerts ...monitor.Alert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+	var subject string
+	if len(alerts) == 1 {
+		d := notify.AlertData(alerts[0])
+		d.Kind = "subject"
+		text, err := n.templates.Render(d)
+		if err != nil {
+			return fmt.Errorf("smtp: %v", err)
+		}
+		subject = n.conf.SubjectPrefix + " " + strings.TrimSpace(text)
+	} else {
+		subject = fmt.Sprintf("%s %d alerts", n.conf.SubjectPrefix, len(alerts))
+	}
+
+	var body bytes.Buffer
+	for i, a := range alerts {
+		if i > 0 {
+			body.WriteString("\r\n")
+		}
+		text, err := n.templates.Render(notify.AlertData(a))
+		if err != nil {
+			return fmt.Errorf("smtp: %v", err)
+		}
+		body.WriteString(crlf(text))
+	}
+
+	if err := sendMail(n.conf.Addr, n.auth, n.conf.From, n.conf.To, n.message(subject, body.Bytes())); err != nil {
+		return fmt.Errorf("smtp: %v", err)
+	}
+	return nil
+}
+
+// crlf converts line endings to CRLF, as required by SMTP
+func crlf(s string) string {
+	return strings.Replace(strings.Replace(s, "\r\n", "\n", -1), "\n", "\r\n", -1)
+}
+
+// message adds the headers to the body
+func (n *Notifier) message(subject string, body []byte) []byte {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.conf.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.conf.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("\r\n")
+	msg.Write(body)
+	return msg.Bytes()
+}
+
+func (n *Notifier) notifyError(err error) {
+	if err == nil {
+		return
+	}
+	select {
+	case n.errors <- err:
+	default:
+	}
+}
+
+// Stop halts the notifier after sending the pending digest
+func (n *Notifier) Stop() {
+	n.closer.Do(func() {
+		close(n.close)
+		<-n.done
+	})
+}
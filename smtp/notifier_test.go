@@ -0,0 +1,101 @@
+package smtp
+
+import (
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+	"github.com/WhoSoup/factom-monitor/monitortest"
+)
+
+type mail struct {
+	from string
+	to   []string
+	msg  string
+}
+
+func TestNotifier(t *testing.T) {
+	var mtx sync.Mutex
+	var mails []mail
+	sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		mails = append(mails, mail{from, to, string(msg)})
+		return nil
+	}
+	defer func() { sendMail = smtp.SendMail }()
+
+	fake := monitortest.NewFakeMonitor(10, 0)
+	n, err := NewNotifier(fake, Config{
+		Addr:   "localhost:25",
+		From:   "monitor@example.com",
+		To:     []string{"ops@example.com", "oncall@example.com"},
+		Window: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stall := monitor.Alert{Rule: "no-new-block", Severity: monitor.SeverityCritical, Status: monitor.AlertFiring, Message: "no new block for 12m0s since height 10"}
+	fake.SendAlert(stall)
+	fake.SendAlert(monitor.Alert{Rule: "dbheight-lag", Severity: monitor.SeverityWarning, Status: monitor.AlertFiring})
+	resolved := stall
+	resolved.Status = monitor.AlertResolved
+	fake.SendAlert(resolved)
+	fake.SendAlert(stall)
+	fake.Drain() // sends the digest
+	select {
+	case <-n.done:
+	case <-time.After(time.Second):
+		t.Fatal("notifier did not stop after the drain")
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	if len(mails) != 2 {
+		t.Fatalf("unexpected mails: %v", mails)
+	}
+	if m := mails[0]; m.from != "monitor@example.com" || len(m.to) != 2 ||
+		!strings.Contains(m.msg, "To: ops@example.com, oncall@example.com\r\n") ||
+		!strings.Contains(m.msg, "Subject: [factom-monitor] firing: no-new-block\r\n") ||
+		!strings.Contains(m.msg, "\r\n\r\nFIRING: no-new-block (critical)\r\nno new block for 12m0s since height 10\r\n") {
+		t.Errorf("unexpected first mail:\n%s", m.msg)
+	}
+	if m := mails[1]; !strings.Contains(m.msg, "Subject: [factom-monitor] 2 alerts\r\n") ||
+		!strings.Contains(m.msg, "RESOLVED: no-new-block") || strings.Contains(m.msg, "dbheight-lag") {
+		t.Errorf("unexpected digest:\n%s", m.msg)
+	}
+}
+
+func TestNotifier_Templates(t *testing.T) {
+	var msg string
+	sendMail = func(addr string, a smtp.Auth, from string, to []string, m []byte) error {
+		msg = string(m)
+		return nil
+	}
+	defer func() { sendMail = smtp.SendMail }()
+
+	fake := monitortest.NewFakeMonitor(10, 0)
+	n, err := NewNotifier(fake, Config{Templates: map[string]string{
+		"subject": "{{upper .Alert.Rule}} at {{.Height}}",
+		"firing":  "Rule {{.Alert.Rule}} fired\nCheck the node",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer n.Stop()
+
+	if err := n.Send(monitor.Alert{Rule: "no-new-block", Height: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(msg, "Subject: [factom-monitor] NO-NEW-BLOCK at 10\r\n") || !strings.HasSuffix(msg, "\r\n\r\nRule no-new-block fired\r\nCheck the node") {
+		t.Errorf("unexpected mail:\n%s", msg)
+	}
+
+	if _, err := NewNotifier(fake, Config{Templates: map[string]string{"firing": "{{.Missing"}}); err == nil {
+		t.Error("no error for invalid template")
+	}
+}
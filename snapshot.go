@@ -0,0 +1,231 @@
+package monitor
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// ErrStarted is returned by Restore if the monitor has already been started
+var ErrStarted = errors.New("monitor has already been started")
+
+// Snapshot is the state of a monitor that is needed to resume monitoring after a planned
+// restart without gaps: the position in the network, everything that is watched along
+// with the last known values, and the acknowledged cursors of durable listeners.
+// It can be encoded as JSON.
+//
+// Listeners, alert rules, WatchSync, and the settings of the monitor are not part of a
+// snapshot and have to be set up again.
+type Snapshot struct {
+	// The most recent state delivered to listeners, with the time of the snapshot
+	Cursor Cursor `json:"cursor"`
+
+	// Watched chains and their known heads
+	Chains map[string]string `json:"chains,omitempty"`
+	// Watched factoid and entry credit addresses and their known balances
+	FactoidAddresses map[string]int64 `json:"factoidaddresses,omitempty"`
+	ECAddresses      map[string]int64 `json:"ecaddresses,omitempty"`
+	// Whether the entry credit rate is watched, and its known value
+	ECRateWatched bool  `json:"ecratewatched,omitempty"`
+	ECRate        int64 `json:"ecrate,omitempty"`
+
+	PendingEntries      bool `json:"pendingentries,omitempty"`
+	PendingTransactions bool `json:"pendingtransactions,omitempty"`
+	// The addresses pending transactions are filtered by
+	TransactionAddresses []string `json:"transactionaddresses,omitempty"`
+
+	DirectoryBlocks bool `json:"directoryblocks,omitempty"`
+	Authorities     bool `json:"authorities,omitempty"`
+	Transactions    bool `json:"transactions,omitempty"`
+	ECBlocks        bool `json:"ecblocks,omitempty"`
+	Anchors         bool `json:"anchors,omitempty"`
+	Coinbase        bool `json:"coinbase,omitempty"`
+	Diagnostics     bool `json:"diagnostics,omitempty"`
+
+	// The acknowledged cursors of the monitor's durable listeners by name
+	Subscriptions map[string]Cursor `json:"subscriptions,omitempty"`
+}
+
+// Snapshot returns the monitor's current state, see Snapshot
+func (m *Monitor) Snapshot() Snapshot {
+	var s Snapshot
+	state := m.State()
+	s.Cursor = Cursor{Height: state.Height, DBHeight: state.DBHeight, Minute: state.Minute, Time: m.clock().Now()}
+
+	m.watchMtx.Lock()
+	s.Chains = copyStrings(m.chains)
+	s.FactoidAddresses = copyInts(m.fctBalances)
+	s.ECAddresses = copyInts(m.ecBalances)
+	s.ECRateWatched = m.ecRateWatched
+	if m.ecRateWatched {
+		s.ECRate = m.ecRate
+	}
+	s.PendingEntries = m.pending != nil
+	s.PendingTransactions = m.pendingTxs != nil
+	for addr := range m.txAddresses {
+		s.TransactionAddresses = append(s.TransactionAddresses, addr)
+	}
+	sort.Strings(s.TransactionAddresses)
+	s.DirectoryBlocks = m.dblocks
+	s.Authorities = m.ablocks
+	s.Transactions = m.fblocks
+	s.ECBlocks = m.ecblocks
+	s.Anchors = m.anchors != nil
+	s.Coinbase = m.coinbase
+	s.Diagnostics = m.network != nil
+	durables := make([]*DurableListener, 0, len(m.durables))
+	for _, d := range m.durables {
+		durables = append(durables, d)
+	}
+	m.watchMtx.Unlock()
+
+	for _, d := range durables {
+		d.mtx.Lock()
+		if d.acked != nil {
+			if s.Subscriptions == nil {
+				s.Subscriptions = make(map[string]Cursor)
+			}
+			s.Subscriptions[d.name] = *d.acked
+		}
+		d.mtx.Unlock()
+	}
+	return s
+}
+
+// Restore picks up where the snapshot left off. It must be called after New and before
+// the monitor is started for the first time.
+//
+// Everything in the snapshot is watched again with the values it had at the time of the
+// snapshot, so entries, balance changes, and rate changes that happened in between are
+// reported after the first new dbheight. The snapshot's cursor is returned by Resumed
+// and used by Missed, unless the Store has a more recent one. Subscription cursors are
+// saved to Config.Subscriptions unless it has more recent ones, so durable listeners
+// created afterwards resume after them.
+// A concurrent Start waits until the restore is complete.
+func (m *Monitor) Restore(s Snapshot) error {
+	for id := range s.Chains {
+		if err := checkHash("chain id", id); err != nil {
+			return err
+		}
+	}
+	for addr := range s.FactoidAddresses {
+		if err := checkAddress("FA", addr); err != nil {
+			return err
+		}
+	}
+	for addr := range s.ECAddresses {
+		if err := checkAddress("EC", addr); err != nil {
+			return err
+		}
+	}
+	for _, addr := range s.TransactionAddresses {
+		prefix := "FA"
+		if strings.HasPrefix(addr, "EC") {
+			prefix = "EC"
+		}
+		if err := checkAddress(prefix, addr); err != nil {
+			return err
+		}
+	}
+	if len(s.Subscriptions) > 0 && m.conf.Subscriptions == nil {
+		return ErrNoSubscriptionStore
+	}
+
+	m.restoreMtx.Lock()
+	defer m.restoreMtx.Unlock()
+	m.runMtx.Lock()
+	started := m.started
+	m.runMtx.Unlock()
+	if started {
+		return ErrStarted
+	}
+
+	for name, c := range s.Subscriptions {
+		saved, err := m.conf.Subscriptions.Load(name)
+		if err != nil {
+			return err
+		}
+		if saved != nil && !after(c.Height, c.Minute, saved.Height, saved.Minute) {
+			continue
+		}
+		if err := m.conf.Subscriptions.Save(name, c); err != nil {
+			return err
+		}
+	}
+
+	m.watchMtx.Lock()
+	for id, head := range s.Chains {
+		m.watchChainHead(id, head)
+	}
+	for addr, balance := range s.FactoidAddresses {
+		m.watchFactoidBalance(addr, balance)
+	}
+	for addr, balance := range s.ECAddresses {
+		m.watchECBalance(addr, balance)
+	}
+	if s.ECRateWatched || s.ECRate > 0 { // snapshots before ECRateWatched only had the rate
+		m.watchECRate(s.ECRate)
+	}
+	m.watchMtx.Unlock()
+	m.saveWatchlist()
+
+	if s.PendingEntries {
+		m.WatchPendingEntries()
+	}
+	if s.PendingTransactions {
+		if err := m.WatchPendingTransactions(s.TransactionAddresses...); err != nil {
+			return err
+		}
+	}
+	if s.DirectoryBlocks {
+		m.WatchDirectoryBlocks()
+	}
+	if s.Authorities {
+		m.WatchAuthorities()
+	}
+	if s.Transactions {
+		m.WatchTransactions()
+	}
+	if s.ECBlocks {
+		m.WatchECBlocks()
+	}
+	if s.Anchors {
+		m.WatchAnchors()
+	}
+	if s.Coinbase {
+		m.WatchCoinbase()
+	}
+	if s.Diagnostics {
+		m.WatchDiagnostics()
+	}
+
+	m.heightMtx.Lock()
+	if m.resumed == nil || after(s.Cursor.Height, s.Cursor.Minute, m.resumed.Height, m.resumed.Minute) {
+		c := s.Cursor
+		m.resumed = &c
+	}
+	m.heightMtx.Unlock()
+	return nil
+}
+
+func copyStrings(src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return nil
+	}
+	dst := make(map[string]string, len(src))
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
+func copyInts(src map[string]int64) map[string]int64 {
+	if len(src) == 0 {
+		return nil
+	}
+	dst := make(map[string]int64, len(src))
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
@@ -0,0 +1,145 @@
+package monitor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// memSubscriptions is a SubscriptionStore in memory
+type memSubscriptions map[string]Cursor
+
+func (ms memSubscriptions) Load(name string) (*Cursor, error) {
+	c, ok := ms[name]
+	if !ok {
+		return nil, nil
+	}
+	return &c, nil
+}
+
+func (ms memSubscriptions) Save(name string, c Cursor) error {
+	ms[name] = c
+	return nil
+}
+
+func TestMonitor_Restore(t *testing.T) {
+	s := newTestServer("localhost:9834", 10, 5, time.Second*10, t)
+	defer s.stop()
+	fc := newFakeChain(s, hash("c"), hash("a"))
+
+	m, err := NewMonitorWithConfig("http://localhost:9834/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.WatchChain(hash("c")); err != nil {
+		t.Fatal(err)
+	}
+	m.WatchPendingEntries()
+	m.Stop()
+
+	js, err := json.Marshal(m.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(js, &snap); err != nil {
+		t.Fatal(err)
+	}
+	if snap.Cursor.Height != 10 || snap.Cursor.Minute != 5 || snap.Chains[hash("c")] != hash("a") || !snap.PendingEntries || snap.Anchors {
+		t.Errorf("unexpected snapshot %s", js)
+	}
+	if err := m.Restore(snap); err != ErrStarted {
+		t.Errorf("unexpected error restoring a started monitor. got = %v, want = %v", err, ErrStarted)
+	}
+
+	// entries added while no monitor is running
+	s.mtx.Lock()
+	fc.add(hash("b"), 10, hash("1"))
+	s.mtx.Unlock()
+
+	snap.Subscriptions = map[string]Cursor{"alerts": {Height: 9, Minute: 3}}
+	m2, err := New("http://localhost:9834/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m2.Restore(snap); err != ErrNoSubscriptionStore {
+		t.Errorf("unexpected error without subscription store. got = %v, want = %v", err, ErrNoSubscriptionStore)
+	}
+
+	subs := memSubscriptions{"alerts": {Height: 9, Minute: 1}}
+	m2, err = New("http://localhost:9834/v2", Config{Interval: time.Millisecond * 100, Subscriptions: subs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m2.Restore(snap); err != nil {
+		t.Fatal(err)
+	}
+	if subs["alerts"].Minute != 3 {
+		t.Errorf("subscription cursor not restored: %+v", subs["alerts"])
+	}
+	if c, ok := m2.Resumed(); !ok || c.Height != 10 || c.Minute != 5 {
+		t.Errorf("unexpected resumed cursor %+v", c)
+	}
+	if w := m2.WatchedChains(); len(w) != 1 || w[0] != hash("c") {
+		t.Errorf("unexpected watched chains %v", w)
+	}
+
+	listener := m2.NewEntryListener()
+	if err := m2.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer m2.Stop()
+
+	for i := 0; i < 6; i++ {
+		s.tick() // until height 11 minute 1, which saves dbheight 11
+	}
+	select {
+	case e := <-listener:
+		if e.EntryHash != hash("1") {
+			t.Errorf("unexpected entry %+v", e)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("entry added before the restore not received")
+	}
+}
+
+func TestMonitor_RestoreECRate(t *testing.T) {
+	m, err := New("http://localhost:9814/v2", Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Restore(Snapshot{ECRateWatched: true}); err != nil {
+		t.Fatal(err)
+	}
+	if snap := m.Snapshot(); !snap.ECRateWatched || snap.ECRate != 0 {
+		t.Errorf("watched rate of 0 not restored: %+v", snap)
+	}
+}
+
+func TestMonitor_RestoreConcurrentStart(t *testing.T) {
+	s := newTestServer("localhost:9813", 10, 5, time.Second*10, t)
+	defer s.stop()
+	newFakeChain(s, hash("c"), hash("a"))
+
+	m, err := New("http://localhost:9813/v2", Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	started := make(chan error)
+	go func() { started <- m.Start() }()
+	err = m.Restore(Snapshot{Chains: map[string]string{hash("c"): hash("a")}})
+	if err := <-started; err != nil {
+		t.Fatal(err)
+	}
+	switch err {
+	case nil:
+		if w := m.WatchedChains(); len(w) != 1 {
+			t.Errorf("restore before start lost chains: %v", w)
+		}
+	case ErrStarted:
+	default:
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,137 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+	"github.com/WhoSoup/factom-monitor/monitortest"
+)
+
+type exec struct {
+	query string
+	args  []interface{}
+}
+
+type fakeDB struct {
+	mtx      sync.Mutex
+	execs    []exec
+	failures int
+}
+
+func (db *fakeDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+	if db.failures > 0 {
+		db.failures--
+		return nil, errors.New("connection refused")
+	}
+	db.execs = append(db.execs, exec{query, args})
+	return nil, nil
+}
+
+func (db *fakeDB) wait(n int) []exec {
+	for i := 0; i < 100; i++ {
+		db.mtx.Lock()
+		e := db.execs
+		db.mtx.Unlock()
+		if len(e) >= n {
+			return e
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	return nil
+}
+
+func TestMigrate(t *testing.T) {
+	db := new(fakeDB)
+	if err := Migrate(context.Background(), db, `my"table`); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.execs) != 3 || !strings.HasPrefix(db.execs[0].query, `CREATE TABLE IF NOT EXISTS "my""table"`) {
+		t.Errorf("unexpected statements: %v", db.execs)
+	}
+
+	db.failures = 1
+	if err := Migrate(context.Background(), db, "events"); err == nil {
+		t.Error("no error for failed statement")
+	}
+}
+
+func TestWriter(t *testing.T) {
+	db := new(fakeDB)
+	w := NewWriter(db, "events")
+	now := time.Unix(1600000001, 0)
+	w.now = func() time.Time { return now }
+
+	start := time.Unix(1600000000, 0)
+	err := w.Write(context.Background(),
+		monitor.Event{Network: "mainnet", Height: 10, DBHeight: 9, Minute: 0, BlockStart: start, MinuteStart: start, NodeTime: start.Add(time.Second * 3)},
+		monitor.Event{Height: 10, DBHeight: 10, Minute: 1},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `INSERT OR IGNORE INTO "events" (network, height, dbheight, minute, block_start, minute_start, node_time, received_at, detection_ms) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?), (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	if len(db.execs) != 1 || db.execs[0].query != want {
+		t.Fatalf("unexpected statement: %v", db.execs)
+	}
+	args := db.execs[0].args
+	if len(args) != 18 || args[0] != "mainnet" || args[1] != int64(10) {
+		t.Errorf("unexpected args: %v", args)
+	}
+	if bs := args[4].(sql.NullInt64); !bs.Valid || bs.Int64 != 1600000000000 {
+		t.Errorf("unexpected block start: %v", bs)
+	}
+	if d := args[8].(sql.NullInt64); !d.Valid || d.Int64 != 3000 {
+		t.Errorf("unexpected detection time: %v", d)
+	}
+	if bs := args[13].(sql.NullInt64); bs.Valid {
+		t.Errorf("zero block start is not null: %v", bs)
+	}
+
+	// split to stay below the variable limit
+	db.execs = nil
+	if err := w.Write(context.Background(), make([]monitor.Event, 250)...); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.execs) != 3 || len(db.execs[0].args) != 999 || len(db.execs[2].args) != 28*9 {
+		t.Errorf("unexpected statements for large batch: %d", len(db.execs))
+	}
+}
+
+func TestSink_Retry(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(1, 0)
+	db := &fakeDB{failures: 2}
+	conf := DefaultConfig()
+	conf.RetryDelay = time.Millisecond * 10
+	s := NewSink(db, fake, conf)
+	defer s.Stop()
+
+	fake.SendEvent(monitor.Event{Height: 1})
+	fake.SendEvent(monitor.Event{Height: 1, Minute: 1})
+	fake.Drain() // sends the incomplete batch
+	select {
+	case <-s.done:
+	case <-time.After(time.Second):
+		t.Fatal("sink did not stop after the drain")
+	}
+
+	if e := db.wait(1); len(e) != 1 || len(e[0].args) != 18 {
+		t.Errorf("batch was not retried: %v", e)
+	}
+	select {
+	case err := <-s.Errors():
+		if err == nil {
+			t.Error("nil error")
+		}
+	default:
+		t.Error("failed batch not reported")
+	}
+}
@@ -0,0 +1,100 @@
+package monitor
+
+import "time"
+
+// State is a snapshot of everything the monitor knows about the network and the node
+type State struct {
+	// The most recent block saved in the node's database
+	DBHeight int64
+	// The most recently completed block in the network
+	Height int64
+	// The minute the network is currently working on
+	Minute int64
+
+	// The time of the most recent successful poll
+	LastPoll time.Time
+	// The time the node started working on the current block
+	BlockStart time.Time
+	// The time the node started working on the current minute
+	MinuteStart time.Time
+	// The node's clock at the time of the most recent successful poll. Zero if the node
+	// doesn't report it.
+	NodeTime time.Time
+	// The network the node belongs to, ie "mainnet" or "testnet". Empty if it could
+	// not be detected yet.
+	Network string
+
+	// Healthy is true if the most recent poll succeeded
+	Healthy bool
+	// The number of consecutive failed polls
+	Failures int
+	// The error of the most recent failed poll, cleared by a successful poll
+	LastError error
+}
+
+// State returns a snapshot of the monitor's current state
+func (m *Monitor) State() State {
+	m.heightMtx.Lock()
+	defer m.heightMtx.Unlock()
+	s := State{
+		DBHeight:    m.dbheight,
+		Height:      m.height,
+		Minute:      m.minute,
+		LastPoll:    m.lastPoll,
+		BlockStart:  m.blockStart,
+		MinuteStart: m.minuteStart,
+		NodeTime:    m.nodeTime,
+		Healthy:     m.failures == 0,
+		Failures:    m.failures,
+		LastError:   m.lastError,
+	}
+	if m.networkKnown {
+		s.Network = m.networkID.String()
+	}
+	return s
+}
+
+// polled updates the state after every poll. resp is nil if the poll failed.
+func (m *Monitor) polled(resp *MinuteResponse, err error) {
+	m.polls.add(m.clock().Now(), err != nil)
+
+	m.heightMtx.Lock()
+	defer m.heightMtx.Unlock()
+	if err != nil {
+		m.failures++
+		m.lastError = err
+		return
+	}
+	m.failures = 0
+	m.lastError = nil
+	m.lastPoll = m.clock().Now()
+	m.blockStart = resp.BlockStart()
+	m.minuteStart = resp.MinuteStart()
+	m.blockSeconds = resp.DBlockSeconds
+	m.nodeTime = resp.NodeTime()
+	if !m.nodeTime.IsZero() {
+		m.clockOffset = m.lastPoll.Sub(m.nodeTime)
+	}
+}
+
+// partial returns an event for the minute in progress.
+// The second return value is false if the monitor hasn't polled the node yet.
+func (m *Monitor) partial() (Event, bool) {
+	m.heightMtx.Lock()
+	defer m.heightMtx.Unlock()
+	if m.lastPoll.IsZero() {
+		return Event{}, false
+	}
+	e := Event{
+		DBHeight:    m.dbheight,
+		Height:      m.height,
+		Minute:      m.minute,
+		BlockStart:  m.blockStart,
+		MinuteStart: m.minuteStart,
+		Partial:     true,
+	}
+	if m.networkKnown {
+		e.Network = m.networkID.String()
+	}
+	return e, true
+}
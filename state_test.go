@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonitor_State(t *testing.T) {
+	s := newTestServer("localhost:9881", 10, 5, time.Second*10, t)
+	defer s.stop()
+
+	before := time.Now()
+	m, err := NewMonitorWithConfig("http://localhost:9881/v2", Config{Interval: time.Millisecond * 100, Timeout: time.Millisecond * 250})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	state := m.State()
+	if state.Height != 10 || state.DBHeight != 10 || state.Minute != 5 {
+		t.Errorf("unexpected heights. got = %d/%d/%d, want = 10/10/5", state.Height, state.DBHeight, state.Minute)
+	}
+	if !state.Healthy || state.Failures != 0 || state.LastError != nil {
+		t.Errorf("new monitor is not healthy: %+v", state)
+	}
+	if state.LastPoll.Before(before) {
+		t.Errorf("last poll %v is before the monitor was created %v", state.LastPoll, before)
+	}
+	if !state.BlockStart.Equal(s.blockstart) {
+		t.Errorf("unexpected block start. got = %v, want = %v", state.BlockStart, s.blockstart)
+	}
+	if state.NodeTime.Before(state.MinuteStart) {
+		t.Errorf("node time %v is before the minute start %v", state.NodeTime, state.MinuteStart)
+	}
+
+	s.stop()
+	time.Sleep(time.Millisecond * 500)
+
+	state = m.State()
+	if state.Healthy || state.Failures == 0 || state.LastError == nil {
+		t.Errorf("monitor still healthy after server stopped: %+v", state)
+	}
+}
+
+func TestMonitor_IncludePartial(t *testing.T) {
+	s := newTestServer("localhost:9843", 10, 5, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitorWithConfig("http://localhost:9843/v2", Config{IncludePartial: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	select {
+	case e := <-m.NewMinuteListener():
+		if !e.Partial || e.Height != 10 || e.DBHeight != 10 || e.Minute != 5 || e.Sequence != 0 {
+			t.Errorf("unexpected partial event %+v", e)
+		}
+	default:
+		t.Error("no partial event")
+	}
+
+	plain, err := NewMonitor("http://localhost:9843/v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plain.Stop()
+	select {
+	case e := <-plain.NewMinuteListener():
+		t.Errorf("partial event without IncludePartial: %+v", e)
+	default:
+	}
+}
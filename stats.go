@@ -0,0 +1,159 @@
+package monitor
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// listenerStats tracks the deliveries of a single listener
+type listenerStats struct {
+	kind    string
+	name    string
+	ch      reflect.Value
+	dropped uint64
+	// true if the last event could not be delivered
+	slow bool
+	// true once the channel was closed by StopAndDrain
+	closed bool
+}
+
+// ListenerStats contains the counters of a single listener
+type ListenerStats struct {
+	// The name given to the listener, empty if it was created without one
+	Name string `json:"name,omitempty"`
+	// The type of listener, ie "minute" or "height"
+	Type string `json:"type"`
+	// The number of events waiting in the listener's buffer
+	Buffered int `json:"buffered"`
+	// The size of the listener's buffer
+	Capacity int `json:"capacity"`
+	// The number of events dropped because the listener's buffer was full
+	Dropped uint64 `json:"dropped"`
+}
+
+// Stats contains counters about the monitor's listeners and requests
+type Stats struct {
+	// The total number of events dropped by all listeners
+	Dropped uint64 `json:"dropped"`
+	// Every listener, in the order they were created
+	Listeners []ListenerStats `json:"listeners"`
+	// The latencies of recent API requests, see LatencyHistogram
+	Latency LatencyHistogram `json:"latency"`
+	// The times between commit and reveal of tracked entries, see RevealLatency
+	RevealLatency LatencyHistogram `json:"reveallatency"`
+	// The entries and chains of recent directory blocks, see ThroughputStats
+	Throughput ThroughputStats `json:"throughput"`
+	// The nodes of the pool and their blacklist state, see Config.CourtesyNodes
+	Nodes []NodeStats `json:"nodes,omitempty"`
+}
+
+// SlowConsumerError is sent to error listeners when a listener starts dropping events
+// because its reader can't keep up. It is sent again only after the listener has
+// received an event in the meantime. Drops of error listeners are not reported.
+type SlowConsumerError struct {
+	// The name of the listener, empty if it was created without one
+	Name string
+	// The type of listener, ie "minute" or "height"
+	Type string
+	// The total number of events the listener dropped so far
+	Dropped uint64
+}
+
+func (e *SlowConsumerError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("slow consumer: %s listener %q dropped %d events", e.Type, e.Name, e.Dropped)
+	}
+	return fmt.Sprintf("slow consumer: %s listener dropped %d events", e.Type, e.Dropped)
+}
+
+// Stats returns the number of events dropped by each listener, the latencies of
+// recent API requests, the commit to reveal latencies of recently tracked entries,
+// the entry throughput of recent blocks, and the blacklist state of the courtesy nodes
+func (m *Monitor) Stats() Stats {
+	var s Stats
+	s.Listeners = m.Listeners()
+	for _, l := range s.Listeners {
+		s.Dropped += l.Dropped
+	}
+	s.Latency = m.LatencyHistogram()
+	s.RevealLatency = m.RevealLatency()
+	s.Throughput = m.ThroughputStats()
+	s.Nodes = m.Nodes()
+	return s
+}
+
+// Listeners returns the name, type, buffer usage, and number of dropped events of every
+// listener, including the ones the monitor uses internally, in the order they were created.
+func (m *Monitor) Listeners() []ListenerStats {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	list := make([]ListenerStats, 0, len(m.listenerStats))
+	for _, ls := range m.listenerStats {
+		list = append(list, ListenerStats{
+			Name:     ls.name,
+			Type:     ls.kind,
+			Buffered: ls.ch.Len(),
+			Capacity: ls.ch.Cap(),
+			Dropped:  ls.dropped,
+		})
+	}
+	return list
+}
+
+// register a new listener channel, must be called with listenerMtx held
+func (m *Monitor) register(kind string, ch interface{}) *listenerStats {
+	if m.listenerIndex == nil {
+		m.listenerIndex = make(map[interface{}]*listenerStats)
+	}
+	ls := &listenerStats{kind: kind, ch: reflect.ValueOf(ch)}
+	m.listenerIndex[ch] = ls
+	m.listenerStats = append(m.listenerStats, ls)
+	return ls
+}
+
+// unregister removes a listener channel, must be called with listenerMtx held
+func (m *Monitor) unregister(ch interface{}) {
+	ls, ok := m.listenerIndex[ch]
+	if !ok {
+		return
+	}
+	delete(m.listenerIndex, ch)
+	for i, l := range m.listenerStats {
+		if l == ls {
+			m.listenerStats = append(m.listenerStats[:i], m.listenerStats[i+1:]...)
+			break
+		}
+	}
+}
+
+// delivered must be called with listenerMtx held after an event was sent to the channel
+func (m *Monitor) delivered(ch interface{}) {
+	if ls, ok := m.listenerIndex[ch]; ok {
+		ls.slow = false
+	}
+}
+
+// dropped must be called with listenerMtx held after the channel's buffer was full.
+// The first drop after a delivery is reported to error listeners.
+func (m *Monitor) dropped(ch interface{}) {
+	ls, ok := m.listenerIndex[ch]
+	if !ok {
+		return
+	}
+	ls.dropped++
+	if ls.slow || ls.kind == "error" {
+		ls.slow = true
+		return
+	}
+	ls.slow = true
+
+	err := &SlowConsumerError{Name: ls.name, Type: ls.kind, Dropped: ls.dropped}
+	for _, l := range m.errorListeners {
+		select {
+		case l <- err:
+			m.delivered(l)
+		default:
+			m.dropped(l)
+		}
+	}
+}
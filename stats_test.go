@@ -0,0 +1,82 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonitor_Stats(t *testing.T) {
+	s := newTestServer("localhost:9856", 10, 0, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitorWithConfig("http://localhost:9856/v2", Config{Interval: time.Millisecond * 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	errs := m.NewErrorListener()
+	m.NewRawListener() // never read
+	raw := m.NewRawListener()
+
+	for i := 0; i < 10; i++ {
+		select {
+		case <-raw:
+		case <-time.After(time.Second):
+			t.Fatal("poll not received")
+		}
+	}
+
+	stats := m.Stats()
+	n := len(stats.Listeners) // includes the monitor's internal listeners
+	if n < 3 {
+		t.Fatalf("unexpected listeners %+v", stats.Listeners)
+	}
+	slow := stats.Listeners[n-2]
+	if slow.Type != "raw" || slow.Dropped < 4 || stats.Dropped != slow.Dropped {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if l := stats.Latency; l.Count < 10 || l.P50 <= 0 || l.P50 > l.P95 || l.P95 > l.P99 || l.P99 > l.Max {
+		t.Errorf("unexpected latency stats %+v", l)
+	}
+
+	var warnings int
+	for len(errs) > 0 {
+		err := <-errs
+		if sc, ok := err.(*SlowConsumerError); ok {
+			warnings++
+			if sc.Type != "raw" || sc.Dropped != 1 {
+				t.Errorf("unexpected warning %v", sc)
+			}
+		}
+	}
+	if warnings != 1 {
+		t.Errorf("got %d slow consumer warnings, want 1", warnings)
+	}
+}
+
+func TestMonitor_ListenerStats(t *testing.T) {
+	s := newTestServer("localhost:9855", 10, 0, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitor("http://localhost:9855/v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	m.NewMinuteListenerNamed("alerter")
+	m.NewHeightListener()
+
+	list := m.Listeners()
+	n := len(list)
+	if n < 2 {
+		t.Fatalf("unexpected listeners %+v", list)
+	}
+	if l := list[n-2]; l.Name != "alerter" || l.Type != "minute" || l.Capacity != 25 || l.Buffered != 0 {
+		t.Errorf("unexpected named listener %+v", l)
+	}
+	if l := list[n-1]; l.Name != "" || l.Type != "height" || l.Capacity != 6 {
+		t.Errorf("unexpected unnamed listener %+v", l)
+	}
+}
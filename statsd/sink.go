@@ -0,0 +1,189 @@
+// Package statsd emits monitor counters and timings over StatsD UDP, for use with
+// Graphite, the Datadog agent, or any other StatsD server.
+package statsd
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+)
+
+// Source is the subset of a monitor used by the sink
+type Source interface {
+	NewRawListener() <-chan *monitor.MinuteResponse
+	NewMinuteListener() <-chan monitor.Event
+	NewMinuteTimingListener() <-chan monitor.MinuteTiming
+	NewErrorListener() <-chan error
+	LatencyHistogram() monitor.LatencyHistogram
+	RevealLatency() monitor.LatencyHistogram
+	ThroughputStats() monitor.ThroughputStats
+}
+
+// Config contains the settings of a sink
+type Config struct {
+	// Prefix is prepended to every metric name, ie "factom." for "factom.polls"
+	Prefix string
+	// Tags are appended to every metric in the DogStatsD format. Leave empty for plain StatsD.
+	Tags []string
+}
+
+// DefaultConfig uses the "factom." prefix without tags
+var DefaultConfig = Config{Prefix: "factom."}
+
+// Sink sends the following metrics to a StatsD server:
+//
+//	polls        counter, successful polls
+//	errors       counter, errors reported by the monitor
+//	minutes      counter, new minutes
+//	blocks       counter, new heights
+//	height       gauge, the current height
+//	minute_time  timing, the duration of every minute observed in full
+//	block_time   timing, the duration of every block observed in full
+//	latency_p50  gauge, the median latency of recent requests in milliseconds, sent every minute
+//	latency_p95  gauge, the 95th percentile, sent every minute
+//	latency_p99  gauge, the 99th percentile, sent every minute
+//	reveal_latency_p50  gauge, the median commit to reveal latency of tracked entries in
+//	                    milliseconds, sent every minute once there is one
+//	reveal_latency_p95  gauge, the 95th percentile, sent every minute once there is one
+//	entries_per_block   gauge, the mean entries of recent directory blocks, sent every
+//	                    minute once a block was counted
+//	chains_per_block    gauge, the mean chains of recent directory blocks, likewise
+//	entries_per_second  gauge, the entry throughput of recent directory blocks, likewise
+//
+// Metrics are sent as they happen. StatsD is fire-and-forget, so failed sends are
+// only reported on Errors().
+type Sink struct {
+	src  Source
+	conn net.Conn
+	conf Config
+	tags string
+
+	errors chan error
+
+	close  chan interface{}
+	done   chan interface{}
+	closer sync.Once
+}
+
+// NewSink connects to the StatsD server at the UDP address, ie "localhost:8125", and
+// begins sending metrics from the source immediately.
+// Starts a goroutine that can be stopped via sink.Stop().
+func NewSink(addr string, src Source, conf Config) (*Sink, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := new(Sink)
+	s.conn = conn
+	s.conf = conf
+	if len(conf.Tags) > 0 {
+		s.tags = "|#" + strings.Join(conf.Tags, ",")
+	}
+	s.errors = make(chan error, 6)
+	s.close = make(chan interface{})
+	s.done = make(chan interface{})
+	s.src = src
+	go s.run(src.NewRawListener(), src.NewMinuteListener(), src.NewMinuteTimingListener(), src.NewErrorListener())
+	return s, nil
+}
+
+// Errors returns a channel that receives errors from failed sends.
+// Errors are dropped if the channel is not read.
+func (s *Sink) Errors() <-chan error {
+	return s.errors
+}
+
+func (s *Sink) run(polls <-chan *monitor.MinuteResponse, minutes <-chan monitor.Event, timings <-chan monitor.MinuteTiming, errs <-chan error) {
+	defer close(s.done)
+
+	height := int64(-1)
+	var prev monitor.Event
+	for {
+		select {
+		case <-s.close:
+			return
+		case _, ok := <-polls:
+			if !ok { // drained
+				polls = nil
+				continue
+			}
+			s.send("polls", "1|c")
+		case _, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			s.send("errors", "1|c")
+		case e, ok := <-minutes:
+			if !ok {
+				minutes = nil
+				continue
+			}
+			s.send("minutes", "1|c")
+			if e.Height != height {
+				if height >= 0 {
+					s.send("blocks", "1|c")
+				}
+				height = e.Height
+				s.send("height", fmt.Sprintf("%d|g", e.Height))
+			}
+			if e.Minute == 0 && prev.Minute == 0 && e.Height == prev.Height+1 && !prev.BlockStart.IsZero() && !e.BlockStart.IsZero() {
+				s.send("block_time", millis(e.BlockStart.Sub(prev.BlockStart)))
+			}
+			if e.Minute == 0 {
+				prev = e
+			}
+			lat := s.src.LatencyHistogram()
+			s.send("latency_p50", fmt.Sprintf("%d|g", lat.P50.Milliseconds()))
+			s.send("latency_p95", fmt.Sprintf("%d|g", lat.P95.Milliseconds()))
+			s.send("latency_p99", fmt.Sprintf("%d|g", lat.P99.Milliseconds()))
+			if rev := s.src.RevealLatency(); rev.Count > 0 {
+				s.send("reveal_latency_p50", fmt.Sprintf("%d|g", rev.P50.Milliseconds()))
+				s.send("reveal_latency_p95", fmt.Sprintf("%d|g", rev.P95.Milliseconds()))
+			}
+			if tp := s.src.ThroughputStats(); tp.Blocks > 0 {
+				s.send("entries_per_block", gauge(tp.EntriesPerBlock))
+				s.send("chains_per_block", gauge(tp.ChainsPerBlock))
+				s.send("entries_per_second", gauge(tp.EntriesPerSecond))
+			}
+		case t, ok := <-timings:
+			if !ok {
+				timings = nil
+				continue
+			}
+			s.send("minute_time", millis(t.Duration))
+		}
+	}
+}
+
+func millis(d time.Duration) string {
+	return fmt.Sprintf("%d|ms", d.Milliseconds())
+}
+
+func gauge(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64) + "|g"
+}
+
+// send writes a single metric as one packet
+func (s *Sink) send(name, value string) {
+	if _, err := fmt.Fprintf(s.conn, "%s%s:%s%s", s.conf.Prefix, name, value, s.tags); err != nil {
+		select {
+		case s.errors <- err:
+		default:
+		}
+	}
+}
+
+// Stop halts the sink and closes the connection
+func (s *Sink) Stop() {
+	s.closer.Do(func() {
+		close(s.close)
+		<-s.done
+		s.conn.Close()
+	})
+}
@@ -0,0 +1,78 @@
+package statsd
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+	"github.com/WhoSoup/factom-monitor/monitortest"
+)
+
+func TestSink(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	fake := monitortest.NewFakeMonitor(10, 0)
+	fake.SetLatencyHistogram(monitor.LatencyHistogram{Count: 3, P50: time.Millisecond * 20, P95: time.Millisecond * 80, P99: time.Millisecond * 150})
+	fake.SetRevealLatency(monitor.LatencyHistogram{Count: 2, P50: time.Second * 4, P95: time.Second * 9})
+	s, err := NewSink(server.LocalAddr().String(), fake, Config{Prefix: "fct.", Tags: []string{"net:main"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	// the listeners are independent, so read the metrics of each one before
+	// sending the next
+	buf := make([]byte, 512)
+	expect := func(want ...string) {
+		for _, w := range want {
+			server.SetReadDeadline(time.Now().Add(time.Second))
+			n, _, err := server.ReadFrom(buf)
+			if err != nil {
+				t.Fatalf("%s not received: %v", w, err)
+			}
+			if got := string(buf[:n]); got != w {
+				t.Errorf("unexpected metric. got = %s, want = %s", got, w)
+			}
+		}
+	}
+
+	start := time.Unix(1600000000, 0)
+	fake.SendRaw(new(monitor.MinuteResponse))
+	expect("fct.polls:1|c|#net:main")
+	fake.SendError(errors.New("timeout"))
+	expect("fct.errors:1|c|#net:main")
+	fake.SendEvent(monitor.Event{Height: 10, BlockStart: start})
+	expect(
+		"fct.minutes:1|c|#net:main",
+		"fct.height:10|g|#net:main",
+		"fct.latency_p50:20|g|#net:main",
+		"fct.latency_p95:80|g|#net:main",
+		"fct.latency_p99:150|g|#net:main",
+		"fct.reveal_latency_p50:4000|g|#net:main",
+		"fct.reveal_latency_p95:9000|g|#net:main",
+	)
+	fake.SetThroughputStats(monitor.ThroughputStats{Blocks: 2, EntriesPerBlock: 4, ChainsPerBlock: 1.5, EntriesPerSecond: 0.4})
+	fake.SendEvent(monitor.Event{Height: 11, BlockStart: start.Add(time.Second * 605)})
+	expect(
+		"fct.minutes:1|c|#net:main",
+		"fct.blocks:1|c|#net:main",
+		"fct.height:11|g|#net:main",
+		"fct.block_time:605000|ms|#net:main",
+		"fct.latency_p50:20|g|#net:main",
+		"fct.latency_p95:80|g|#net:main",
+		"fct.latency_p99:150|g|#net:main",
+		"fct.reveal_latency_p50:4000|g|#net:main",
+		"fct.reveal_latency_p95:9000|g|#net:main",
+		"fct.entries_per_block:4|g|#net:main",
+		"fct.chains_per_block:1.5|g|#net:main",
+		"fct.entries_per_second:0.4|g|#net:main",
+	)
+	fake.SendMinuteTiming(monitor.MinuteTiming{Height: 11, Duration: time.Second * 61})
+	expect("fct.minute_time:61000|ms|#net:main")
+}
@@ -0,0 +1,89 @@
+package monitor
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cursor is the most recent state delivered to listeners
+type Cursor struct {
+	Height   int64     `json:"height"`
+	DBHeight int64     `json:"dbheight"`
+	Minute   int64     `json:"minute"`
+	Time     time.Time `json:"time"`
+}
+
+// Store persists the cursor across restarts.
+type Store interface {
+	// Load returns the saved cursor. If nothing has been saved yet, it returns nil and no error.
+	Load() (*Cursor, error)
+	// Save replaces the saved cursor
+	Save(c Cursor) error
+}
+
+// FileStore is a Store that keeps the cursor in a JSON file.
+type FileStore struct {
+	path string
+}
+
+var _ Store = (*FileStore)(nil)
+
+// NewFileStore creates a store that saves the cursor to the specified path.
+// The file is created on the first save.
+func NewFileStore(path string) *FileStore {
+	fs := new(FileStore)
+	fs.path = path
+	return fs
+}
+
+// Load reads the cursor from the file
+func (fs *FileStore) Load() (*Cursor, error) {
+	data, err := ioutil.ReadFile(fs.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := new(Cursor)
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Save writes the cursor to a temporary file and replaces the old file,
+// so an interrupted write does not corrupt the saved cursor.
+func (fs *FileStore) Save(c Cursor) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return writeFile(fs.path, data)
+}
+
+// writeFile writes the data to a temporary file and replaces the file at path with it
+func writeFile(path string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
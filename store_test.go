@@ -0,0 +1,78 @@
+package monitor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func tempStore(t *testing.T) (*FileStore, func()) {
+	dir, err := ioutil.TempDir("", "factom-monitor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewFileStore(filepath.Join(dir, "cursor.json")), func() { os.RemoveAll(dir) }
+}
+
+func TestFileStore(t *testing.T) {
+	fs, cleanup := tempStore(t)
+	defer cleanup()
+
+	c, err := fs.Load()
+	if err != nil || c != nil {
+		t.Fatalf("unexpected result for empty store. got = (%v, %v), want = (nil, nil)", c, err)
+	}
+
+	want := Cursor{Height: 5, DBHeight: 4, Minute: 0, Time: time.Now().Round(0)}
+	if err := fs.Save(want); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err = fs.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Height != want.Height || c.DBHeight != want.DBHeight || c.Minute != want.Minute || !c.Time.Equal(want.Time) {
+		t.Errorf("unexpected cursor. got = %+v, want = %+v", c, want)
+	}
+}
+
+func TestMonitor_Store(t *testing.T) {
+	fs, cleanup := tempStore(t)
+	defer cleanup()
+
+	if err := fs.Save(Cursor{Height: 7, DBHeight: 7, Minute: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := newTestServer("localhost:9885", 10, 5, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitorWithConfig("http://localhost:9885/v2", Config{Interval: time.Millisecond * 100, Store: fs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	if missed, ok := m.Missed(); !ok || missed != 3 {
+		t.Errorf("unexpected missed blocks. got = (%d, %v), want = (3, true)", missed, ok)
+	}
+
+	listener := m.NewMinuteListener()
+	s.tick()
+	select {
+	case <-listener:
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+	}
+
+	c, err := fs.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Height != 10 || c.Minute != 6 {
+		t.Errorf("cursor not saved. got = %d/%d, want = 10/6", c.Height, c.Minute)
+	}
+}
@@ -0,0 +1,70 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrSubscriptionClosed is returned by Next after the subscription was closed
+var ErrSubscriptionClosed = errors.New("subscription closed")
+
+// Subscription is a pull-based alternative to minute listeners. Events are buffered
+// until the caller asks for the next one, so the caller decides when to accept more work.
+// Like other listeners, events are dropped while the buffer is full.
+type Subscription struct {
+	m      *Monitor
+	ch     <-chan Event
+	done   chan interface{}
+	closer sync.Once
+}
+
+// Subscribe creates a subscription to minute events, listed under the name in Listeners().
+func (m *Monitor) Subscribe(name string) *Subscription {
+	s := new(Subscription)
+	s.m = m
+	s.ch = m.NewMinuteListenerNamed(name)
+	s.done = make(chan interface{})
+	return s
+}
+
+// Next blocks until the next event is available, the context is done, or the
+// subscription is closed. Returns ErrSubscriptionClosed after Close, and ErrDrained once
+// the monitor was drained and all buffered events have been returned.
+func (s *Subscription) Next(ctx context.Context) (Event, error) {
+	select {
+	case <-s.done:
+		return Event{}, ErrSubscriptionClosed
+	default:
+	}
+
+	select {
+	case <-s.done:
+		return Event{}, ErrSubscriptionClosed
+	case e, ok := <-s.ch:
+		if !ok {
+			return Event{}, ErrDrained
+		}
+		return e, nil
+	case <-ctx.Done():
+		return Event{}, ctx.Err()
+	}
+}
+
+// Close stops the delivery of events to the subscription and discards buffered events.
+// Calls to Next that are waiting for an event return ErrSubscriptionClosed.
+func (s *Subscription) Close() {
+	s.closer.Do(func() {
+		close(s.done)
+
+		s.m.listenerMtx.Lock()
+		defer s.m.listenerMtx.Unlock()
+		for i, l := range s.m.minuteListeners {
+			if l == s.ch {
+				s.m.minuteListeners = append(s.m.minuteListeners[:i], s.m.minuteListeners[i+1:]...)
+				s.m.unregister(l)
+				break
+			}
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMonitor_Subscribe(t *testing.T) {
+	s := newTestServer("localhost:9841", 10, 1, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitorWithConfig("http://localhost:9841/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	sub := m.Subscribe("pull")
+	if l := m.Listeners(); l[len(l)-1].Name != "pull" {
+		t.Errorf("subscription not listed: %+v", l)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	if _, err := sub.Next(ctx); err != context.DeadlineExceeded {
+		t.Errorf("unexpected error without events. got = %v, want = %v", err, context.DeadlineExceeded)
+	}
+
+	s.tick()
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+	e, err := sub.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Height != 10 || e.Minute != 2 {
+		t.Errorf("unexpected event %+v", e)
+	}
+
+	// a waiting Next returns once the subscription is closed
+	errs := make(chan error)
+	go func() {
+		_, err := sub.Next(context.Background())
+		errs <- err
+	}()
+	time.Sleep(time.Millisecond * 50)
+
+	n := len(m.Listeners())
+	sub.Close()
+	select {
+	case err := <-errs:
+		if err != ErrSubscriptionClosed {
+			t.Errorf("unexpected error of waiting Next. got = %v, want = %v", err, ErrSubscriptionClosed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiting Next not released by Close")
+	}
+	if _, err := sub.Next(ctx); err != ErrSubscriptionClosed {
+		t.Errorf("unexpected error after close. got = %v, want = %v", err, ErrSubscriptionClosed)
+	}
+	if l := m.Listeners(); len(l) != n-1 {
+		t.Errorf("subscription still listed: %+v", l)
+	}
+}
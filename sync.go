@@ -0,0 +1,152 @@
+package monitor
+
+import (
+	"context"
+	"time"
+)
+
+// SyncInterval specifies the time between sync progress checks
+var SyncInterval time.Duration = time.Second * 10
+
+// SyncThreshold is the number of blocks the node's dbheight has to be behind the
+// network before the node is considered to be syncing
+var SyncThreshold int64 = 10
+
+// SyncProgress is sent to sync listeners every SyncInterval while the node is catching
+// up with the network, and once more when it is done.
+type SyncProgress struct {
+	// The most recent block saved in the node's database
+	DBHeight int64 `json:"dbheight"`
+	// The height of the network the node is catching up to
+	Target int64 `json:"target"`
+	// The number of blocks left to sync
+	Remaining int64 `json:"remaining"`
+	// The number of blocks per second the node caught up on since it started syncing.
+	// Zero until the node made progress.
+	Rate float64 `json:"rate"`
+	// The estimated time until the node is synced, zero if there is no rate yet
+	ETA time.Duration `json:"eta"`
+	// True for the final event, once the node is within SyncThreshold of the network
+	Synced bool `json:"synced"`
+	// When the progress was checked
+	Time time.Time `json:"time"`
+}
+
+// syncState tracks the progress of a sync since it was first detected
+type syncState struct {
+	reference Source
+	syncing   bool
+	since     time.Time
+	from      int64 // blocks remaining when the sync was detected
+}
+
+// WatchSync starts checking every SyncInterval whether the node's dbheight is more
+// than SyncThreshold blocks behind the network. The network's height is the dbheight
+// of the reference monitor, or, if reference is nil, the leader height reported by
+// the node's "heights" API.
+// While the node is syncing, sync listeners receive its progress and height and dbheight
+// listeners don't receive the heights the node rushes through. They resume after the node
+// is synced.
+// Calling it more than once has no effect.
+func (m *Monitor) WatchSync(reference Source) {
+	m.watchMtx.Lock()
+	defer m.watchMtx.Unlock()
+	if m.sync == nil {
+		m.sync = &syncState{reference: reference}
+		interval := m.conf.SyncInterval
+		if interval <= 0 {
+			interval = SyncInterval
+		}
+		m.every(interval, m.checkSync)
+	}
+}
+
+// Syncing returns true if WatchSync detected that the node is catching up with the network
+func (m *Monitor) Syncing() bool {
+	m.heightMtx.Lock()
+	defer m.heightMtx.Unlock()
+	return m.syncing
+}
+
+// NewSyncListener spawns a new listener that receives the progress of the node while it
+// is syncing. See WatchSync.
+// Each reader must have its own listener.
+func (m *Monitor) NewSyncListener() <-chan SyncProgress {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan SyncProgress, 6)
+	m.syncListeners = append(m.syncListeners, l)
+	m.register("sync", l)
+	return l
+}
+
+// syncHeights returns the dbheight of the node and the height it is syncing to
+func (m *Monitor) syncHeights(reference Source) (int64, int64, error) {
+	if reference != nil {
+		_, target, _ := reference.GetCurrentMinute()
+		return m.State().DBHeight, target, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout())
+	defer cancel()
+	heights, err := m.HeightsRequest(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	return heights.DirectoryBlockHeight, heights.LeaderHeight, nil
+}
+
+func (m *Monitor) checkSync() {
+	m.watchMtx.Lock()
+	reference := m.sync.reference
+	m.watchMtx.Unlock()
+
+	dbheight, target, err := m.syncHeights(reference)
+	if err != nil {
+		m.notifyError(err)
+		return
+	}
+
+	now := m.clock().Now()
+	p := SyncProgress{DBHeight: dbheight, Target: target, Remaining: target - dbheight, Time: now}
+	if p.Remaining < 0 {
+		p.Remaining = 0
+	}
+	syncing := p.Remaining > SyncThreshold
+
+	m.watchMtx.Lock()
+	s := m.sync
+	if !syncing && !s.syncing {
+		m.watchMtx.Unlock()
+		return
+	}
+	if syncing && !s.syncing {
+		s.since = now
+		s.from = p.Remaining
+	}
+	if elapsed := now.Sub(s.since).Seconds(); elapsed > 0 && p.Remaining < s.from {
+		p.Rate = float64(s.from-p.Remaining) / elapsed
+		p.ETA = time.Duration(float64(p.Remaining) / p.Rate * float64(time.Second))
+	}
+	s.syncing = syncing
+	p.Synced = !syncing
+	if p.Synced {
+		p.ETA = 0
+	}
+	m.watchMtx.Unlock()
+
+	m.heightMtx.Lock()
+	m.syncing = syncing
+	m.heightMtx.Unlock()
+
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	for _, l := range m.syncListeners {
+		select {
+		case l <- p:
+			m.delivered(l)
+		default:
+			m.dropped(l)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package monitor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMonitor_WatchSync(t *testing.T) {
+	s := newTestServer("localhost:9837", 10, 0, time.Second*10, t)
+	defer s.stop()
+	dbheight := int64(10)
+	s.handle("heights", func(json.RawMessage) interface{} {
+		return HeightsResponse{DirectoryBlockHeight: dbheight, LeaderHeight: 100}
+	})
+
+	m, err := NewMonitorWithConfig("http://localhost:9837/v2", Config{SyncInterval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	listener := m.NewSyncListener()
+	m.WatchSync(nil)
+
+	next := func() SyncProgress {
+		select {
+		case p := <-listener:
+			return p
+		case <-time.After(time.Second * 2):
+			t.Fatal("sync progress not received")
+		}
+		return SyncProgress{}
+	}
+
+	if p := next(); p.Synced || p.Remaining != 90 || p.Target != 100 || p.ETA != 0 {
+		t.Errorf("unexpected initial progress %+v", p)
+	}
+	if !m.Syncing() {
+		t.Error("monitor not syncing")
+	}
+
+	s.mtx.Lock()
+	dbheight = 50
+	s.mtx.Unlock()
+	var p SyncProgress
+	for p = next(); p.DBHeight != 50; p = next() {
+	}
+	if p.Synced || p.Remaining != 50 || p.Rate <= 0 || p.ETA <= 0 {
+		t.Errorf("unexpected progress %+v", p)
+	}
+
+	s.mtx.Lock()
+	dbheight = 95
+	s.mtx.Unlock()
+	for p = next(); p.DBHeight != 95; p = next() {
+	}
+	if !p.Synced || p.Remaining != 5 {
+		t.Errorf("unexpected final progress %+v", p)
+	}
+	if m.Syncing() {
+		t.Error("monitor still syncing")
+	}
+
+	time.Sleep(time.Millisecond * 300)
+	select {
+	case p := <-listener:
+		t.Errorf("unexpected progress after sync %+v", p)
+	default:
+	}
+}
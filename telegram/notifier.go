@@ -0,0 +1,293 @@
+// Package telegram sends monitor alerts to a Telegram chat via a bot, and can answer
+// commands asking for the current height.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+	"github.com/WhoSoup/factom-monitor/notify"
+)
+
+// DefaultAPIURL is the Telegram Bot API
+const DefaultAPIURL = "https://api.telegram.org"
+
+// Source is the subset of a monitor used by the notifier
+type Source interface {
+	NewAlertListener() <-chan monitor.Alert
+	State() monitor.State
+}
+
+// Config contains the settings of a notifier
+type Config struct {
+	// The token of the bot, as issued by @BotFather
+	Token string
+	// The chat to send alerts to, either a numeric id or "@channelname"
+	ChatID string
+	// Commands enables replies to /height and /status sent in the chat.
+	// Messages from other chats are ignored.
+	Commands bool
+	// Templates replace the DefaultTemplates of firing and resolved alerts
+	Templates map[string]string
+	// How long a single request for new messages waits, defaults to 30 seconds
+	PollTimeout time.Duration
+	// The url of the Bot API, defaults to DefaultAPIURL
+	APIURL string
+	// Leader, if set, limits notifications and commands to the times it is the leader, so
+	// only one of several monitor instances sends them. Followers don't poll for commands,
+	// which leaves them to the leader. See monitor.Elector.
+	Leader monitor.Leader
+	// The client used for requests, defaults to http.DefaultClient
+	Client *http.Client
+}
+
+// DefaultTemplates are the messages of firing and resolved alerts.
+// See package notify for the available fields and functions.
+var DefaultTemplates = map[string]string{
+	notify.KindFiring:   "🚨 {{.Alert.Rule}} ({{.Alert.Severity}}): {{.Alert.Message}}\nHeight {{.Height}}, minute {{.Minute}}",
+	notify.KindResolved: "✅ Resolved: {{.Alert.Rule}} after {{duration .Alert.Duration}}\nHeight {{.Height}}, minute {{.Minute}}",
+}
+
+// Timeout specifies the maximum time a single request can take, in addition to
+// Config.PollTimeout when waiting for messages
+var Timeout = time.Second * 10
+
+// Notifier sends alerts to a Telegram chat
+type Notifier struct {
+	src       Source
+	conf      Config
+	templates notify.Templates
+
+	errors chan error
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	closer sync.Once
+}
+
+// NewNotifier creates a new notifier that begins sending alerts from the source immediately.
+// Returns an error if a template can't be parsed.
+// Starts goroutines that can be stopped via notifier.Stop().
+func NewNotifier(src Source, conf Config) (*Notifier, error) {
+	if conf.PollTimeout <= 0 {
+		conf.PollTimeout = time.Second * 30
+	}
+	if conf.APIURL == "" {
+		conf.APIURL = DefaultAPIURL
+	}
+	if conf.Client == nil {
+		conf.Client = http.DefaultClient
+	}
+	n := new(Notifier)
+	n.src = src
+	n.conf = conf
+	var err error
+	if n.templates, err = notify.ParseTemplates(DefaultTemplates, conf.Templates); err != nil {
+		return nil, fmt.Errorf("telegram: %v", err)
+	}
+	n.errors = make(chan error, 6)
+	n.ctx, n.cancel = context.WithCancel(context.Background())
+
+	n.wg.Add(1)
+	go n.run(src.NewAlertListener())
+	if conf.Commands {
+		n.wg.Add(1)
+		go n.commands()
+	}
+	return n, nil
+}
+
+// Errors returns a channel that receives errors from failed requests.
+// Errors are dropped if the channel is not read.
+func (n *Notifier) Errors() <-chan error {
+	return n.errors
+}
+
+func (n *Notifier) run(alerts <-chan monitor.Alert) {
+	defer n.wg.Done()
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case a, ok := <-alerts:
+			if !ok { // drained
+				return
+			}
+			if n.follower() {
+				continue
+			}
+			text, err := n.templates.Render(notify.AlertData(a))
+			if err != nil {
+				n.notifyError(err)
+				continue
+			}
+			n.notifyError(n.Send(n.conf.ChatID, text))
+		}
+	}
+}
+
+// follower returns true while the notifier isn't the leader
+func (n *Notifier) follower() bool {
+	return n.conf.Leader != nil && !n.conf.Leader.IsLeader()
+}
+
+// statusText formats the monitor's current state as a message
+func (n *Notifier) statusText() string {
+	s := n.src.State()
+	text := fmt.Sprintf("Height %d, minute %d\nDBHeight %d", s.Height, s.Minute, s.DBHeight)
+	if !s.Healthy {
+		text += fmt.Sprintf("\nUnhealthy after %d failed polls: %v", s.Failures, s.LastError)
+	}
+	return text
+}
+
+// commands answers commands until the notifier is stopped
+func (n *Notifier) commands() {
+	defer n.wg.Done()
+	var offset int64
+	for {
+		if n.follower() {
+			select {
+			case <-n.ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		updates, err := n.updates(offset)
+		if n.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			n.notifyError(err)
+			select {
+			case <-n.ctx.Done():
+				return
+			case <-time.After(time.Second * 5):
+			}
+			continue
+		}
+
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			if u.Message == nil || !n.fromChat(u.Message.Chat) || n.follower() {
+				continue
+			}
+			// commands can be addressed to a bot, ie /height@factombot
+			switch cmd := strings.SplitN(strings.Fields(u.Message.Text + " ")[0], "@", 2)[0]; cmd {
+			case "/height", "/status":
+				n.notifyError(n.Send(strconv.FormatInt(u.Message.Chat.ID, 10), n.statusText()))
+			}
+		}
+	}
+}
+
+// fromChat returns true if the chat is the configured chat
+func (n *Notifier) fromChat(c chat) bool {
+	if strconv.FormatInt(c.ID, 10) == n.conf.ChatID {
+		return true
+	}
+	return c.Username != "" && "@"+c.Username == n.conf.ChatID
+}
+
+type chat struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+type update struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		Chat chat   `json:"chat"`
+		Text string `json:"text"`
+	} `json:"message"`
+}
+
+// updates waits for new messages to the bot
+func (n *Notifier) updates(offset int64) ([]update, error) {
+	params := url.Values{}
+	params.Set("offset", strconv.FormatInt(offset, 10))
+	params.Set("timeout", strconv.Itoa(int(n.conf.PollTimeout.Seconds())))
+	params.Set("allowed_updates", `["message"]`)
+
+	var updates []update
+	err := n.call("getUpdates", params, n.conf.PollTimeout+Timeout, &updates)
+	return updates, err
+}
+
+// Send sends a text message to the chat
+func (n *Notifier) Send(chatID, text string) error {
+	params := url.Values{}
+	params.Set("chat_id", chatID)
+	params.Set("text", text)
+	return n.call("sendMessage", params, Timeout, nil)
+}
+
+// call sends a Bot API request and decodes the result
+func (n *Notifier) call(method string, params url.Values, timeout time.Duration, result interface{}) error {
+	ctx, cancel := context.WithTimeout(n.ctx, timeout)
+	defer cancel()
+
+	u := fmt.Sprintf("%s/bot%s/%s", strings.TrimSuffix(n.conf.APIURL, "/"), n.conf.Token, method)
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewBufferString(params.Encode()))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := n.conf.Client.Do(req)
+	if err != nil {
+		// the url contains the token
+		if uerr, ok := err.(*url.Error); ok {
+			return fmt.Errorf("telegram: %s: %v", method, uerr.Err)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	var res struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return fmt.Errorf("telegram: %s: %s", method, resp.Status)
+	}
+	if !res.OK {
+		return fmt.Errorf("telegram: %s: %s", method, res.Description)
+	}
+	if result != nil {
+		return json.Unmarshal(res.Result, result)
+	}
+	return nil
+}
+
+func (n *Notifier) notifyError(err error) {
+	if err == nil {
+		return
+	}
+	select {
+	case n.errors <- err:
+	default:
+	}
+}
+
+// Stop halts the notifier
+func (n *Notifier) Stop() {
+	n.closer.Do(func() {
+		n.cancel()
+		n.wg.Wait()
+	})
+}
@@ -0,0 +1,148 @@
+package telegram
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+	"github.com/WhoSoup/factom-monitor/monitortest"
+)
+
+type sent struct {
+	chat, text string
+}
+
+type fakeBot struct {
+	*httptest.Server
+	mtx     sync.Mutex
+	sent    []sent
+	updates []string
+	polls   int
+}
+
+func newFakeBot() *fakeBot {
+	b := new(fakeBot)
+	b.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		b.mtx.Lock()
+		defer b.mtx.Unlock()
+		switch r.URL.Path {
+		case "/botsecret/sendMessage":
+			b.sent = append(b.sent, sent{r.Form.Get("chat_id"), r.Form.Get("text")})
+			fmt.Fprint(rw, `{"ok":true,"result":{}}`)
+		case "/botsecret/getUpdates":
+			b.polls++
+			if len(b.updates) == 0 {
+				b.mtx.Unlock()
+				time.Sleep(time.Millisecond * 10)
+				b.mtx.Lock()
+				fmt.Fprint(rw, `{"ok":true,"result":[]}`)
+				return
+			}
+			fmt.Fprintf(rw, `{"ok":true,"result":[%s]}`, b.updates[0])
+			b.updates = b.updates[1:]
+		default:
+			fmt.Fprint(rw, `{"ok":false,"description":"Unauthorized"}`)
+		}
+	}))
+	return b
+}
+
+func (b *fakeBot) wait(n int) []sent {
+	for i := 0; i < 100; i++ {
+		b.mtx.Lock()
+		s := b.sent
+		b.mtx.Unlock()
+		if len(s) >= n {
+			return s
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	return nil
+}
+
+func TestNotifier(t *testing.T) {
+	bot := newFakeBot()
+	defer bot.Close()
+	bot.updates = []string{
+		`{"update_id":1,"message":{"chat":{"id":42},"text":"/height@factombot"}}`,
+		`{"update_id":2,"message":{"chat":{"id":7},"text":"/height"}}`,
+		`{"update_id":3,"message":{"chat":{"id":42},"text":"hello"}}`,
+	}
+
+	fake := monitortest.NewFakeMonitor(10, 3)
+	n, err := NewNotifier(fake, Config{Token: "secret", ChatID: "42", Commands: true, APIURL: bot.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer n.Stop()
+
+	fake.SendAlert(monitor.Alert{Rule: "no-new-block", Severity: monitor.SeverityCritical, Status: monitor.AlertFiring, Message: "stalled", Height: 10, Minute: 3})
+
+	s := bot.wait(2)
+	if len(s) != 2 {
+		t.Fatalf("unexpected messages: %v", s)
+	}
+	want := map[sent]bool{
+		{"42", "🚨 no-new-block (critical): stalled\nHeight 10, minute 3"}: true,
+		{"42", "Height 10, minute 3\nDBHeight 10"}:                        true,
+	}
+	for _, m := range s {
+		if !want[m] {
+			t.Errorf("unexpected message %+v", m)
+		}
+	}
+
+	time.Sleep(time.Millisecond * 50)
+	if s := bot.wait(0); len(s) != 2 {
+		t.Errorf("answered commands from other chats: %v", s)
+	}
+
+	bad, err := NewNotifier(fake, Config{Token: "wrong", ChatID: "42", APIURL: bot.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bad.Stop()
+	if err := bad.Send("42", "hi"); err == nil || err.Error() != "telegram: sendMessage: Unauthorized" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestNotifier_Follower(t *testing.T) {
+	bot := newFakeBot()
+	defer bot.Close()
+	bot.updates = []string{`{"update_id":1,"message":{"chat":{"id":42},"text":"/status"}}`}
+
+	fake := monitortest.NewFakeMonitor(10, 3)
+	leader := monitortest.NewFakeLeader(false)
+	n, err := NewNotifier(fake, Config{Token: "secret", ChatID: "42", Commands: true, APIURL: bot.URL, Leader: leader})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer n.Stop()
+
+	fake.SendAlert(monitor.Alert{Rule: "no-new-block", Status: monitor.AlertFiring, Message: "stalled"})
+	time.Sleep(time.Millisecond * 100)
+	bot.mtx.Lock()
+	if len(bot.sent) != 0 || bot.polls != 0 {
+		t.Errorf("follower sent %v and polled %d times", bot.sent, bot.polls)
+	}
+	bot.mtx.Unlock()
+
+	// the leader answers the command the follower left alone
+	leader.SetLeader(true)
+	for i := 0; i < 300; i++ {
+		if s := bot.wait(0); len(s) > 0 {
+			if len(s) != 1 || s[0] != (sent{"42", "Height 10, minute 3\nDBHeight 10"}) {
+				t.Errorf("unexpected messages: %v", s)
+			}
+			return
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	t.Error("command not answered by the leader")
+}
@@ -0,0 +1,108 @@
+package monitor
+
+import (
+	"strings"
+	"sync"
+)
+
+// the admin, entry credit, and factoid chains of every directory block
+var systemChains = map[string]bool{
+	strings.Repeat("0", 63) + "a": true,
+	strings.Repeat("0", 63) + "c": true,
+	strings.Repeat("0", 63) + "f": true,
+}
+
+// ThroughputStats contains entry throughput statistics of recent directory blocks
+type ThroughputStats struct {
+	// The number of blocks the statistics are based on
+	Blocks int `json:"blocks"`
+	// Totals over all blocks
+	Entries int `json:"entries"`
+	Chains  int `json:"chains"`
+	// Averages per block
+	EntriesPerBlock float64 `json:"entriesperblock"`
+	ChainsPerBlock  float64 `json:"chainsperblock"`
+	// Entries per second based on the mean block time, or on the node's configured
+	// block time if no block was observed in full. Zero if neither is known.
+	EntriesPerSecond float64 `json:"entriespersecond"`
+}
+
+type blockCount struct {
+	entries, chains int
+}
+
+// throughput is a ring buffer of the entry and chain counts of recent blocks
+type throughput struct {
+	mtx    sync.Mutex
+	counts []blockCount
+	next   int
+	full   bool
+}
+
+func newThroughput(size int) *throughput {
+	t := new(throughput)
+	t.counts = make([]blockCount, size)
+	return t
+}
+
+func (t *throughput) add(c blockCount) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.counts[t.next] = c
+	t.next = (t.next + 1) % len(t.counts)
+	if t.next == 0 {
+		t.full = true
+	}
+}
+
+// ThroughputStats returns entry throughput statistics of the last Config.BlockTimeWindow
+// directory blocks. Blocks are only counted while WatchDirectoryBlocks is enabled.
+func (m *Monitor) ThroughputStats() ThroughputStats {
+	var s ThroughputStats
+	m.throughput.mtx.Lock()
+	n := m.throughput.next
+	if m.throughput.full {
+		n = len(m.throughput.counts)
+	}
+	for _, c := range m.throughput.counts[:n] {
+		s.Entries += c.entries
+		s.Chains += c.chains
+	}
+	m.throughput.mtx.Unlock()
+
+	s.Blocks = n
+	if n == 0 {
+		return s
+	}
+	s.EntriesPerBlock = float64(s.Entries) / float64(n)
+	s.ChainsPerBlock = float64(s.Chains) / float64(n)
+
+	blocktime := m.blockTimes.mean().Seconds()
+	if blocktime == 0 {
+		m.heightMtx.Lock()
+		blocktime = float64(m.blockSeconds)
+		m.heightMtx.Unlock()
+	}
+	if blocktime > 0 {
+		s.EntriesPerSecond = s.EntriesPerBlock / blocktime
+	}
+	return s
+}
+
+// countEntries fetches the entry blocks of the directory block and returns the number
+// of entries and non-system chains in it
+func (m *Monitor) countEntries(dblock *DirectoryBlockResponse) (blockCount, error) {
+	var c blockCount
+	for _, e := range dblock.DBlock.DBEntries {
+		if systemChains[e.ChainID] {
+			continue
+		}
+		eblock, err := m.EntryBlockRequest(e.KeyMR)
+		if err != nil {
+			return c, err
+		}
+		c.chains++
+		c.entries += len(eblock.EntryList)
+	}
+	return c, nil
+}
@@ -0,0 +1,77 @@
+package monitor
+
+import "time"
+
+// MinuteTiming is sent to timing listeners at the end of every observed minute and
+// compares the minute's duration to the duration the node is configured for.
+type MinuteTiming struct {
+	// The height and minute that ended
+	Height int64 `json:"height"`
+	Minute int64 `json:"minute"`
+	// The duration of the minute, based on the node's minute start times if available,
+	// or on the times the monitor observed the minutes
+	Duration time.Duration `json:"duration"`
+	// DBlockSeconds / 10, zero if the node did not report a block time
+	Expected time.Duration `json:"expected"`
+}
+
+// Ratio returns the duration as a multiple of the expected duration, ie 1.5 for a minute
+// that took 50% longer than expected. Zero if there is no expected duration.
+func (t MinuteTiming) Ratio() float64 {
+	if t.Expected <= 0 {
+		return 0
+	}
+	return float64(t.Duration) / float64(t.Expected)
+}
+
+// NewMinuteTimingListener spawns a new listener that receives the timing of every minute.
+// Minutes are only timed if the monitor observed both the minute and the one after it.
+// Each reader must have its own listener.
+func (m *Monitor) NewMinuteTimingListener() <-chan MinuteTiming {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan MinuteTiming, 25)
+	m.timingListeners = append(m.timingListeners, l)
+	m.register("timing", l)
+	return l
+}
+
+// timeMinute compares the event to the previous one and notifies timing listeners
+// if the previous minute was observed in full
+func (m *Monitor) timeMinute(e Event, observed time.Time) {
+	m.heightMtx.Lock()
+	prev, prevObserved := m.prevEvent, m.prevObserved
+	m.prevEvent, m.prevObserved = e, observed
+	expected := m.minuteDuration()
+	m.heightMtx.Unlock()
+
+	if prevObserved.IsZero() || !consecutive(prev, e) {
+		return
+	}
+
+	t := MinuteTiming{Height: prev.Height, Minute: prev.Minute, Expected: expected}
+	if !prev.MinuteStart.IsZero() && !e.MinuteStart.IsZero() {
+		t.Duration = e.MinuteStart.Sub(prev.MinuteStart)
+	} else {
+		t.Duration = observed.Sub(prevObserved)
+	}
+
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	for _, l := range m.timingListeners {
+		select {
+		case l <- t:
+			m.delivered(l)
+		default:
+			m.dropped(l)
+		}
+	}
+}
+
+// consecutive checks if b is the minute directly after a
+func consecutive(a, b Event) bool {
+	if a.Minute == 9 {
+		return b.Height == a.Height+1 && b.Minute == 0
+	}
+	return b.Height == a.Height && b.Minute == a.Minute+1
+}
@@ -0,0 +1,58 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConsecutive(t *testing.T) {
+	tests := []struct {
+		a, b Event
+		want bool
+	}{
+		{Event{Height: 5, Minute: 3}, Event{Height: 5, Minute: 4}, true},
+		{Event{Height: 5, Minute: 9}, Event{Height: 6, Minute: 0}, true},
+		{Event{Height: 5, Minute: 3}, Event{Height: 5, Minute: 5}, false},
+		{Event{Height: 5, Minute: 9}, Event{Height: 6, Minute: 1}, false},
+		{Event{Height: 5, Minute: 3}, Event{Height: 6, Minute: 4}, false},
+	}
+	for _, tt := range tests {
+		if got := consecutive(tt.a, tt.b); got != tt.want {
+			t.Errorf("consecutive(%d/%d, %d/%d) = %v, want %v", tt.a.Height, tt.a.Minute, tt.b.Height, tt.b.Minute, got, tt.want)
+		}
+	}
+}
+
+func TestMonitor_MinuteTiming(t *testing.T) {
+	s := newTestServer("localhost:9862", 10, 5, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitorWithConfig("http://localhost:9862/v2", Config{Interval: time.Millisecond * 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	listener := m.NewMinuteTimingListener()
+	minutes := m.NewMinuteListener()
+
+	s.tick() // minute 6, the first observed minute
+	<-minutes
+	time.Sleep(time.Millisecond * 300)
+	s.tick() // minute 7
+
+	select {
+	case timing := <-listener:
+		if timing.Height != 10 || timing.Minute != 6 || timing.Expected != time.Second {
+			t.Errorf("unexpected timing %+v", timing)
+		}
+		if timing.Duration < time.Millisecond*300 || timing.Duration > time.Second {
+			t.Errorf("unexpected duration %s", timing.Duration)
+		}
+		if r := timing.Ratio(); r < 0.3 || r > 1 {
+			t.Errorf("unexpected ratio %f", r)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("minute timing not received")
+	}
+}
@@ -0,0 +1,155 @@
+package monitor
+
+import "strings"
+
+// PendingTransaction is a single item of the factomd "pending-transactions" API.
+type PendingTransaction struct {
+	TransactionID string               `json:"TransactionID"`
+	Status        string               `json:"Status"`
+	Inputs        []TransactionAddress `json:"Inputs"`
+	Outputs       []TransactionAddress `json:"Outputs"`
+	ECOutputs     []TransactionAddress `json:"ECOutputs"`
+	Fees          int64                `json:"Fees"`
+}
+
+// TransactionAddress is an input or output of a factoid transaction
+type TransactionAddress struct {
+	Amount int64 `json:"amount"`
+	// The address hash
+	Address string `json:"address"`
+	// The human readable address, ie FA... or EC...
+	UserAddress string `json:"useraddress"`
+}
+
+// PendingTransactionEvent types
+const (
+	// The transaction entered the pending pool
+	PendingEnter = "enter"
+	// The transaction left the pending pool, either because it was included
+	// in a block or because it was dropped
+	PendingLeave = "leave"
+)
+
+// PendingTransactionEvent is sent to pending transaction listeners when a transaction
+// enters or leaves the node's pending pool.
+type PendingTransactionEvent struct {
+	Type        string             `json:"type"`
+	Transaction PendingTransaction `json:"transaction"`
+}
+
+// PendingTransactionsRequest sends a "pending-transactions" API request to the configured node.
+func (m *Monitor) PendingTransactionsRequest() ([]PendingTransaction, error) {
+	var res []PendingTransaction
+	if err := m.call("pending-transactions", nil, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// WatchPendingTransactions starts checking the node's pending factoid transactions
+// every minute. Pending transaction listeners receive an event when a transaction
+// enters or leaves the pending pool.
+// If addresses are given, only transactions with one of them as input or output
+// are reported. Addresses are human readable public factoid or entry credit addresses.
+// Subsequent calls add to the set of addresses.
+func (m *Monitor) WatchPendingTransactions(addresses ...string) error {
+	for _, addr := range addresses {
+		prefix := "FA"
+		if strings.HasPrefix(addr, "EC") {
+			prefix = "EC"
+		}
+		if err := checkAddress(prefix, addr); err != nil {
+			return err
+		}
+	}
+
+	m.watchMtx.Lock()
+	defer m.watchMtx.Unlock()
+	if m.pendingTxs == nil {
+		m.pendingTxs = make(map[string]PendingTransaction)
+		m.txAddresses = make(map[string]bool)
+		m.everyMinute(func(Event) {
+			if m.due(WatcherPendingTransactions) {
+				m.checkPendingTransactions()
+			}
+		})
+	}
+	for _, addr := range addresses {
+		m.txAddresses[addr] = true
+	}
+	return nil
+}
+
+// NewPendingTransactionListener spawns a new listener that receives an event every time
+// a transaction enters or leaves the pending pool.
+// Each reader must have its own listener.
+func (m *Monitor) NewPendingTransactionListener() <-chan PendingTransactionEvent {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan PendingTransactionEvent, 100)
+	m.pendingTxListeners = append(m.pendingTxListeners, l)
+	m.register("pending transaction", l)
+	return l
+}
+
+func (m *Monitor) checkPendingTransactions() {
+	list, err := m.PendingTransactionsRequest()
+	if err != nil {
+		m.notifyError(err)
+		return
+	}
+
+	var events []PendingTransactionEvent
+	seen := make(map[string]bool, len(list))
+
+	m.watchMtx.Lock()
+	for _, tx := range list {
+		if !m.txMatches(tx) {
+			continue
+		}
+		seen[tx.TransactionID] = true
+		if _, ok := m.pendingTxs[tx.TransactionID]; !ok {
+			events = append(events, PendingTransactionEvent{Type: PendingEnter, Transaction: tx})
+		}
+		m.pendingTxs[tx.TransactionID] = tx
+	}
+	for id, tx := range m.pendingTxs {
+		if !seen[id] {
+			delete(m.pendingTxs, id)
+			events = append(events, PendingTransactionEvent{Type: PendingLeave, Transaction: tx})
+		}
+	}
+	m.watchMtx.Unlock()
+
+	m.notifyPendingTransactions(events)
+}
+
+// txMatches checks if the transaction involves a filtered address, must be called with watchMtx held
+func (m *Monitor) txMatches(tx PendingTransaction) bool {
+	if len(m.txAddresses) == 0 {
+		return true
+	}
+	for _, list := range [][]TransactionAddress{tx.Inputs, tx.Outputs, tx.ECOutputs} {
+		for _, a := range list {
+			if m.txAddresses[a.UserAddress] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m *Monitor) notifyPendingTransactions(events []PendingTransactionEvent) {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	for _, e := range events {
+		for _, l := range m.pendingTxListeners {
+			select {
+			case l <- e:
+				m.delivered(l)
+			default:
+				m.dropped(l)
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package monitor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMonitor_WatchPendingTransactions(t *testing.T) {
+	s := newTestServer("localhost:9871", 10, 0, time.Second*10, t)
+	defer s.stop()
+	var pending []PendingTransaction
+	s.handle("pending-transactions", func(json.RawMessage) interface{} {
+		return pending
+	})
+
+	m, err := NewMonitorWithConfig("http://localhost:9871/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	fa := "FA2jK2HcLnRdS94dEcU27rF3meoJfpUcZPSinpb7AwQvPRY6RL1Q"
+	if err := m.WatchPendingTransactions("FA123"); err == nil {
+		t.Errorf("no error for invalid address")
+	}
+	if err := m.WatchPendingTransactions(fa); err != nil {
+		t.Fatal(err)
+	}
+	listener := m.NewPendingTransactionListener()
+
+	expect := func(typ, id string) {
+		select {
+		case e := <-listener:
+			if e.Type != typ || e.Transaction.TransactionID != id {
+				t.Errorf("unexpected event. got = %+v, want %s %s", e, typ, id)
+			}
+		case <-time.After(time.Second * 2):
+			t.Fatalf("%s of %s not received", typ, id)
+		}
+	}
+
+	mine := PendingTransaction{TransactionID: hash("1"), Outputs: []TransactionAddress{{Amount: 5, UserAddress: fa}}}
+	other := PendingTransaction{TransactionID: hash("2"), Outputs: []TransactionAddress{{Amount: 5, UserAddress: "FA3"}}}
+
+	s.mtx.Lock()
+	pending = []PendingTransaction{mine, other}
+	s.mtx.Unlock()
+	s.tick()
+	expect(PendingEnter, hash("1"))
+
+	s.mtx.Lock()
+	pending = []PendingTransaction{other}
+	s.mtx.Unlock()
+	s.tick()
+	expect(PendingLeave, hash("1"))
+
+	select {
+	case e := <-listener:
+		t.Errorf("unexpected event %+v", e)
+	case <-time.After(time.Millisecond * 300):
+	}
+}
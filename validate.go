@@ -0,0 +1,59 @@
+package monitor
+
+import "fmt"
+
+// defaultMaxHeightJump is used if Config.MaxHeightJump is zero
+const defaultMaxHeightJump = 10
+
+// InvalidResponseError is sent to error listeners for "current-minute" responses that
+// are malformed or absurd. The monitor ignores these responses.
+type InvalidResponseError struct {
+	Response *MinuteResponse
+	Reason   string
+}
+
+func (e *InvalidResponseError) Error() string {
+	return fmt.Sprintf("invalid current-minute response: %s", e.Reason)
+}
+
+// validate checks the response before the monitor acts on it. If jumps is true, an
+// increase in height larger than Config.MaxHeightJump is rejected unless the previous
+// response reported the same jump.
+func (m *Monitor) validate(resp *MinuteResponse, jumps bool) error {
+	invalid := func(format string, args ...interface{}) error {
+		return &InvalidResponseError{Response: resp, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	if resp.Minute < 0 || resp.Minute > 10 {
+		return invalid("minute %d is outside of 0-10", resp.Minute)
+	}
+	if resp.DBlockSeconds <= 0 {
+		return invalid("block time of %d seconds", resp.DBlockSeconds)
+	}
+	if resp.LeaderHeight < 0 || resp.DBHeight < 0 {
+		return invalid("negative height %d or dbheight %d", resp.LeaderHeight, resp.DBHeight)
+	}
+
+	max := m.conf.MaxHeightJump
+	if max == 0 {
+		max = defaultMaxHeightJump
+	}
+	if !jumps || max < 0 {
+		return nil
+	}
+
+	m.heightMtx.Lock()
+	defer m.heightMtx.Unlock()
+	jump := resp.LeaderHeight - m.height
+	if jump <= max {
+		m.suspectHeight = 0
+		return nil
+	}
+	// confirmed if the previous response jumped to the same height or the one before it
+	if m.suspectHeight > 0 && resp.LeaderHeight >= m.suspectHeight && resp.LeaderHeight <= m.suspectHeight+1 {
+		m.suspectHeight = 0
+		return nil
+	}
+	m.suspectHeight = resp.LeaderHeight
+	return invalid("height jumped by %d blocks from %d to %d", jump, m.height, resp.LeaderHeight)
+}
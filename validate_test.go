@@ -0,0 +1,61 @@
+package monitor
+
+import "testing"
+
+func TestMonitor_validate(t *testing.T) {
+	valid := func() *MinuteResponse {
+		return &MinuteResponse{LeaderHeight: 100, DBHeight: 100, Minute: 5, DBlockSeconds: 600}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(r *MinuteResponse)
+		wantErr bool
+	}{
+		{"valid", func(r *MinuteResponse) {}, false},
+		{"minute 10", func(r *MinuteResponse) { r.Minute = 10 }, false},
+		{"minute 11", func(r *MinuteResponse) { r.Minute = 11 }, true},
+		{"negative minute", func(r *MinuteResponse) { r.Minute = -1 }, true},
+		{"no block time", func(r *MinuteResponse) { r.DBlockSeconds = 0 }, true},
+		{"negative height", func(r *MinuteResponse) { r.LeaderHeight = -1 }, true},
+		{"negative dbheight", func(r *MinuteResponse) { r.DBHeight = -5 }, true},
+		{"small jump", func(r *MinuteResponse) { r.LeaderHeight = 110 }, false},
+		{"large jump", func(r *MinuteResponse) { r.LeaderHeight = 111 }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := new(Monitor)
+			m.height = 100
+			r := valid()
+			tt.modify(r)
+			err := m.validate(r, true)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, ok := err.(*InvalidResponseError); err != nil && !ok {
+				t.Errorf("unexpected error type %T", err)
+			}
+		})
+	}
+}
+
+func TestMonitor_validate_confirmJump(t *testing.T) {
+	m := new(Monitor)
+	m.height = 100
+	jump := &MinuteResponse{LeaderHeight: 5000, DBHeight: 5000, DBlockSeconds: 600}
+
+	if err := m.validate(jump, true); err == nil {
+		t.Fatal("unconfirmed jump accepted")
+	}
+	if err := m.validate(jump, false); err != nil {
+		t.Errorf("jump rejected without jump check: %v", err)
+	}
+	if err := m.validate(jump, true); err != nil {
+		t.Errorf("confirmed jump rejected: %v", err)
+	}
+
+	m.conf.MaxHeightJump = -1
+	if err := m.validate(&MinuteResponse{LeaderHeight: 1e6, DBlockSeconds: 600}, true); err != nil {
+		t.Errorf("jump rejected with disabled check: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package monitor
+
+import "fmt"
+
+// SupportedAPIVersion is the factomd API version this package was built for
+const SupportedAPIVersion = "2.0"
+
+// PropertiesResponse is a struct formed after the response from the factomd "properties" API.
+type PropertiesResponse struct {
+	FactomdVersion    string `json:"factomdversion"`
+	FactomdAPIVersion string `json:"factomdapiversion"`
+}
+
+// VersionEvent is sent to version listeners when the node's software version changes,
+// usually because the node was upgraded and restarted.
+type VersionEvent struct {
+	Old        string `json:"old"`
+	New        string `json:"new"`
+	APIVersion string `json:"apiversion"`
+	// The dbheight at which the change was detected
+	DBHeight int64 `json:"dbheight"`
+}
+
+// PropertiesRequest sends a "properties" API request to the configured node.
+func (m *Monitor) PropertiesRequest() (*PropertiesResponse, error) {
+	res := new(PropertiesResponse)
+	if err := m.call("properties", nil, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Version returns the factomd version of the node.
+// Empty if the node's properties could not be retrieved yet.
+func (m *Monitor) Version() string {
+	m.heightMtx.Lock()
+	defer m.heightMtx.Unlock()
+	return m.version
+}
+
+// APIVersion returns the API version of the node.
+// Empty if the node's properties could not be retrieved yet.
+func (m *Monitor) APIVersion() string {
+	m.heightMtx.Lock()
+	defer m.heightMtx.Unlock()
+	return m.apiVersion
+}
+
+// NewVersionListener spawns a new listener that receives an event every time the
+// node's software version changes.
+// Each reader must have its own listener.
+func (m *Monitor) NewVersionListener() <-chan VersionEvent {
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	l := make(chan VersionEvent, 6)
+	m.versionListeners = append(m.versionListeners, l)
+	m.register("version", l)
+	return l
+}
+
+// checkVersion fetches the node's properties. Error listeners receive a warning if the
+// API version is not SupportedAPIVersion.
+func (m *Monitor) checkVersion(dbheight int64) {
+	props, err := m.PropertiesRequest()
+	if err != nil {
+		m.notifyError(err)
+		return
+	}
+
+	m.heightMtx.Lock()
+	old, oldAPI := m.version, m.apiVersion
+	m.version = props.FactomdVersion
+	m.apiVersion = props.FactomdAPIVersion
+	m.heightMtx.Unlock()
+
+	if props.FactomdAPIVersion != oldAPI && props.FactomdAPIVersion != SupportedAPIVersion {
+		m.notifyError(fmt.Errorf("node has api version %s, this package was built for %s", props.FactomdAPIVersion, SupportedAPIVersion))
+	}
+
+	if old == "" || old == props.FactomdVersion {
+		return
+	}
+
+	e := VersionEvent{Old: old, New: props.FactomdVersion, APIVersion: props.FactomdAPIVersion, DBHeight: dbheight}
+	m.listenerMtx.Lock()
+	defer m.listenerMtx.Unlock()
+	for _, l := range m.versionListeners {
+		select {
+		case l <- e:
+			m.delivered(l)
+		default:
+			m.dropped(l)
+		}
+	}
+}
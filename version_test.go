@@ -0,0 +1,49 @@
+package monitor
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMonitor_Version(t *testing.T) {
+	s := newTestServer("localhost:9864", 10, 0, time.Second*10, t)
+	defer s.stop()
+
+	m, err := NewMonitorWithConfig("http://localhost:9864/v2", Config{Interval: time.Millisecond * 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	if m.Version() != "6.9.0" || m.APIVersion() != SupportedAPIVersion {
+		t.Errorf("unexpected versions %s %s", m.Version(), m.APIVersion())
+	}
+
+	listener := m.NewVersionListener()
+	errs := m.NewErrorListener()
+
+	s.handle("properties", func(json.RawMessage) interface{} {
+		return PropertiesResponse{FactomdVersion: "7.0.0", FactomdAPIVersion: "3.0"}
+	})
+	s.tick() // dbheight 10
+
+	select {
+	case e := <-listener:
+		if e.Old != "6.9.0" || e.New != "7.0.0" || e.APIVersion != "3.0" {
+			t.Errorf("unexpected event %+v", e)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("version change not received")
+	}
+
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "api version 3.0") {
+			t.Errorf("unexpected error %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("no warning about unsupported api version")
+	}
+}
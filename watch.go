@@ -0,0 +1,90 @@
+package monitor
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// zeroHash is the keymr that precedes the first block of a chain
+const zeroHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// everyDBHeight starts a goroutine that calls f for every new dbheight while the monitor is running.
+func (m *Monitor) everyDBHeight(f func(dbheight int64)) {
+	l := m.NewDBHeightListener()
+	m.addWatcher(func(stop <-chan struct{}) {
+		for {
+			select {
+			case <-stop:
+				return
+			case h, ok := <-l:
+				if !ok { // drained
+					return
+				}
+				f(h)
+			}
+		}
+	})
+}
+
+// everyMinute starts a goroutine that calls f for every new minute while the monitor is running.
+func (m *Monitor) everyMinute(f func(e Event)) {
+	l := m.NewMinuteListener()
+	m.addWatcher(func(stop <-chan struct{}) {
+		for {
+			select {
+			case <-stop:
+				return
+			case e, ok := <-l:
+				if !ok { // drained
+					return
+				}
+				f(e)
+			}
+		}
+	})
+}
+
+// every starts a goroutine that calls f every interval while the monitor is running.
+func (m *Monitor) every(interval time.Duration, f func()) {
+	m.addWatcher(func(stop <-chan struct{}) {
+		ticker := m.clock().NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C():
+				f()
+			}
+		}
+	})
+}
+
+// addWatcher runs w in a goroutine every time the monitor is started, until it is stopped.
+// If the monitor is running, w is started immediately.
+func (m *Monitor) addWatcher(w func(stop <-chan struct{})) {
+	m.runMtx.Lock()
+	defer m.runMtx.Unlock()
+	m.watchers = append(m.watchers, w)
+	if m.running {
+		go w(m.close)
+	}
+}
+
+// call sends an API request with the monitor's timeout
+func (m *Monitor) call(method string, params, result interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout())
+	defer cancel()
+	return m.request(ctx, method, params, result)
+}
+
+// checkHash verifies that the string is a 32 byte hex encoded hash, such as a chain id
+func checkHash(name, h string) error {
+	b, err := hex.DecodeString(h)
+	if err != nil || len(b) != 32 {
+		return fmt.Errorf("invalid %s %q: must be 64 hex characters", name, h)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+)
+
+// Watchlist is the set of chains and addresses a monitor watches
+type Watchlist struct {
+	Chains           []string `json:"chains,omitempty"`
+	FactoidAddresses []string `json:"factoidaddresses,omitempty"`
+	ECAddresses      []string `json:"ecaddresses,omitempty"`
+}
+
+// WatchlistStore persists the watchlist across restarts.
+type WatchlistStore interface {
+	// Load returns the saved watchlist. If nothing has been saved yet, it returns nil and no error.
+	Load() (*Watchlist, error)
+	// Save replaces the saved watchlist
+	Save(w Watchlist) error
+}
+
+// FileWatchlist is a WatchlistStore that keeps the watchlist in a JSON file.
+type FileWatchlist struct {
+	path string
+}
+
+var _ WatchlistStore = (*FileWatchlist)(nil)
+
+// NewFileWatchlist creates a store that saves the watchlist to the specified path.
+// The file is created on the first save.
+func NewFileWatchlist(path string) *FileWatchlist {
+	fw := new(FileWatchlist)
+	fw.path = path
+	return fw
+}
+
+// Load reads the watchlist from the file
+func (fw *FileWatchlist) Load() (*Watchlist, error) {
+	data, err := ioutil.ReadFile(fw.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	w := new(Watchlist)
+	if err := json.Unmarshal(data, w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Save writes the watchlist to a temporary file and replaces the old file,
+// so an interrupted write does not corrupt the saved watchlist.
+func (fw *FileWatchlist) Save(w Watchlist) error {
+	data, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+	return writeFile(fw.path, data)
+}
+
+// Watchlist returns the chains and addresses that are watched, including the ones of
+// Config.Watchlist that are watched again when the monitor starts
+func (m *Monitor) Watchlist() Watchlist {
+	m.watchMtx.Lock()
+	defer m.watchMtx.Unlock()
+	return m.watchlist()
+}
+
+// watchlist must be called with watchMtx held
+func (m *Monitor) watchlist() Watchlist {
+	var w Watchlist
+	for id := range m.chains {
+		w.Chains = append(w.Chains, id)
+	}
+	for addr := range m.fctBalances {
+		w.FactoidAddresses = append(w.FactoidAddresses, addr)
+	}
+	for addr := range m.ecBalances {
+		w.ECAddresses = append(w.ECAddresses, addr)
+	}
+	w.Chains = union(w.Chains, m.savedWatchlist.Chains)
+	w.FactoidAddresses = union(w.FactoidAddresses, m.savedWatchlist.FactoidAddresses)
+	w.ECAddresses = union(w.ECAddresses, m.savedWatchlist.ECAddresses)
+	return w
+}
+
+// union adds the items of b that are not in a to a and sorts it
+func union(a, b []string) []string {
+	for _, s := range b {
+		if !contains(a, s) {
+			a = append(a, s)
+		}
+	}
+	sort.Strings(a)
+	return a
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// remove returns a copy of the list without s
+func remove(list []string, s string) []string {
+	var out []string
+	for _, item := range list {
+		if item != s {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// checkWatchlist verifies the chain ids and addresses of a loaded watchlist
+func checkWatchlist(w *Watchlist) error {
+	for _, id := range w.Chains {
+		if err := checkHash("chain id", id); err != nil {
+			return err
+		}
+	}
+	for _, addr := range w.FactoidAddresses {
+		if err := checkAddress("FA", addr); err != nil {
+			return err
+		}
+	}
+	for _, addr := range w.ECAddresses {
+		if err := checkAddress("EC", addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveWatchlist persists the watchlist to Config.Watchlist, if set. Errors are sent to
+// error listeners.
+func (m *Monitor) saveWatchlist() {
+	if m.conf.Watchlist == nil {
+		return
+	}
+	m.watchlistMtx.Lock()
+	defer m.watchlistMtx.Unlock()
+	if err := m.conf.Watchlist.Save(m.Watchlist()); err != nil {
+		m.notifyError(err)
+	}
+}
+
+// restoreWatchlist watches the chains and addresses of the saved watchlist that aren't
+// watched yet. Errors are sent to error listeners and items that could not be watched
+// are tried again with the next start.
+func (m *Monitor) restoreWatchlist() {
+	m.watchMtx.Lock()
+	pending := m.savedWatchlist
+	m.watchMtx.Unlock()
+
+	for _, id := range pending.Chains {
+		head, err := m.ChainHeadRequest(id)
+		if err != nil {
+			m.notifyError(fmt.Errorf("watchlist chain %s: %v", id, err))
+			continue
+		}
+		m.watchMtx.Lock()
+		m.watchChainHead(id, head.ChainHead)
+		m.savedWatchlist.Chains = remove(m.savedWatchlist.Chains, id)
+		m.watchMtx.Unlock()
+	}
+	for _, addr := range pending.FactoidAddresses {
+		balance, err := m.FactoidBalanceRequest(addr)
+		if err != nil {
+			m.notifyError(fmt.Errorf("watchlist address %s: %v", addr, err))
+			continue
+		}
+		m.watchMtx.Lock()
+		m.watchFactoidBalance(addr, balance)
+		m.savedWatchlist.FactoidAddresses = remove(m.savedWatchlist.FactoidAddresses, addr)
+		m.watchMtx.Unlock()
+	}
+	for _, addr := range pending.ECAddresses {
+		balance, err := m.ECBalanceRequest(addr)
+		if err != nil {
+			m.notifyError(fmt.Errorf("watchlist address %s: %v", addr, err))
+			continue
+		}
+		m.watchMtx.Lock()
+		m.watchECBalance(addr, balance)
+		m.savedWatchlist.ECAddresses = remove(m.savedWatchlist.ECAddresses, addr)
+		m.watchMtx.Unlock()
+	}
+}
@@ -0,0 +1,143 @@
+package monitor
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AdamSLevy/jsonrpc2/v14"
+)
+
+func TestMonitor_Watchlist(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watchlist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fw := NewFileWatchlist(filepath.Join(dir, "watchlist.json"))
+
+	s := newTestServer("localhost:9828", 10, 5, time.Second*10, t)
+	defer s.stop()
+	newFakeChain(s, hash("c"), hash("a"))
+	s.handle("factoid-balance", func(json.RawMessage) interface{} { return BalanceResponse{Balance: 5} })
+	s.handle("entry-credit-balance", func(json.RawMessage) interface{} { return BalanceResponse{Balance: 7} })
+
+	fa := "FA2jK2HcLnRdS94dEcU27rF3meoJfpUcZPSinpb7AwQvPRY6RL1Q"
+	ec := "EC2BURNFCT2PEGNETooo1oooo1oooo1oooo1oooo1oooo19wthin"
+
+	m, err := New("http://localhost:9828/v2", Config{Watchlist: fw})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, err := range []error{m.WatchChain(hash("c")), m.WatchChain(hash("d")), m.WatchFactoidAddress(fa), m.WatchECAddress(ec)} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	m.UnwatchChain(hash("d"))
+
+	want := Watchlist{Chains: []string{hash("c")}, FactoidAddresses: []string{fa}, ECAddresses: []string{ec}}
+	saved, err := fw.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved == nil || !reflect.DeepEqual(*saved, want) {
+		t.Fatalf("unexpected saved watchlist %+v", saved)
+	}
+
+	// after a restart of the process
+	m2, err := New("http://localhost:9828/v2", Config{Watchlist: fw})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := m2.Watchlist(); !reflect.DeepEqual(w, want) {
+		t.Errorf("unexpected watchlist before start %+v", w)
+	}
+	if err := m2.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer m2.Stop()
+	if c := m2.WatchedChains(); len(c) != 1 || c[0] != hash("c") {
+		t.Errorf("chains not watched again: %v", c)
+	}
+	if b := m2.Snapshot(); b.FactoidAddresses[fa] != 5 || b.ECAddresses[ec] != 7 {
+		t.Errorf("addresses not watched again: %+v %+v", b.FactoidAddresses, b.ECAddresses)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "invalid.json"), []byte(`{"chains":["abc"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New("http://localhost:9828/v2", Config{Watchlist: NewFileWatchlist(filepath.Join(dir, "invalid.json"))}); err == nil {
+		t.Error("invalid watchlist accepted")
+	}
+}
+
+func TestMonitor_WatchlistUnresolved(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watchlist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fw := NewFileWatchlist(filepath.Join(dir, "watchlist.json"))
+	if err := fw.Save(Watchlist{Chains: []string{hash("c"), hash("d")}}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := newTestServer("localhost:9820", 10, 5, time.Second*10, t)
+	defer s.stop()
+	heads := 0
+	s.handle("chain-head", func(params json.RawMessage) interface{} {
+		heads++
+		var p struct {
+			ChainID string `json:"chainid"`
+		}
+		json.Unmarshal(params, &p)
+		if p.ChainID == hash("d") {
+			return jsonrpc2.Error{Code: -32009, Message: "Missing Chain Head"}
+		}
+		return ChainHeadResponse{ChainHead: hash("a")}
+	})
+
+	m, err := New("http://localhost:9820/v2", Config{Watchlist: fw})
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := m.NewErrorListener()
+	if err := m.Start(); err != nil {
+		t.Fatalf("start failed because of an unresolved chain: %v", err)
+	}
+	defer m.Stop()
+
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), hash("d")) {
+			t.Errorf("unexpected error %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("unresolved chain not reported")
+	}
+	if c := m.WatchedChains(); len(c) != 1 || c[0] != hash("c") {
+		t.Errorf("unexpected watched chains %v", c)
+	}
+	if w := m.Watchlist(); !reflect.DeepEqual(w.Chains, []string{hash("c"), hash("d")}) {
+		t.Errorf("unresolved chain not kept for the next start: %v", w.Chains)
+	}
+
+	// starting a running monitor doesn't retry the unresolved chain
+	s.mtx.Lock()
+	before := heads
+	s.mtx.Unlock()
+	if err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if heads != before {
+		t.Errorf("start of a running monitor sent %d chain-head requests", heads-before)
+	}
+}
@@ -0,0 +1,176 @@
+// Package websocket broadcasts monitor events to websocket clients.
+package websocket
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+	gws "github.com/gorilla/websocket"
+)
+
+// BufferSize is the amount of events that can be queued for a single client.
+// Clients that fall further behind than this are disconnected.
+var BufferSize = 16
+
+// WriteTimeout specifies the maximum time writing a single event to a client can take
+var WriteTimeout = time.Second * 5
+
+// Server is an http.Handler that upgrades incoming requests to websocket connections
+// and broadcasts every event it receives as JSON to all connected clients.
+type Server struct {
+	// Upgrader is used to upgrade incoming requests and may be modified before
+	// the first client connects, ie to set a CheckOrigin function.
+	Upgrader gws.Upgrader
+
+	clientMtx sync.Mutex
+	clients   map[*client]bool
+
+	close  chan interface{}
+	closer sync.Once
+}
+
+var _ monitor.Sink = (*Server)(nil)
+
+type client struct {
+	conn *gws.Conn
+	send chan monitor.Event
+	once sync.Once
+	done chan interface{}
+}
+
+// NewServer creates a new server that broadcasts all events read from the
+// provided channel, usually a monitor's minute listener.
+// Starts a goroutine that can be stopped via server.Stop().
+// If events is nil, the server only broadcasts the events passed to HandleEvent, ie by
+// a monitor.Dispatcher.
+func NewServer(events <-chan monitor.Event) *Server {
+	s := new(Server)
+	s.clients = make(map[*client]bool)
+	s.close = make(chan interface{})
+	if events != nil {
+		go s.broadcast(events)
+	}
+	return s
+}
+
+// ServeHTTP upgrades the connection and registers the client with the broadcaster.
+func (s *Server) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	conn, err := s.Upgrader.Upgrade(rw, r, nil)
+	if err != nil {
+		return // upgrader already responded with an http error
+	}
+
+	c := new(client)
+	c.conn = conn
+	c.send = make(chan monitor.Event, BufferSize)
+	c.done = make(chan interface{})
+
+	s.clientMtx.Lock()
+	select {
+	case <-s.close:
+		s.clientMtx.Unlock()
+		conn.Close()
+		return
+	default:
+	}
+	s.clients[c] = true
+	s.clientMtx.Unlock()
+
+	go s.read(c)
+	go s.write(c)
+}
+
+// Clients returns the amount of currently connected clients
+func (s *Server) Clients() int {
+	s.clientMtx.Lock()
+	defer s.clientMtx.Unlock()
+	return len(s.clients)
+}
+
+func (s *Server) broadcast(events <-chan monitor.Event) {
+	for {
+		select {
+		case <-s.close:
+			return
+		case e, ok := <-events:
+			if !ok {
+				s.Stop()
+				return
+			}
+			s.HandleEvent(context.Background(), e)
+		}
+	}
+}
+
+// HandleEvent queues the event for every connected client. Clients that fall too far
+// behind are disconnected. It implements monitor.Sink and never fails.
+func (s *Server) HandleEvent(ctx context.Context, e monitor.Event) error {
+	s.clientMtx.Lock()
+	defer s.clientMtx.Unlock()
+	for c := range s.clients {
+		select {
+		case c.send <- e:
+		default: // slow client
+			s.evict(c)
+		}
+	}
+	return nil
+}
+
+// evict removes the client and closes the connection.
+// clientMtx must be held by the caller.
+func (s *Server) evict(c *client) {
+	delete(s.clients, c)
+	c.once.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+func (s *Server) remove(c *client) {
+	s.clientMtx.Lock()
+	defer s.clientMtx.Unlock()
+	s.evict(c)
+}
+
+// read discards incoming messages but is necessary to process control frames
+// and to detect when the client disconnects
+func (s *Server) read(c *client) {
+	defer s.remove(c)
+	for {
+		if _, _, err := c.conn.NextReader(); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) write(c *client) {
+	defer s.remove(c)
+	for {
+		select {
+		case <-c.done:
+			return
+		case e := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(WriteTimeout))
+			if err := c.conn.WriteJSON(e); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Stop will disconnect all clients and stop broadcasting.
+// New connections after the server has stopped are closed immediately.
+func (s *Server) Stop() {
+	s.closer.Do(func() {
+		s.clientMtx.Lock()
+		defer s.clientMtx.Unlock()
+		close(s.close)
+		for c := range s.clients {
+			s.evict(c)
+		}
+	})
+}
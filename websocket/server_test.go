@@ -0,0 +1,121 @@
+package websocket
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	monitor "github.com/WhoSoup/factom-monitor"
+	"github.com/WhoSoup/factom-monitor/monitortest"
+	gws "github.com/gorilla/websocket"
+)
+
+func dial(t *testing.T, url string) *gws.Conn {
+	conn, _, err := gws.DefaultDialer.Dial("ws"+strings.TrimPrefix(url, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func waitClients(s *Server, n int) bool {
+	for i := 0; i < 50; i++ {
+		if s.Clients() == n {
+			return true
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	return false
+}
+
+func TestServer_Broadcast(t *testing.T) {
+	events := make(chan monitor.Event)
+	s := NewServer(events)
+	defer s.Stop()
+
+	hs := httptest.NewServer(s)
+	defer hs.Close()
+
+	a := dial(t, hs.URL)
+	defer a.Close()
+	b := dial(t, hs.URL)
+	defer b.Close()
+
+	if !waitClients(s, 2) {
+		t.Fatalf("unexpected client count. got = %d, want = 2", s.Clients())
+	}
+
+	want := monitor.Event{DBHeight: 9, Height: 10, Minute: 3}
+	events <- want
+
+	for i, c := range []*gws.Conn{a, b} {
+		var got monitor.Event
+		c.SetReadDeadline(time.Now().Add(time.Second))
+		if err := c.ReadJSON(&got); err != nil {
+			t.Fatalf("client %d: %v", i, err)
+		}
+		if got != want {
+			t.Errorf("client %d received wrong event. got = %+v, want = %+v", i, got, want)
+		}
+	}
+
+	a.Close()
+	if !waitClients(s, 1) {
+		t.Errorf("disconnected client was not removed. got = %d, want = 1", s.Clients())
+	}
+}
+
+func TestServer_Stop(t *testing.T) {
+	events := make(chan monitor.Event)
+	s := NewServer(events)
+
+	hs := httptest.NewServer(s)
+	defer hs.Close()
+
+	c := dial(t, hs.URL)
+	defer c.Close()
+
+	if !waitClients(s, 1) {
+		t.Fatalf("unexpected client count. got = %d, want = 1", s.Clients())
+	}
+
+	s.Stop()
+
+	c.SetReadDeadline(time.Now().Add(time.Second))
+	if _, _, err := c.ReadMessage(); err == nil {
+		t.Errorf("connection still open after stop")
+	}
+	if s.Clients() != 0 {
+		t.Errorf("clients still registered after stop. got = %d", s.Clients())
+	}
+}
+
+func TestServer_Dispatcher(t *testing.T) {
+	fake := monitortest.NewFakeMonitor(10, 1)
+	d := monitor.NewDispatcher(fake, monitor.DispatcherConfig{})
+	defer d.Stop()
+	s := NewServer(nil)
+	defer s.Stop()
+	if err := d.Add("websocket", s); err != nil {
+		t.Fatal(err)
+	}
+
+	hs := httptest.NewServer(s)
+	defer hs.Close()
+	c := dial(t, hs.URL)
+	defer c.Close()
+	if !waitClients(s, 1) {
+		t.Fatalf("unexpected client count. got = %d, want = 1", s.Clients())
+	}
+
+	fake.AdvanceMinute()
+	var got monitor.Event
+	c.SetReadDeadline(time.Now().Add(time.Second))
+	if err := c.ReadJSON(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Height != 10 || got.Minute != 2 {
+		t.Errorf("unexpected event %+v", got)
+	}
+}